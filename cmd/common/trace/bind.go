@@ -27,6 +27,7 @@ type BindFlags struct {
 	TargetPid    int32
 	IgnoreErrors bool
 	TargetPorts  []uint
+	Listen       bool
 
 	// It is necessary because pflag doesn't support []uint16 flags.
 	ValidatedTargetPorts []uint16
@@ -72,6 +73,13 @@ func NewBindCmd(runCmd func(*cobra.Command, []string) error, flags *BindFlags) *
 		true,
 		"Show only events where the bind succeeded",
 	)
+	cmd.PersistentFlags().BoolVarP(
+		&flags.Listen,
+		"listen",
+		"",
+		false,
+		"Show also the listen() calls, with their backlog, and the close of the listening sockets",
+	)
 
 	return cmd
 }
@@ -19,8 +19,9 @@ import (
 )
 
 type CapabilitiesFlags struct {
-	AuditOnly bool
-	Unique    bool
+	AuditOnly  bool
+	Unique     bool
+	PrintStack bool
 }
 
 func NewCapabilitiesCmd(runCmd func(*cobra.Command, []string) error, flags *CapabilitiesFlags) *cobra.Command {
@@ -46,5 +47,13 @@ func NewCapabilitiesCmd(runCmd func(*cobra.Command, []string) error, flags *Capa
 		"Only show a capability once on the same container",
 	)
 
+	cmd.PersistentFlags().BoolVarP(
+		&flags.PrintStack,
+		"print-stack",
+		"",
+		false,
+		"Show the syscall and collect the kernel stack (only shown in json output) of the checks. Each syscall, capability and verdict is shown once on the same container, then again with the times it was seen",
+	)
+
 	return cmd
 }
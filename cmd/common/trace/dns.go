@@ -18,10 +18,21 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func NewDNSCmd(runCmd func(*cobra.Command, []string) error) *cobra.Command {
-	return &cobra.Command{
+type DNSFlags struct {
+	ResponsesOnly bool
+}
+
+func NewDNSCmd(runCmd func(*cobra.Command, []string) error, flags *DNSFlags) *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "dns",
 		Short: "Trace DNS requests",
 		RunE:  runCmd,
 	}
+
+	cmd.Flags().BoolVar(
+		&flags.ResponsesOnly, "responses-only", false,
+		"Only show responses, with the latency since their query",
+	)
+
+	return cmd
 }
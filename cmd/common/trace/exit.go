@@ -0,0 +1,41 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type ExitFlags struct {
+	FailedOnly bool
+}
+
+func NewExitCmd(runCmd func(*cobra.Command, []string) error, flags *ExitFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exit",
+		Short: "Trace process exits with their exit code or terminating signal",
+		RunE:  runCmd,
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&flags.FailedOnly,
+		"failed-only",
+		"f",
+		false,
+		"Show only processes exiting with a non-zero code or killed by a signal",
+	)
+
+	return cmd
+}
@@ -30,14 +30,14 @@ type FsSlowerFlags struct {
 }
 
 func NewFsSlowerCmd(runCmd func(*cobra.Command, []string) error, flags *FsSlowerFlags) *cobra.Command {
-	validFsSlowerFilesystems := []string{"btrfs", "ext4", "nfs", "xfs"}
+	validFsSlowerFilesystems := []string{"btrfs", "ext4", "nfs", "nfs4", "xfs"}
 
 	cmd := &cobra.Command{
 		Use:   "fsslower",
 		Short: "Trace open, read, write and fsync operations slower than a threshold",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if flags.Filesystem == "" {
-				return commonutils.WrapInErrMissingArgs("--filesystem / -f")
+				return nil
 			}
 
 			found := false
@@ -64,7 +64,7 @@ func NewFsSlowerCmd(runCmd func(*cobra.Command, []string) error, flags *FsSlower
 	)
 	cmd.Flags().StringVarP(
 		&flags.Filesystem, "filesystem", "f", "",
-		fmt.Sprintf("Which filesystem to trace: [%s]", strings.Join(validFsSlowerFilesystems, ", ")),
+		fmt.Sprintf("Which filesystem to trace: [%s] (default to all the supported filesystems whose module is loaded)", strings.Join(validFsSlowerFilesystems, ", ")),
 	)
 
 	return cmd
@@ -0,0 +1,61 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/spf13/cobra"
+
+	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
+)
+
+type HTTPFlags struct {
+	Ports []uint
+
+	// It is necessary because pflag doesn't support []uint16 flags.
+	ValidatedPorts []uint16
+}
+
+func NewHTTPCmd(runCmd func(*cobra.Command, []string) error, flags *HTTPFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "http",
+		Short: "Trace plain-text HTTP requests",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			flags.ValidatedPorts = make([]uint16, 0, len(flags.Ports))
+			for _, port := range flags.Ports {
+				if port == 0 || port > math.MaxUint16 {
+					return commonutils.WrapInErrInvalidArg("--ports",
+						fmt.Errorf("invalid port. Valid range: 1-%d", math.MaxUint16))
+				}
+
+				flags.ValidatedPorts = append(flags.ValidatedPorts, uint16(port))
+			}
+			return nil
+		},
+		RunE: runCmd,
+	}
+
+	cmd.PersistentFlags().UintSliceVarP(
+		&flags.Ports,
+		"ports",
+		"P",
+		[]uint{80, 8080},
+		"Trace only HTTP connections to these server ports",
+	)
+
+	return cmd
+}
@@ -19,9 +19,10 @@ import (
 )
 
 type SignalFlags struct {
-	Pid    uint64
-	Sig    string
-	Failed bool
+	Pid            uint64
+	Sig            string
+	Failed         bool
+	CrossContainer bool
 }
 
 func NewSignalCmd(runCmd func(*cobra.Command, []string) error, flags *SignalFlags) *cobra.Command {
@@ -52,6 +53,13 @@ func NewSignalCmd(runCmd func(*cobra.Command, []string) error, flags *SignalFlag
 		false,
 		`Show only events where the syscall sending a signal failed`,
 	)
+	cmd.PersistentFlags().BoolVarP(
+		&flags.CrossContainer,
+		"cross-container",
+		"",
+		false,
+		`Show only signals sent to processes in another container, or between a container and the host. The filters on the containers then match the sender or the target of the signals`,
+	)
 
 	return cmd
 }
@@ -18,10 +18,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func NewSNICmd(runCmd func(*cobra.Command, []string) error) *cobra.Command {
-	return &cobra.Command{
+type SNIFlags struct {
+	Negotiated bool
+	WithoutSNI bool
+}
+
+func NewSNICmd(runCmd func(*cobra.Command, []string) error, flags *SNIFlags) *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "sni",
 		Short: "Trace Server Name Indication (SNI) from TLS requests",
 		RunE:  runCmd,
 	}
+
+	cmd.Flags().BoolVar(
+		&flags.Negotiated, "negotiated", false,
+		"Show one event per handshake, when the server answers, with the TLS version and cipher it selected",
+	)
+	cmd.Flags().BoolVar(
+		&flags.WithoutSNI, "without-sni", false,
+		"Also show the TLS handshakes without Server Name Indication",
+	)
+
+	return cmd
 }
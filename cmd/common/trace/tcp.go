@@ -18,10 +18,24 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func NewTCPCmd(runCmd func(*cobra.Command, []string) error) *cobra.Command {
-	return &cobra.Command{
+type TCPFlags struct {
+	Lifetime bool
+}
+
+func NewTCPCmd(runCmd func(*cobra.Command, []string) error, flags *TCPFlags) *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "tcp",
 		Short: "Trace TCP connect, accept and close",
 		RunE:  runCmd,
 	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&flags.Lifetime,
+		"lifetime",
+		"",
+		false,
+		"Show one event per connection closed, with its duration and the bytes sent and received",
+	)
+
+	return cmd
 }
@@ -18,10 +18,24 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func NewTcpconnectCmd(runCmd func(*cobra.Command, []string) error) *cobra.Command {
-	return &cobra.Command{
+type TcpconnectFlags struct {
+	MinLatency uint
+}
+
+func NewTcpconnectCmd(runCmd func(*cobra.Command, []string) error, flags *TcpconnectFlags) *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "tcpconnect",
-		Short: "Trace connect system calls",
+		Short: "Trace connect system calls, with their latency or failure reason",
 		RunE:  runCmd,
 	}
+
+	cmd.PersistentFlags().UintVarP(
+		&flags.MinLatency,
+		"min-latency",
+		"",
+		0,
+		"Show only connections established slower than this latency, in ms. Failed connections are always shown",
+	)
+
+	return cmd
 }
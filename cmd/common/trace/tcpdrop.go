@@ -0,0 +1,38 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type TcpdropFlags struct {
+	KernelStack bool
+}
+
+func NewTcpdropCmd(runCmd func(*cobra.Command, []string) error, flags *TcpdropFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tcpdrop",
+		Short: "Trace packets dropped by the kernel",
+		RunE:  runCmd,
+	}
+
+	cmd.Flags().BoolVar(
+		&flags.KernelStack, "kernel-stack", false,
+		"Collect the kernel stack of each drop (only shown in json output)",
+	)
+
+	return cmd
+}
@@ -36,7 +36,12 @@ func newBindCmd() *cobra.Command {
 			portsStringSlice = append(portsStringSlice, strconv.FormatUint(uint64(port), 10))
 		}
 
-		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, bindTypes.GetColumns())
+		columns := bindTypes.GetColumns()
+		if flags.Listen {
+			columns = bindTypes.GetListenColumns()
+		}
+
+		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, columns)
 		if err != nil {
 			return commonutils.WrapInErrParserCreate(err)
 		}
@@ -49,6 +54,7 @@ func newBindCmd() *cobra.Command {
 				"pid":           strconv.FormatUint(uint64(flags.TargetPid), 10),
 				"ports":         strings.Join(portsStringSlice, ","),
 				"ignore_errors": strconv.FormatBool(flags.IgnoreErrors),
+				"listen":        strconv.FormatBool(flags.Listen),
 			},
 		}
 
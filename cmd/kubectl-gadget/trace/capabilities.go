@@ -30,7 +30,12 @@ func newCapabilitiesCmd() *cobra.Command {
 	var flags commontrace.CapabilitiesFlags
 
 	runCmd := func(cmd *cobra.Command, args []string) error {
-		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, capabilitiesTypes.GetColumns())
+		columns := capabilitiesTypes.GetColumns()
+		if flags.PrintStack {
+			columns = capabilitiesTypes.GetPrintStackColumns()
+		}
+
+		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, columns)
 		if err != nil {
 			return commonutils.WrapInErrParserCreate(err)
 		}
@@ -40,8 +45,9 @@ func newCapabilitiesCmd() *cobra.Command {
 			commonFlags: &commonFlags,
 			parser:      parser,
 			params: map[string]string{
-				capabilitiesTypes.AuditOnlyParam: strconv.FormatBool(flags.AuditOnly),
-				capabilitiesTypes.UniqueParam:    strconv.FormatBool(flags.Unique),
+				capabilitiesTypes.AuditOnlyParam:  strconv.FormatBool(flags.AuditOnly),
+				capabilitiesTypes.UniqueParam:     strconv.FormatBool(flags.Unique),
+				capabilitiesTypes.PrintStackParam: strconv.FormatBool(flags.PrintStack),
 			},
 		}
 
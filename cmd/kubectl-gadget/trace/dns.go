@@ -15,6 +15,8 @@
 package trace
 
 import (
+	"strconv"
+
 	"github.com/spf13/cobra"
 
 	commontrace "github.com/inspektor-gadget/inspektor-gadget/cmd/common/trace"
@@ -25,6 +27,7 @@ import (
 
 func newDNSCmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.DNSFlags
 
 	runCmd := func(cmd *cobra.Command, args []string) error {
 		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, dnsTypes.GetColumns())
@@ -36,12 +39,15 @@ func newDNSCmd() *cobra.Command {
 			name:        "dns",
 			commonFlags: &commonFlags,
 			parser:      parser,
+			params: map[string]string{
+				"responsesonly": strconv.FormatBool(flags.ResponsesOnly),
+			},
 		}
 
 		return dnsGadget.Run()
 	}
 
-	cmd := commontrace.NewDNSCmd(runCmd)
+	cmd := commontrace.NewDNSCmd(runCmd, &flags)
 
 	utils.AddCommonFlags(cmd, &commonFlags)
 
@@ -0,0 +1,61 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	commontrace "github.com/inspektor-gadget/inspektor-gadget/cmd/common/trace"
+	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/kubectl-gadget/utils"
+	httpTypes "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/http/types"
+)
+
+func newHTTPCmd() *cobra.Command {
+	var commonFlags utils.CommonFlags
+	var flags commontrace.HTTPFlags
+
+	runCmd := func(cmd *cobra.Command, args []string) error {
+		portsStringSlice := []string{}
+		for _, port := range flags.ValidatedPorts {
+			portsStringSlice = append(portsStringSlice, strconv.FormatUint(uint64(port), 10))
+		}
+
+		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, httpTypes.GetColumns())
+		if err != nil {
+			return commonutils.WrapInErrParserCreate(err)
+		}
+
+		httpGadget := &TraceGadget[httpTypes.Event]{
+			name:        "http",
+			commonFlags: &commonFlags,
+			parser:      parser,
+			params: map[string]string{
+				"ports": strings.Join(portsStringSlice, ","),
+			},
+		}
+
+		return httpGadget.Run()
+	}
+
+	cmd := commontrace.NewHTTPCmd(runCmd, &flags)
+
+	utils.AddCommonFlags(cmd, &commonFlags)
+
+	return cmd
+}
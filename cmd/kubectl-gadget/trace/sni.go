@@ -15,6 +15,8 @@
 package trace
 
 import (
+	"strconv"
+
 	"github.com/spf13/cobra"
 
 	commontrace "github.com/inspektor-gadget/inspektor-gadget/cmd/common/trace"
@@ -25,6 +27,8 @@ import (
 
 func newSNICmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.SNIFlags
+
 	runCmd := func(cmd *cobra.Command, args []string) error {
 		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, sniTypes.GetColumns())
 		if err != nil {
@@ -35,12 +39,16 @@ func newSNICmd() *cobra.Command {
 			name:        "snisnoop",
 			commonFlags: &commonFlags,
 			parser:      parser,
+			params: map[string]string{
+				"negotiated": strconv.FormatBool(flags.Negotiated),
+				"withoutsni": strconv.FormatBool(flags.WithoutSNI),
+			},
 		}
 
 		return sniGadget.Run()
 	}
 
-	cmd := commontrace.NewSNICmd(runCmd)
+	cmd := commontrace.NewSNICmd(runCmd, &flags)
 	utils.AddCommonFlags(cmd, &commonFlags)
 
 	return cmd
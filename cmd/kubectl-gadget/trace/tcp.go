@@ -15,6 +15,8 @@
 package trace
 
 import (
+	"strconv"
+
 	"github.com/spf13/cobra"
 
 	commontrace "github.com/inspektor-gadget/inspektor-gadget/cmd/common/trace"
@@ -25,9 +27,15 @@ import (
 
 func newTCPCmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.TCPFlags
 
 	runCmd := func(cmd *cobra.Command, args []string) error {
-		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, tcpTypes.GetColumns())
+		cols := tcpTypes.GetColumns()
+		if flags.Lifetime {
+			cols = tcpTypes.GetLifetimeColumns()
+		}
+
+		parser, err := commonutils.NewGadgetParserWithK8sInfo(&commonFlags.OutputConfig, cols)
 		if err != nil {
 			return commonutils.WrapInErrParserCreate(err)
 		}
@@ -36,12 +44,15 @@ func newTCPCmd() *cobra.Command {
 			name:        "tcptracer",
 			commonFlags: &commonFlags,
 			parser:      parser,
+			params: map[string]string{
+				"lifetime": strconv.FormatBool(flags.Lifetime),
+			},
 		}
 
 		return tcpGadget.Run()
 	}
 
-	cmd := commontrace.NewTCPCmd(runCmd)
+	cmd := commontrace.NewTCPCmd(runCmd, &flags)
 
 	utils.AddCommonFlags(cmd, &commonFlags)
 
@@ -15,6 +15,8 @@
 package trace
 
 import (
+	"strconv"
+
 	"github.com/spf13/cobra"
 
 	commontrace "github.com/inspektor-gadget/inspektor-gadget/cmd/common/trace"
@@ -25,6 +27,7 @@ import (
 
 func newTcpconnectCmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.TcpconnectFlags
 
 	runCmd := func(*cobra.Command, []string) error {
 		parser, err := commonutils.NewGadgetParserWithK8sInfo(
@@ -39,12 +42,15 @@ func newTcpconnectCmd() *cobra.Command {
 			name:        "tcpconnect",
 			commonFlags: &commonFlags,
 			parser:      parser,
+			params: map[string]string{
+				"minlatency": strconv.FormatUint(uint64(flags.MinLatency), 10),
+			},
 		}
 
 		return tcpconnectGadget.Run()
 	}
 
-	cmd := commontrace.NewTcpconnectCmd(runCmd)
+	cmd := commontrace.NewTcpconnectCmd(runCmd, &flags)
 
 	utils.AddCommonFlags(cmd, &commonFlags)
 
@@ -96,10 +96,16 @@ func NewTraceCmd() *cobra.Command {
 
 	traceCmd.AddCommand(newBindCmd())
 	traceCmd.AddCommand(newCapabilitiesCmd())
+	traceCmd.AddCommand(newCredsCmd())
 	traceCmd.AddCommand(newDNSCmd())
 	traceCmd.AddCommand(newExecCmd())
+	traceCmd.AddCommand(newExitCmd())
+	traceCmd.AddCommand(newFsopsCmd())
 	traceCmd.AddCommand(newFsSlowerCmd())
+	traceCmd.AddCommand(newHTTPCmd())
+	traceCmd.AddCommand(newKernelLoadCmd())
 	traceCmd.AddCommand(newMountCmd())
+	traceCmd.AddCommand(newNamespacesCmd())
 	traceCmd.AddCommand(newNetworkCmd())
 	traceCmd.AddCommand(newOOMKillCmd())
 	traceCmd.AddCommand(newOpenCmd())
@@ -107,6 +113,8 @@ func NewTraceCmd() *cobra.Command {
 	traceCmd.AddCommand(newSNICmd())
 	traceCmd.AddCommand(newTCPCmd())
 	traceCmd.AddCommand(newTcpconnectCmd())
+	traceCmd.AddCommand(newTcpdropCmd())
+	traceCmd.AddCommand(newTcpretransCmd())
 
 	return traceCmd
 }
@@ -26,7 +26,6 @@ import (
 	commonaudit "github.com/inspektor-gadget/inspektor-gadget/cmd/common/audit"
 	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/local-gadget/utils"
-	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/audit/seccomp/tracer"
 	seccompauditTypes "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/audit/seccomp/types"
 	localgadgetmanager "github.com/inspektor-gadget/inspektor-gadget/pkg/local-gadget-manager"
@@ -37,7 +36,7 @@ func newSeccompCmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
 
 	runCmd := func(cmd *cobra.Command, args []string) error {
-		localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs)
+		localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs, commonFlags.ContainerCollectionOptions()...)
 		if err != nil {
 			return commonutils.WrapInErrManagerInit(err)
 		}
@@ -80,9 +79,7 @@ func newSeccompCmd() *cobra.Command {
 
 		// TODO: Improve filtering, see further details in
 		// https://github.com/inspektor-gadget/inspektor-gadget/issues/644.
-		containerSelector := containercollection.ContainerSelector{
-			Name: commonFlags.Containername,
-		}
+		containerSelector := commonFlags.ContainerSelector()
 
 		// Create mount namespace map to filter by containers
 		mountnsmap, err := localGadgetManager.CreateMountNsMap(containerSelector)
@@ -41,15 +41,13 @@ func NewListContainersCmd() *cobra.Command {
 		Use:   "list-containers",
 		Short: "List all containers",
 		RunE: func(*cobra.Command, []string) error {
-			localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs)
+			localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs, commonFlags.ContainerCollectionOptions()...)
 			if err != nil {
 				return commonutils.WrapInErrManagerInit(err)
 			}
 			defer localGadgetManager.Close()
 
-			selector := containercollection.ContainerSelector{
-				Name: commonFlags.Containername,
-			}
+			selector := commonFlags.ContainerSelector()
 
 			if !optionWatch {
 				parser, err := commonutils.NewGadgetParserWithRuntimeInfo(&commonFlags.OutputConfig, containercollection.GetColumns())
@@ -74,7 +72,8 @@ func NewListContainersCmd() *cobra.Command {
 			if err != nil {
 				return commonutils.WrapInErrParserCreate(err)
 			}
-			containers := localGadgetManager.ContainerCollection.Subscribe(
+			// Printing doesn't need to delay the containers
+			containers := localGadgetManager.ContainerCollection.SubscribeAsync(
 				localGadgetSubKey,
 				selector,
 				func(event containercollection.PubSubEvent) {
@@ -82,6 +81,7 @@ func NewListContainersCmd() *cobra.Command {
 						fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
 					}
 				},
+				64,
 			)
 			defer localGadgetManager.ContainerCollection.Unsubscribe(localGadgetSubKey)
 
@@ -19,7 +19,6 @@ import (
 
 	commonprofile "github.com/inspektor-gadget/inspektor-gadget/cmd/common/profile"
 	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
-	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-collection/gadgets/profile"
 	cpuTracer "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/profile/cpu/tracer"
 	cpuTypes "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/profile/cpu/types"
@@ -40,7 +39,7 @@ func newCPUCmd() *cobra.Command {
 			return commonutils.WrapInErrParserCreate(err)
 		}
 
-		localGadgetManager, err := localgadgetmanager.NewManager(profileFlags.RuntimeConfigs)
+		localGadgetManager, err := localgadgetmanager.NewManager(profileFlags.RuntimeConfigs, profileFlags.ContainerCollectionOptions()...)
 		if err != nil {
 			return commonutils.WrapInErrManagerInit(err)
 		}
@@ -48,9 +47,7 @@ func newCPUCmd() *cobra.Command {
 
 		// TODO: Improve filtering, see further details in
 		// https://github.com/inspektor-gadget/inspektor-gadget/issues/644.
-		containerSelector := containercollection.ContainerSelector{
-			Name: profileFlags.Containername,
-		}
+		containerSelector := profileFlags.ContainerSelector()
 
 		// Create mount namespace map to filter by containers
 		mountnsmap, err := localGadgetManager.CreateMountNsMap(containerSelector)
@@ -35,7 +35,7 @@ type SnapshotGadget[Event commonsnapshot.SnapshotEvent] struct {
 // Run runs a SnapshotGadget and prints the output after parsing it using the
 // SnapshotParser's methods.
 func (g *SnapshotGadget[Event]) Run() error {
-	localGadgetManager, err := localgadgetmanager.NewManager(g.commonFlags.RuntimeConfigs)
+	localGadgetManager, err := localgadgetmanager.NewManager(g.commonFlags.RuntimeConfigs, g.commonFlags.ContainerCollectionOptions()...)
 	if err != nil {
 		return commonutils.WrapInErrManagerInit(err)
 	}
@@ -43,11 +43,9 @@ func (g *SnapshotGadget[Event]) Run() error {
 
 	// TODO: Improve filtering, see further details in
 	// https://github.com/inspektor-gadget/inspektor-gadget/issues/644.
-	containerSelector := &containercollection.ContainerSelector{
-		Name: g.commonFlags.Containername,
-	}
+	containerSelector := g.commonFlags.ContainerSelector()
 
-	allEvents, err := g.runTracer(localGadgetManager, containerSelector)
+	allEvents, err := g.runTracer(localGadgetManager, &containerSelector)
 	if err != nil {
 		return commonutils.WrapInErrGadgetTracerCreateAndRun(err)
 	}
@@ -29,7 +29,6 @@ import (
 	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/local-gadget/utils"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/sort"
-	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-collection/gadgets/trace"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/top"
@@ -47,7 +46,7 @@ type TopGadget[Stats any] struct {
 // Run runs a TopGadget and prints the output after parsing it using the
 // TopParser's methods.
 func (g *TopGadget[Stats]) Run(args []string) error {
-	localGadgetManager, err := localgadgetmanager.NewManager(g.commonFlags.RuntimeConfigs)
+	localGadgetManager, err := localgadgetmanager.NewManager(g.commonFlags.RuntimeConfigs, g.commonFlags.ContainerCollectionOptions()...)
 	if err != nil {
 		return commonutils.WrapInErrManagerInit(err)
 	}
@@ -55,9 +54,7 @@ func (g *TopGadget[Stats]) Run(args []string) error {
 
 	// TODO: Improve filtering, see further details in
 	// https://github.com/inspektor-gadget/inspektor-gadget/issues/644.
-	containerSelector := containercollection.ContainerSelector{
-		Name: g.commonFlags.Containername,
-	}
+	containerSelector := g.commonFlags.ContainerSelector()
 
 	// Create mount namespace map to filter by containers
 	mountnsmap, err := localGadgetManager.CreateMountNsMap(containerSelector)
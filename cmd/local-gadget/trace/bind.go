@@ -32,7 +32,12 @@ func newBindCmd() *cobra.Command {
 	var flags commontrace.BindFlags
 
 	runCmd := func(*cobra.Command, []string) error {
-		parser, err := commonutils.NewGadgetParserWithRuntimeInfo(&commonFlags.OutputConfig, bindTypes.GetColumns())
+		columns := bindTypes.GetColumns()
+		if flags.Listen {
+			columns = bindTypes.GetListenColumns()
+		}
+
+		parser, err := commonutils.NewGadgetParserWithRuntimeInfo(&commonFlags.OutputConfig, columns)
 		if err != nil {
 			return commonutils.WrapInErrParserCreate(err)
 		}
@@ -46,6 +51,7 @@ func newBindCmd() *cobra.Command {
 					TargetPid:    flags.TargetPid,
 					TargetPorts:  flags.ValidatedTargetPorts,
 					IgnoreErrors: flags.IgnoreErrors,
+					Listen:       flags.Listen,
 				}
 
 				return bindTracer.NewTracer(config, enricher, eventCallback)
@@ -32,9 +32,14 @@ func newCapabilitiesCmd() *cobra.Command {
 	var flags commontrace.CapabilitiesFlags
 
 	runCmd := func(*cobra.Command, []string) error {
+		columns := capabilitiesTypes.GetColumns()
+		if flags.PrintStack {
+			columns = capabilitiesTypes.GetPrintStackColumns()
+		}
+
 		parser, err := commonutils.NewGadgetParserWithRuntimeInfo(
 			&commonFlags.OutputConfig,
-			capabilitiesTypes.GetColumns(),
+			columns,
 		)
 		if err != nil {
 			return commonutils.WrapInErrParserCreate(err)
@@ -48,6 +53,7 @@ func newCapabilitiesCmd() *cobra.Command {
 					MountnsMap: mountnsmap,
 					AuditOnly:  flags.AuditOnly,
 					Unique:     flags.Unique,
+					PrintStack: flags.PrintStack,
 				}
 
 				return capabilitiesTracer.NewTracer(config, enricher, eventCallback)
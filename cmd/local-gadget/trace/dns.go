@@ -36,6 +36,7 @@ import (
 
 func newDNSCmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.DNSFlags
 
 	// The DNS gadget works in a different way than most gadgets: It
 	// attaches a new eBPF program to each container when it's
@@ -43,7 +44,7 @@ func newDNSCmd() *cobra.Command {
 	// namespaces IDs to filter the events. For this reason we can't
 	// use the TraceGadget implementation here.
 	runCmd := func(*cobra.Command, []string) error {
-		localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs)
+		localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs, commonFlags.ContainerCollectionOptions()...)
 		if err != nil {
 			return commonutils.WrapInErrManagerInit(err)
 		}
@@ -89,7 +90,7 @@ func newDNSCmd() *cobra.Command {
 			}
 		}
 
-		tracer, err := dnsTracer.NewTracer()
+		tracer, err := dnsTracer.NewTracer(&dnsTracer.Config{ResponsesOnly: flags.ResponsesOnly})
 		if err != nil {
 			return commonutils.WrapInErrGadgetTracerCreateAndRun(err)
 		}
@@ -99,9 +100,7 @@ func newDNSCmd() *cobra.Command {
 			fmt.Println(parser.BuildColumnsHeader())
 		}
 
-		selector := containercollection.ContainerSelector{
-			Name: commonFlags.Containername,
-		}
+		selector := commonFlags.ContainerSelector()
 
 		config := &networktracer.ConnectToContainerCollectionConfig[dnsTypes.Event]{
 			Tracer:        tracer,
@@ -123,7 +122,7 @@ func newDNSCmd() *cobra.Command {
 		return nil
 	}
 
-	cmd := commontrace.NewDNSCmd(runCmd)
+	cmd := commontrace.NewDNSCmd(runCmd, &flags)
 
 	utils.AddCommonFlags(cmd, &commonFlags)
 
@@ -45,10 +45,11 @@ func newSignalCmd() *cobra.Command {
 			parser:      parser,
 			createAndRunTracer: func(mountnsmap *ebpf.Map, enricher gadgets.DataEnricher, eventCallback func(signalTypes.Event)) (trace.Tracer, error) {
 				return signalTracer.NewTracer(&signalTracer.Config{
-					MountnsMap:   mountnsmap,
-					TargetSignal: flags.Sig,
-					TargetPid:    int32(flags.Pid),
-					FailedOnly:   flags.Failed,
+					MountnsMap:         mountnsmap,
+					TargetSignal:       flags.Sig,
+					TargetPid:          int32(flags.Pid),
+					FailedOnly:         flags.Failed,
+					CrossContainerOnly: flags.CrossContainer,
 				}, enricher, eventCallback)
 			},
 		}
@@ -36,6 +36,7 @@ import (
 
 func newSNICmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.SNIFlags
 
 	// The SNI gadget works in a different way than most gadgets: It
 	// attaches a new eBPF program to each container when it's
@@ -43,7 +44,7 @@ func newSNICmd() *cobra.Command {
 	// namespaces IDs to filter the events. For this reason we can't
 	// use the TraceGadget implementation here.
 	runCmd := func(*cobra.Command, []string) error {
-		localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs)
+		localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs, commonFlags.ContainerCollectionOptions()...)
 		if err != nil {
 			return commonutils.WrapInErrManagerInit(err)
 		}
@@ -89,7 +90,10 @@ func newSNICmd() *cobra.Command {
 			}
 		}
 
-		tracer, err := sniTracer.NewTracer()
+		tracer, err := sniTracer.NewTracer(&sniTracer.Config{
+			Negotiated: flags.Negotiated,
+			WithoutSNI: flags.WithoutSNI,
+		})
 		if err != nil {
 			return commonutils.WrapInErrGadgetTracerCreateAndRun(err)
 		}
@@ -99,9 +103,7 @@ func newSNICmd() *cobra.Command {
 			fmt.Println(parser.BuildColumnsHeader())
 		}
 
-		selector := containercollection.ContainerSelector{
-			Name: commonFlags.Containername,
-		}
+		selector := commonFlags.ContainerSelector()
 
 		config := &networktracer.ConnectToContainerCollectionConfig[sniTypes.Event]{
 			Tracer:        tracer,
@@ -123,7 +125,7 @@ func newSNICmd() *cobra.Command {
 		return nil
 	}
 
-	cmd := commontrace.NewSNICmd(runCmd)
+	cmd := commontrace.NewSNICmd(runCmd, &flags)
 
 	utils.AddCommonFlags(cmd, &commonFlags)
 
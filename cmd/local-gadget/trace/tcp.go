@@ -29,9 +29,15 @@ import (
 
 func newTCPCmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.TCPFlags
 
 	runCmd := func(*cobra.Command, []string) error {
-		parser, err := commonutils.NewGadgetParserWithRuntimeInfo(&commonFlags.OutputConfig, tcpTypes.GetColumns())
+		cols := tcpTypes.GetColumns()
+		if flags.Lifetime {
+			cols = tcpTypes.GetLifetimeColumns()
+		}
+
+		parser, err := commonutils.NewGadgetParserWithRuntimeInfo(&commonFlags.OutputConfig, cols)
 		if err != nil {
 			return commonutils.WrapInErrParserCreate(err)
 		}
@@ -40,14 +46,17 @@ func newTCPCmd() *cobra.Command {
 			commonFlags: &commonFlags,
 			parser:      parser,
 			createAndRunTracer: func(mountnsmap *ebpf.Map, enricher gadgets.DataEnricher, eventCallback func(tcpTypes.Event)) (trace.Tracer, error) {
-				return tcpTracer.NewTracer(&tcpTracer.Config{MountnsMap: mountnsmap}, enricher, eventCallback)
+				return tcpTracer.NewTracer(&tcpTracer.Config{
+					MountnsMap: mountnsmap,
+					Lifetime:   flags.Lifetime,
+				}, enricher, eventCallback)
 			},
 		}
 
 		return tcpGadget.Run()
 	}
 
-	cmd := commontrace.NewTCPCmd(runCmd)
+	cmd := commontrace.NewTCPCmd(runCmd, &flags)
 
 	utils.AddCommonFlags(cmd, &commonFlags)
 
@@ -29,6 +29,7 @@ import (
 
 func newTcpconnectCmd() *cobra.Command {
 	var commonFlags utils.CommonFlags
+	var flags commontrace.TcpconnectFlags
 
 	runCmd := func(*cobra.Command, []string) error {
 		parser, err := commonutils.NewGadgetParserWithRuntimeInfo(
@@ -43,14 +44,17 @@ func newTcpconnectCmd() *cobra.Command {
 			commonFlags: &commonFlags,
 			parser:      parser,
 			createAndRunTracer: func(mountnsmap *ebpf.Map, enricher gadgets.DataEnricher, eventCallback func(tcpconnectTypes.Event)) (trace.Tracer, error) {
-				return tcpconnectTracer.NewTracer(&tcpconnectTracer.Config{MountnsMap: mountnsmap}, enricher, eventCallback)
+				return tcpconnectTracer.NewTracer(&tcpconnectTracer.Config{
+					MountnsMap: mountnsmap,
+					MinLatency: flags.MinLatency,
+				}, enricher, eventCallback)
 			},
 		}
 
 		return tcpconnectGadget.Run()
 	}
 
-	cmd := commontrace.NewTcpconnectCmd(runCmd)
+	cmd := commontrace.NewTcpconnectCmd(runCmd, &flags)
 
 	utils.AddCommonFlags(cmd, &commonFlags)
 
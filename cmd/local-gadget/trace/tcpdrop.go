@@ -0,0 +1,149 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	commontrace "github.com/inspektor-gadget/inspektor-gadget/cmd/common/trace"
+	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/local-gadget/utils"
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	tcpdropTracer "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/tcpdrop/tracer"
+	tcpdropTypes "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/tcpdrop/types"
+	localgadgetmanager "github.com/inspektor-gadget/inspektor-gadget/pkg/local-gadget-manager"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func newTcpdropCmd() *cobra.Command {
+	var commonFlags utils.CommonFlags
+	var flags commontrace.TcpdropFlags
+
+	// The tcpdrop gadget works in a different way than most gadgets:
+	// packets are dropped outside of the context of the process owning
+	// the socket, so it filters events by network namespace instead of
+	// using an eBPF map with the mount namespaces IDs. When no container
+	// is selected, drops in all the network namespaces are traced. For
+	// this reason we can't use the TraceGadget implementation here.
+	runCmd := func(*cobra.Command, []string) error {
+		localGadgetManager, err := localgadgetmanager.NewManager(commonFlags.RuntimeConfigs, commonFlags.ContainerCollectionOptions()...)
+		if err != nil {
+			return commonutils.WrapInErrManagerInit(err)
+		}
+		defer localGadgetManager.Close()
+
+		// local-gadget is designed to trace containers, hence enable this column
+		cols := tcpdropTypes.GetColumns()
+		col, _ := cols.GetColumn("container")
+		col.Visible = true
+
+		parser, err := commonutils.NewGadgetParserWithRuntimeInfo(&commonFlags.OutputConfig, cols)
+		if err != nil {
+			return commonutils.WrapInErrParserCreate(err)
+		}
+
+		eventCallback := func(event tcpdropTypes.Event) {
+			baseEvent := event.GetBaseEvent()
+			if baseEvent.Type != eventtypes.NORMAL {
+				commonutils.HandleSpecialEvent(baseEvent, commonFlags.Verbose)
+				return
+			}
+
+			switch commonFlags.OutputMode {
+			case commonutils.OutputModeJSON:
+				b, err := json.Marshal(event)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %s", fmt.Sprint(commonutils.WrapInErrMarshalOutput(err)))
+					return
+				}
+
+				fmt.Println(string(b))
+			case commonutils.OutputModeColumns:
+				fallthrough
+			case commonutils.OutputModeCustomColumns:
+				fmt.Println(parser.TransformIntoColumns(&event))
+			}
+		}
+
+		selector := commonFlags.ContainerSelector()
+
+		config := &tcpdropTracer.Config{
+			FilterByNetns: selector.Name != "",
+			KernelStack:   flags.KernelStack,
+		}
+		tracer, err := tcpdropTracer.NewTracer(config, &localGadgetManager.ContainerCollection, eventCallback)
+		if err != nil {
+			return commonutils.WrapInErrGadgetTracerCreateAndRun(err)
+		}
+		defer tracer.Stop()
+
+		if commonFlags.OutputMode != commonutils.OutputModeJSON {
+			fmt.Println(parser.BuildColumnsHeader())
+		}
+
+		if config.FilterByNetns {
+			addNetns := func(container *containercollection.Container) {
+				if err := tracer.AddNetns(container.Netns); err != nil {
+					msg := fmt.Sprintf("start tracing container %q: %s", container.Name, err)
+					eventCallback(tcpdropTypes.Base(eventtypes.Err(msg)))
+				}
+			}
+			removeNetns := func(container *containercollection.Container) {
+				if err := tracer.RemoveNetns(container.Netns); err != nil {
+					msg := fmt.Sprintf("stop tracing container %q: %s", container.Name, err)
+					eventCallback(tcpdropTypes.Base(eventtypes.Err(msg)))
+				}
+			}
+
+			subKey := uuid.New().String()
+			containers := localGadgetManager.Subscribe(
+				subKey,
+				selector,
+				func(event containercollection.PubSubEvent) {
+					switch event.Type {
+					case containercollection.EventTypeAddContainer:
+						addNetns(event.Container)
+					case containercollection.EventTypeRemoveContainer:
+						removeNetns(event.Container)
+					}
+				},
+			)
+			defer localGadgetManager.Unsubscribe(subKey)
+
+			for _, container := range containers {
+				addNetns(container)
+			}
+		}
+
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+		<-stop
+
+		return nil
+	}
+
+	cmd := commontrace.NewTcpdropCmd(runCmd, &flags)
+
+	utils.AddCommonFlags(cmd, &commonFlags)
+
+	return cmd
+}
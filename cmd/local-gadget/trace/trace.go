@@ -27,7 +27,6 @@ import (
 	commontrace "github.com/inspektor-gadget/inspektor-gadget/cmd/common/trace"
 	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/local-gadget/utils"
-	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-collection/gadgets/trace"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	localgadgetmanager "github.com/inspektor-gadget/inspektor-gadget/pkg/local-gadget-manager"
@@ -44,7 +43,7 @@ type TraceGadget[Event commontrace.TraceEvent] struct {
 // Run runs a TraceGadget and prints the output after parsing it using the
 // TraceParser's methods.
 func (g *TraceGadget[Event]) Run() error {
-	localGadgetManager, err := localgadgetmanager.NewManager(g.commonFlags.RuntimeConfigs)
+	localGadgetManager, err := localgadgetmanager.NewManager(g.commonFlags.RuntimeConfigs, g.commonFlags.ContainerCollectionOptions()...)
 	if err != nil {
 		return commonutils.WrapInErrManagerInit(err)
 	}
@@ -52,9 +51,7 @@ func (g *TraceGadget[Event]) Run() error {
 
 	// TODO: Improve filtering, see further details in
 	// https://github.com/inspektor-gadget/inspektor-gadget/issues/644.
-	containerSelector := containercollection.ContainerSelector{
-		Name: g.commonFlags.Containername,
-	}
+	containerSelector := g.commonFlags.ContainerSelector()
 
 	// Create mount namespace map to filter by containers
 	mountnsmap, err := localGadgetManager.CreateMountNsMap(containerSelector)
@@ -109,15 +106,23 @@ func NewTraceCmd() *cobra.Command {
 
 	traceCmd.AddCommand(newBindCmd())
 	traceCmd.AddCommand(newCapabilitiesCmd())
+	traceCmd.AddCommand(newCredsCmd())
 	traceCmd.AddCommand(newDNSCmd())
 	traceCmd.AddCommand(newExecCmd())
+	traceCmd.AddCommand(newExitCmd())
+	traceCmd.AddCommand(newFsopsCmd())
 	traceCmd.AddCommand(newFsSlowerCmd())
+	traceCmd.AddCommand(newKernelLoadCmd())
 	traceCmd.AddCommand(newOOMKillCmd())
 	traceCmd.AddCommand(newOpenCmd())
 	traceCmd.AddCommand(newMountCmd())
+	traceCmd.AddCommand(newNamespacesCmd())
 	traceCmd.AddCommand(newTCPCmd())
 	traceCmd.AddCommand(newTcpconnectCmd())
+	traceCmd.AddCommand(newTcpdropCmd())
+	traceCmd.AddCommand(newTcpretransCmd())
 	traceCmd.AddCommand(newSignalCmd())
+	traceCmd.AddCommand(newHTTPCmd())
 	traceCmd.AddCommand(newSNICmd())
 
 	return traceCmd
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
 	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
 
@@ -37,6 +38,15 @@ type CommonFlags struct {
 	// Containername allows to filter containers by name.
 	Containername string
 
+	// Host allows to filter processes running in the host mount namespace.
+	Host bool
+
+	// SystemdUnit allows to filter processes of a systemd service.
+	SystemdUnit string
+
+	// StateFile is the file where containers are persisted between runs.
+	StateFile string
+
 	// The name of the container runtimes to be used separated by comma.
 	Runtimes string
 
@@ -47,6 +57,18 @@ type CommonFlags struct {
 
 func AddCommonFlags(command *cobra.Command, commonFlags *CommonFlags) {
 	command.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Workload selection
+		selected := 0
+		for _, set := range []bool{commonFlags.Containername != "", commonFlags.Host, commonFlags.SystemdUnit != ""} {
+			if set {
+				selected++
+			}
+		}
+		if selected > 1 {
+			return commonutils.WrapInErrInvalidArg("--containername / --host / --systemd-unit",
+				fmt.Errorf("only one of them can be used at a time"))
+		}
+
 		// Runtimes Configuration
 		parts := strings.Split(commonFlags.Runtimes, ",")
 
@@ -103,6 +125,30 @@ func AddCommonFlags(command *cobra.Command, commonFlags *CommonFlags) {
 		"Show only data from containers with that name",
 	)
 
+	command.PersistentFlags().BoolVarP(
+		&commonFlags.Host,
+		"host",
+		"",
+		false,
+		"Show only data from processes running on the host, outside of any container",
+	)
+
+	command.PersistentFlags().StringVarP(
+		&commonFlags.SystemdUnit,
+		"systemd-unit",
+		"",
+		"",
+		"Show only data from the processes of that systemd service (e.g. kubelet.service)",
+	)
+
+	command.PersistentFlags().StringVarP(
+		&commonFlags.StateFile,
+		"state-file",
+		"",
+		"",
+		"File where containers are persisted to be restored in the next run (disabled if empty)",
+	)
+
 	command.PersistentFlags().StringVarP(
 		&commonFlags.Runtimes,
 		"runtimes", "r",
@@ -111,3 +157,40 @@ func AddCommonFlags(command *cobra.Command, commonFlags *CommonFlags) {
 			strings.Join(containerutils.AvailableRuntimes, ", ")),
 	)
 }
+
+// ContainerCollectionOptions returns the options needed by the local gadget
+// manager to track the workloads selected by the user.
+func (f *CommonFlags) ContainerCollectionOptions() []containercollection.ContainerCollectionOption {
+	opts := []containercollection.ContainerCollectionOption{}
+
+	switch {
+	case f.Host:
+		opts = append(opts, containercollection.WithHostContainer())
+	case f.SystemdUnit != "":
+		opts = append(opts, containercollection.WithSystemdUnitContainers(f.SystemdUnit))
+	}
+
+	if f.StateFile != "" {
+		opts = append(opts, containercollection.WithPersistentState(f.StateFile))
+	}
+
+	return opts
+}
+
+// ContainerSelector returns the selector matching the container, the host or
+// the systemd unit requested by the user.
+func (f *CommonFlags) ContainerSelector() containercollection.ContainerSelector {
+	switch {
+	case f.Host:
+		return containercollection.ContainerSelector{
+			Name: containercollection.HostContainerName,
+		}
+	case f.SystemdUnit != "":
+		return containercollection.ContainerSelector{
+			Name: f.SystemdUnit,
+		}
+	}
+	return containercollection.ContainerSelector{
+		Name: f.Containername,
+	}
+}
@@ -45,8 +45,8 @@ func main() {
 			event.QType, qr, event.DNSName)
 	}
 
-	// Create tracer. In this case no parameters are passed.
-	tracer, err := tracer.NewTracer()
+	// Create tracer. In this case the default configuration is used.
+	tracer, err := tracer.NewTracer(&tracer.Config{})
 	if err != nil {
 		fmt.Printf("error creating tracer: %s\n", err)
 		return
@@ -48,6 +48,7 @@ var (
 	fallbackPodInformer bool
 	hookMode            string
 	socketfile          string
+	stateFile           string
 	method              string
 	label               string
 	tracerid            string
@@ -79,6 +80,7 @@ func init() {
 	flag.BoolVar(&dump, "dump", false, "Dump state for debugging")
 	flag.BoolVar(&liveness, "liveness", false, "Execute as client and perform liveness probe")
 	flag.BoolVar(&fallbackPodInformer, "fallback-podinformer", true, "Use pod informer as a fallback for main hook")
+	flag.StringVar(&stateFile, "state-file", "", "File where containers are persisted to be restored after a restart (disabled if empty)")
 }
 
 func main() {
@@ -236,6 +238,7 @@ func main() {
 			NodeName:            node,
 			HookMode:            hookMode,
 			FallbackPodInformer: fallbackPodInformer,
+			StateFile:           stateFile,
 		})
 
 		if err != nil {
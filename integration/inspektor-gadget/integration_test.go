@@ -362,6 +362,7 @@ func TestBindsnoop(t *testing.T) {
 			expectedEntry := &bindTypes.Event{
 				Event:     BuildBaseEvent(ns),
 				Comm:      "nc",
+				Operation: "bind",
 				Protocol:  "TCP",
 				Addr:      "::",
 				Port:      9090,
@@ -525,6 +526,7 @@ func TestDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeQuery,
 					Nameserver: "8.8.4.4",
 					PktType:    "OUTGOING",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "A",
 				},
@@ -534,6 +536,7 @@ func TestDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeResponse,
 					Nameserver: "8.8.4.4",
 					PktType:    "HOST",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "A",
 					Rcode:      "NoError",
@@ -544,6 +547,7 @@ func TestDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeQuery,
 					Nameserver: "8.8.4.4",
 					PktType:    "OUTGOING",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "A",
 				},
@@ -553,6 +557,7 @@ func TestDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeResponse,
 					Nameserver: "8.8.4.4",
 					PktType:    "HOST",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "AAAA",
 					Rcode:      "NoError",
@@ -567,6 +572,8 @@ func TestDns(t *testing.T) {
 			normalize := func(e *dnsTypes.Event) {
 				e.Node = ""
 				e.ID = "0000"
+				e.Latency = 0
+				e.Addresses = nil
 			}
 
 			return ExpectEntriesToMatch(output, normalize, expectedEntries...)
@@ -659,19 +666,25 @@ func TestExecsnoop(t *testing.T) {
 		ExpectedOutputFn: func(output string) error {
 			expectedEntries := []*execTypes.Event{
 				{
-					Event: BuildBaseEvent(ns),
-					Comm:  "sh",
-					Args:  shArgs,
+					Event:   BuildBaseEvent(ns),
+					Comm:    "sh",
+					Args:    shArgs,
+					ExePath: "/bin/sh",
+					Cwd:     "/",
 				},
 				{
-					Event: BuildBaseEvent(ns),
-					Comm:  "date",
-					Args:  dateArgs,
+					Event:   BuildBaseEvent(ns),
+					Comm:    "date",
+					Args:    dateArgs,
+					ExePath: "/bin/date",
+					Cwd:     "/",
 				},
 				{
-					Event: BuildBaseEvent(ns),
-					Comm:  "sleep",
-					Args:  sleepArgs,
+					Event:   BuildBaseEvent(ns),
+					Comm:    "sleep",
+					Args:    sleepArgs,
+					ExePath: "/bin/sleep",
+					Cwd:     "/",
 				},
 			}
 
@@ -758,10 +771,11 @@ func TestFsslower(t *testing.T) {
 		StartAndStop: true,
 		ExpectedOutputFn: func(output string) error {
 			expectedEntry := &fsslowerType.Event{
-				Event: BuildBaseEvent(ns),
-				Comm:  "cat",
-				File:  "foo",
-				Op:    "R",
+				Event:      BuildBaseEvent(ns),
+				Comm:       "cat",
+				File:       "foo",
+				Op:         "R",
+				Filesystem: fsType,
 			}
 
 			normalize := func(e *fsslowerType.Event) {
@@ -771,6 +785,9 @@ func TestFsslower(t *testing.T) {
 				e.Bytes = 0
 				e.Offset = 0
 				e.Latency = 0
+				// The root of the container is an overlay whose layers
+				// use internal mounts, so it depends on the runtime.
+				e.MountPath = ""
 			}
 
 			return ExpectEntriesToMatch(output, normalize, expectedEntry)
@@ -964,6 +981,7 @@ func TestOomkill(t *testing.T) {
 			expectedEntry := &oomkillTypes.Event{
 				Event:      BuildBaseEvent(ns),
 				KilledComm: "tail",
+				Scope:      oomkillTypes.ScopeMemcg,
 			}
 			expectedEntry.Container = "test-pod-container"
 
@@ -974,6 +992,9 @@ func TestOomkill(t *testing.T) {
 				e.TriggeredPid = 0
 				e.TriggeredComm = ""
 				e.MountNsID = 0
+				e.MemcgID = 0
+				e.MemLimit = 0
+				e.MemUsage = 0
 			}
 
 			return ExpectAllToMatch(output, normalize, expectedEntry)
@@ -1216,6 +1237,9 @@ func TestSigsnoop(t *testing.T) {
 				Comm:   "sh",
 				Signal: "SIGTERM",
 			}
+			expectedEntry.TargetNamespace = expectedEntry.Namespace
+			expectedEntry.TargetPod = expectedEntry.Pod
+			expectedEntry.TargetContainer = expectedEntry.Container
 
 			normalize := func(e *signalTypes.Event) {
 				e.Node = ""
@@ -1223,6 +1247,7 @@ func TestSigsnoop(t *testing.T) {
 				e.TargetPid = 0
 				e.Retval = 0
 				e.MountNsID = 0
+				e.TargetMountNsID = 0
 			}
 
 			return ExpectEntriesToMatch(output, normalize, expectedEntry)
@@ -1253,6 +1278,7 @@ func TestSnisnoop(t *testing.T) {
 			expectedEntry := &sniTypes.Event{
 				Event: BuildBaseEvent(ns),
 				Name:  "inspektor-gadget.io",
+				Dport: 443,
 			}
 
 			// SNI gadget doesn't provide container data. Remove it.
@@ -1260,6 +1286,11 @@ func TestSnisnoop(t *testing.T) {
 
 			normalize := func(e *sniTypes.Event) {
 				e.Node = ""
+				e.Saddr = ""
+				e.Sport = 0
+				e.Daddr = ""
+				e.ALPN = nil
+				e.Versions = nil
 			}
 
 			return ExpectAllToMatch(output, normalize, expectedEntry)
@@ -1360,6 +1391,7 @@ func TestTcpconnect(t *testing.T) {
 				e.Node = ""
 				e.Pid = 0
 				e.MountNsID = 0
+				e.Latency = 0
 			}
 
 			return ExpectEntriesToMatch(output, normalize, expectedEntry)
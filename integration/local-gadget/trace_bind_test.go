@@ -32,12 +32,13 @@ func TestTraceBind(t *testing.T) {
 		StartAndStop: true,
 		ExpectedOutputFn: func(output string) error {
 			expectedEntry := &bindTypes.Event{
-				Event:    BuildBaseEvent(ns),
-				Comm:     "nc",
-				Protocol: "TCP",
-				Addr:     "::",
-				Port:     9090,
-				Options:  ".R...",
+				Event:     BuildBaseEvent(ns),
+				Comm:      "nc",
+				Operation: "bind",
+				Protocol:  "TCP",
+				Addr:      "::",
+				Port:      9090,
+				Options:   ".R...",
 			}
 
 			normalize := func(e *bindTypes.Event) {
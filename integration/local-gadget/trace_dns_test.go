@@ -38,6 +38,7 @@ func TestTraceDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeQuery,
 					Nameserver: "8.8.4.4",
 					PktType:    "OUTGOING",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "A",
 				},
@@ -46,6 +47,7 @@ func TestTraceDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeResponse,
 					Nameserver: "8.8.4.4",
 					PktType:    "HOST",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "A",
 					Rcode:      "NoError",
@@ -55,6 +57,7 @@ func TestTraceDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeQuery,
 					Nameserver: "8.8.4.4",
 					PktType:    "OUTGOING",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "AAAA",
 				},
@@ -63,6 +66,7 @@ func TestTraceDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeResponse,
 					Nameserver: "8.8.4.4",
 					PktType:    "HOST",
+					Protocol:   "UDP",
 					DNSName:    "inspektor-gadget.io.",
 					QType:      "AAAA",
 					Rcode:      "NoError",
@@ -72,6 +76,7 @@ func TestTraceDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeQuery,
 					Nameserver: "8.8.4.4",
 					PktType:    "OUTGOING",
+					Protocol:   "UDP",
 					DNSName:    "nodomain.inspektor-gadget.io.",
 					QType:      "A",
 				},
@@ -80,6 +85,7 @@ func TestTraceDns(t *testing.T) {
 					Qr:         dnsTypes.DNSPktTypeResponse,
 					Nameserver: "8.8.4.4",
 					PktType:    "HOST",
+					Protocol:   "UDP",
 					DNSName:    "nodomain.inspektor-gadget.io.",
 					QType:      "A",
 					Rcode:      "NXDomain",
@@ -93,6 +99,8 @@ func TestTraceDns(t *testing.T) {
 					e.Container = "test-pod"
 				}
 				e.ID = ""
+				e.Latency = 0
+				e.Addresses = nil
 			}
 
 			return ExpectEntriesToMatch(output, normalize, expectedEntries...)
@@ -38,19 +38,25 @@ func TestTraceExec(t *testing.T) {
 		ExpectedOutputFn: func(output string) error {
 			expectedEntries := []*execTypes.Event{
 				{
-					Event: BuildBaseEvent(ns),
-					Comm:  "sh",
-					Args:  shArgs,
+					Event:   BuildBaseEvent(ns),
+					Comm:    "sh",
+					Args:    shArgs,
+					ExePath: "/bin/sh",
+					Cwd:     "/",
 				},
 				{
-					Event: BuildBaseEvent(ns),
-					Comm:  "date",
-					Args:  dateArgs,
+					Event:   BuildBaseEvent(ns),
+					Comm:    "date",
+					Args:    dateArgs,
+					ExePath: "/bin/date",
+					Cwd:     "/",
 				},
 				{
-					Event: BuildBaseEvent(ns),
-					Comm:  "sleep",
-					Args:  sleepArgs,
+					Event:   BuildBaseEvent(ns),
+					Comm:    "sleep",
+					Args:    sleepArgs,
+					ExePath: "/bin/sleep",
+					Cwd:     "/",
 				},
 			}
 
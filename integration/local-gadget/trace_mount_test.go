@@ -41,6 +41,7 @@ func TestTraceMount(t *testing.T) {
 				Source:    "/mnt",
 				Target:    "/mnt",
 				Flags:     []string{"MS_SILENT"},
+				FlagsRaw:  unix.MS_SILENT,
 			}
 
 			normalize := func(e *mountTypes.Event) {
@@ -34,6 +34,7 @@ func TestTraceOOMKill(t *testing.T) {
 			expectedEntry := &oomkillTypes.Event{
 				Event:      BuildBaseEvent(ns),
 				KilledComm: "tail",
+				Scope:      oomkillTypes.ScopeMemcg,
 			}
 			expectedEntry.Container = "test-pod-container"
 
@@ -49,6 +50,9 @@ func TestTraceOOMKill(t *testing.T) {
 				e.TriggeredPid = 0
 				e.TriggeredComm = ""
 				e.MountNsID = 0
+				e.MemcgID = 0
+				e.MemLimit = 0
+				e.MemUsage = 0
 			}
 
 			return ExpectAllToMatch(output, normalize, expectedEntry)
@@ -36,18 +36,23 @@ func TestTraceSignal(t *testing.T) {
 				Comm:   "sh",
 				Signal: "SIGTERM",
 			}
+			expectedEntry.TargetNamespace = expectedEntry.Namespace
+			expectedEntry.TargetPod = expectedEntry.Pod
+			expectedEntry.TargetContainer = expectedEntry.Container
 
 			normalize := func(e *signalTypes.Event) {
 				// TODO: Handle it once we support getting K8s container name for docker
 				// Issue: https://github.com/inspektor-gadget/inspektor-gadget/issues/737
 				if *containerRuntime == ContainerRuntimeDocker {
 					e.Container = "test-pod"
+					e.TargetContainer = "test-pod"
 				}
 
 				e.Pid = 0
 				e.TargetPid = 0
 				e.Retval = 0
 				e.MountNsID = 0
+				e.TargetMountNsID = 0
 			}
 
 			return ExpectEntriesToMatch(output, normalize, expectedEntry)
@@ -34,9 +34,16 @@ func TestTraceSni(t *testing.T) {
 			expectedEntry := &sniTypes.Event{
 				Event: BuildBaseEvent(ns),
 				Name:  "kubernetes.default.svc.cluster.local",
+				Dport: 443,
 			}
 
 			normalize := func(e *sniTypes.Event) {
+				e.Saddr = ""
+				e.Sport = 0
+				e.Daddr = ""
+				e.ALPN = nil
+				e.Versions = nil
+
 				// TODO: Handle it once we support getting K8s container name for docker
 				// Issue: https://github.com/inspektor-gadget/inspektor-gadget/issues/737
 				if *containerRuntime == ContainerRuntimeDocker {
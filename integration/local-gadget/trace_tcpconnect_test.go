@@ -60,6 +60,7 @@ func TestTraceTcpconnect(t *testing.T) {
 
 				e.Pid = 0
 				e.MountNsID = 0
+				e.Latency = 0
 			}
 
 			return ExpectEntriesToMatch(output, normalize, expectedEntry)
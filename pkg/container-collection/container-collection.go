@@ -23,6 +23,7 @@ package containercollection
 
 import (
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -39,6 +40,10 @@ type ContainerCollection struct {
 	// Values: container   Container
 	containers sync.Map
 
+	// index allows to look up containers without going through all of
+	// them. It's updated together with containers, under index.mu.
+	index containerIndex
+
 	// subs contains a list of subscribers of container events
 	pubsub *GadgetPubSub
 
@@ -62,6 +67,18 @@ type ContainerCollection struct {
 
 	// functions to be called on Close()
 	cleanUpFuncs []func()
+
+	// stateFile is the path where the collection is persisted, if enabled by
+	// WithPersistentState(). stateMu protects the pending write scheduled
+	// with stateTimer and serializes the writes.
+	stateFile    string
+	stateMu      sync.Mutex
+	stateTimer   *time.Timer
+	statePending bool
+
+	// listedRuntimes are the container runtimes whose running containers
+	// were listed during initialization.
+	listedRuntimes map[string]struct{}
 }
 
 // ContainerCollectionOption are options to pass to
@@ -76,6 +93,8 @@ func (cc *ContainerCollection) Initialize(options ...ContainerCollectionOption)
 		panic("Initialize already called")
 	}
 
+	cc.index.init()
+
 	// Call functional options. This might fetch initial containers.
 	for _, o := range options {
 		err := o(cc)
@@ -84,6 +103,13 @@ func (cc *ContainerCollection) Initialize(options ...ContainerCollectionOption)
 		}
 	}
 
+	// Restore the containers saved by a previous instance. This is done
+	// once all functional options have fetched the initial containers so
+	// that they can be reconciled.
+	if cc.stateFile != "" {
+		cc.restoreState()
+	}
+
 	// Consume initial containers that might have been fetched by
 	// functional options. This is done after all functional options have
 	// been called, so that cc.containerEnrichers is fully set up.
@@ -97,12 +123,20 @@ initialContainersLoop:
 			}
 		}
 
+		cc.index.mu.Lock()
+		if old, loaded := cc.containers.Load(container.ID); loaded {
+			cc.index.remove(old.(*Container))
+		}
 		cc.containers.Store(container.ID, container)
+		cc.index.add(container)
+		cc.index.mu.Unlock()
+
 		if cc.pubsub != nil {
 			cc.pubsub.Publish(EventTypeAddContainer, container)
 		}
 	}
 	cc.initialContainers = nil
+	cc.saveState()
 
 	cc.initialized = true
 	return nil
@@ -121,7 +155,12 @@ func (cc *ContainerCollection) GetContainer(id string) *Container {
 
 // RemoveContainer removes a container from the collection.
 func (cc *ContainerCollection) RemoveContainer(id string) {
+	cc.index.mu.Lock()
 	v, loaded := cc.containers.LoadAndDelete(id)
+	if loaded {
+		cc.index.remove(v.(*Container))
+	}
+	cc.index.mu.Unlock()
 	if !loaded {
 		return
 	}
@@ -129,6 +168,7 @@ func (cc *ContainerCollection) RemoveContainer(id string) {
 	if cc.pubsub != nil {
 		cc.pubsub.Publish(EventTypeRemoveContainer, v.(*Container))
 	}
+	cc.saveState()
 }
 
 // AddContainer adds a container to the collection.
@@ -142,45 +182,67 @@ func (cc *ContainerCollection) AddContainer(container *Container) {
 		}
 	}
 
+	cc.index.mu.Lock()
 	_, loaded := cc.containers.LoadOrStore(container.ID, container)
+	if !loaded {
+		cc.index.add(container)
+	}
+	cc.index.mu.Unlock()
 	if loaded {
 		return
 	}
 	if cc.pubsub != nil {
 		cc.pubsub.Publish(EventTypeAddContainer, container)
 	}
+	cc.saveState()
 }
 
 // LookupMntnsByContainer returns the mount namespace inode of the container
 // specified in arguments or zero if not found
 func (cc *ContainerCollection) LookupMntnsByContainer(namespace, pod, container string) (mntns uint64) {
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if namespace == c.Namespace && pod == c.Podname && container == c.Name {
-			mntns = c.Mntns
-			// container found, stop iterating
-			return false
+	for _, c := range lookup(&cc.index, cc.index.byPodname, pod) {
+		if namespace == c.Namespace && container == c.Name {
+			return c.Mntns
 		}
-		return true
-	})
-	return
+	}
+	return 0
 }
 
 // LookupContainerByMntns returns a container by its mount namespace
 // inode id. If not found nil is returned.
+//
+// systemd units usually share the mount namespace of the host, and of other
+// units. The containers and the host pseudo-container are then returned
+// first, then the unit with the lowest ID.
 func (cc *ContainerCollection) LookupContainerByMntns(mntnsid uint64) *Container {
-	var container *Container
-
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if c.Mntns == mntnsid {
-			container = c
-			// container found, stop iterating
-			return false
+	containers := lookup(&cc.index, cc.index.byMntns, mntnsid)
+	for _, c := range containers {
+		if c.Runtime != RuntimeSystemd {
+			return c
 		}
-		return true
-	})
-	return container
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+	return containers[0]
+}
+
+// LookupContainersByNetns returns the containers sharing the network
+// namespace inode id given in argument, or an empty slice if not found.
+func (cc *ContainerCollection) LookupContainersByNetns(netnsid uint64) []*Container {
+	return lookup(&cc.index, cc.index.byNetns, netnsid)
+}
+
+// LookupContainerByPid returns the container whose process id is pid. If not
+// found nil is returned.
+func (cc *ContainerCollection) LookupContainerByPid(pid uint32) *Container {
+	return lookupOne(&cc.index, cc.index.byPid, pid)
+}
+
+// LookupContainerByCgroupID returns the container with the given cgroup v2
+// ID. If not found nil is returned.
+func (cc *ContainerCollection) LookupContainerByCgroupID(cgroupID uint64) *Container {
+	return lookupOne(&cc.index, cc.index.byCgroupID, cgroupID)
 }
 
 // LookupMntnsByPod returns the mount namespace inodes of all containers
@@ -188,29 +250,23 @@ func (cc *ContainerCollection) LookupContainerByMntns(mntnsid uint64) *Container
 // containers or an empty map if not found
 func (cc *ContainerCollection) LookupMntnsByPod(namespace, pod string) map[string]uint64 {
 	ret := make(map[string]uint64)
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if namespace == c.Namespace && pod == c.Podname {
+	for _, c := range lookup(&cc.index, cc.index.byPodname, pod) {
+		if namespace == c.Namespace {
 			ret[c.Name] = c.Mntns
 		}
-		return true
-	})
+	}
 	return ret
 }
 
 // LookupPIDByContainer returns the PID of the container
 // specified in arguments or zero if not found
 func (cc *ContainerCollection) LookupPIDByContainer(namespace, pod, container string) (pid uint32) {
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if namespace == c.Namespace && pod == c.Podname && container == c.Name {
-			pid = c.Pid
-			// container found, stop iterating
-			return false
+	for _, c := range lookup(&cc.index, cc.index.byPodname, pod) {
+		if namespace == c.Namespace && container == c.Name {
+			return c.Pid
 		}
-		return true
-	})
-	return
+	}
+	return 0
 }
 
 // LookupPIDByPod returns the PID of all containers belonging to
@@ -218,34 +274,38 @@ func (cc *ContainerCollection) LookupPIDByContainer(namespace, pod, container st
 // containers or an empty map if not found
 func (cc *ContainerCollection) LookupPIDByPod(namespace, pod string) map[string]uint32 {
 	ret := make(map[string]uint32)
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if namespace == c.Namespace && pod == c.Podname {
+	for _, c := range lookup(&cc.index, cc.index.byPodname, pod) {
+		if namespace == c.Namespace {
 			ret[c.Name] = c.Pid
 		}
-		return true
-	})
+	}
 	return ret
 }
 
+// LookupContainerUIDByMntns returns the user ID, from its OCI config, that the
+// processes of the container identified by the mount namespace are started
+// with. It returns false if the container or its OCI config isn't known, like
+// for the host and systemd pseudo-containers.
+func (cc *ContainerCollection) LookupContainerUIDByMntns(mntns uint64) (uint32, bool) {
+	c := cc.LookupContainerByMntns(mntns)
+	if c == nil || c.OciConfig == nil || c.OciConfig.Process == nil {
+		return 0, false
+	}
+	return c.OciConfig.Process.User.UID, true
+}
+
 // LookupOwnerReferenceByMntns returns a pointer to the owner reference of the
 // container identified by the mount namespace, or nil if not found
 func (cc *ContainerCollection) LookupOwnerReferenceByMntns(mntns uint64) *metav1.OwnerReference {
-	var ownerRef *metav1.OwnerReference
-	var err error
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if mntns == c.Mntns {
-			ownerRef, err = c.GetOwnerReference()
-			if err != nil {
-				log.Warnf("Failed to get owner reference of %s/%s/%s: %s",
-					c.Namespace, c.Podname, c.Name, err)
-			}
-			// container found, stop iterating
-			return false
-		}
-		return true
-	})
+	c := cc.LookupContainerByMntns(mntns)
+	if c == nil {
+		return nil
+	}
+	ownerRef, err := c.GetOwnerReference()
+	if err != nil {
+		log.Warnf("Failed to get owner reference of %s/%s/%s: %s",
+			c.Namespace, c.Podname, c.Name, err)
+	}
 	return ownerRef
 }
 
@@ -254,15 +314,7 @@ func (cc *ContainerCollection) LookupOwnerReferenceByMntns(mntns uint64) *metav1
 func (cc *ContainerCollection) GetContainersBySelector(
 	containerSelector *ContainerSelector,
 ) []*Container {
-	selectedContainers := []*Container{}
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if ContainerSelectorMatches(containerSelector, c) {
-			selectedContainers = append(selectedContainers, c)
-		}
-		return true
-	})
-	return selectedContainers
+	return cc.index.selectContainers(containerSelector)
 }
 
 // ContainerLen returns how many containers are stored in the collection.
@@ -291,13 +343,9 @@ func (cc *ContainerCollection) ContainerRangeWithSelector(
 	containerSelector *ContainerSelector,
 	f func(*Container),
 ) {
-	cc.containers.Range(func(key, value interface{}) bool {
-		c := value.(*Container)
-		if ContainerSelectorMatches(containerSelector, c) {
-			f(c)
-		}
-		return true
-	})
+	for _, c := range cc.index.selectContainers(containerSelector) {
+		f(c)
+	}
 }
 
 func (cc *ContainerCollection) Enrich(event *eventtypes.CommonData, mountnsid uint64) {
@@ -311,25 +359,90 @@ func (cc *ContainerCollection) Enrich(event *eventtypes.CommonData, mountnsid ui
 	}
 }
 
+// EnrichByNetNs is like Enrich but it looks up the containers by their network
+// namespace. It's meant for events that don't happen in the context of a
+// process, like packet drops. The container name is only set when a single
+// container uses the network namespace. Events in the host network namespace,
+// i.e. when any of the containers uses the host network, or in a network
+// namespace shared by several pods are only attributed to the node.
+func (cc *ContainerCollection) EnrichByNetNs(event *eventtypes.CommonData, netnsid uint64) {
+	event.Node = cc.nodeName
+
+	containers := cc.LookupContainersByNetns(netnsid)
+	if len(containers) == 0 {
+		return
+	}
+	for _, c := range containers {
+		if c.HostNetwork || c.Namespace != containers[0].Namespace || c.Podname != containers[0].Podname {
+			return
+		}
+	}
+
+	event.Pod = containers[0].Podname
+	event.Namespace = containers[0].Namespace
+	if len(containers) == 1 {
+		event.Container = containers[0].Name
+	}
+}
+
+// EnrichByCgroupID is like Enrich but it looks up the container by its cgroup
+// v2 ID. It's meant for events attributed to a cgroup rather than to a
+// process, like the OOMs of memory cgroups. It returns false and only sets
+// the node if no container has this cgroup, the caller can then use Enrich
+// with the mount namespace of a process instead. The containers' cgroup IDs
+// are the ones of the unified hierarchy, so it's only useful on cgroup v2
+// hosts.
+func (cc *ContainerCollection) EnrichByCgroupID(event *eventtypes.CommonData, cgroupid uint64) bool {
+	event.Node = cc.nodeName
+
+	container := cc.LookupContainerByCgroupID(cgroupid)
+	if container == nil {
+		return false
+	}
+
+	event.Container = container.Name
+	event.Pod = container.Podname
+	event.Namespace = container.Namespace
+
+	return true
+}
+
 // Subscribe returns the list of existing containers and registers a callback
 // for notifications about additions and deletions of containers
 func (cc *ContainerCollection) Subscribe(key interface{}, selector ContainerSelector, f FuncNotify) []*Container {
+	return cc.subscribe(key, selector, f, 0)
+}
+
+// SubscribeAsync is like Subscribe() but the notifications are delivered
+// asynchronously, in order, through a queue of queueSize events. Containers
+// are only delayed while the queue is full. It's meant for subscribers that
+// don't need to act before the container starts.
+func (cc *ContainerCollection) SubscribeAsync(key interface{}, selector ContainerSelector, f FuncNotify, queueSize int) []*Container {
+	return cc.subscribe(key, selector, f, queueSize)
+}
+
+func (cc *ContainerCollection) subscribe(key interface{}, selector ContainerSelector, f FuncNotify, queueSize int) []*Container {
 	if cc.pubsub == nil {
 		panic("ContainerCollection's pubsub uninitialized")
 	}
 	ret := []*Container{}
-	cc.pubsub.Subscribe(key, func(event PubSubEvent) {
+	callback := func(event PubSubEvent) {
 		if ContainerSelectorMatches(&selector, event.Container) {
 			f(event)
 		}
-	}, func() {
-		// Fetch the list of containers inside pubsub.Subscribe() to
-		// guarantee that no new container event will be published at
-		// the same time.
+	}
+	// Fetch the list of containers inside pubsub.Subscribe() to guarantee
+	// that no new container event will be published at the same time.
+	initializer := func() {
 		cc.ContainerRangeWithSelector(&selector, func(c *Container) {
 			ret = append(ret, c)
 		})
-	})
+	}
+	if queueSize > 0 {
+		cc.pubsub.SubscribeAsync(key, callback, initializer, queueSize)
+	} else {
+		cc.pubsub.Subscribe(key, callback, initializer)
+	}
 	return ret
 }
 
@@ -341,6 +454,15 @@ func (cc *ContainerCollection) Unsubscribe(key interface{}) {
 	cc.pubsub.Unsubscribe(key)
 }
 
+// PubSubStats returns the delivery statistics of the subscribers, or nil if
+// pubsub is not enabled.
+func (cc *ContainerCollection) PubSubStats() []SubscriberStats {
+	if cc.pubsub == nil {
+		return nil
+	}
+	return cc.pubsub.Stats()
+}
+
 func (cc *ContainerCollection) Close() {
 	if !cc.initialized || cc.closed {
 		panic("ContainerCollection is not initialized or has been closed")
@@ -350,6 +472,12 @@ func (cc *ContainerCollection) Close() {
 		f()
 	}
 
+	cc.flushState()
+
+	if cc.pubsub != nil {
+		cc.pubsub.Close()
+	}
+
 	// TODO: it's not clear if we want/can allow to re-initialize
 	// this instance yet, so we don't set cc.initialized = false.
 	cc.closed = true
@@ -24,6 +24,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/lato333/inspektor-gadget/pkg/columns"
+	"github.com/lato333/inspektor-gadget/pkg/container-utils/cgroups"
 )
 
 // Container represents a container with its metadata.
@@ -57,6 +58,10 @@ type Container struct {
 	CgroupV1 string `json:"cgroupV1,omitempty"`
 	CgroupV2 string `json:"cgroupV2,omitempty"`
 
+	// CgroupLimits are the resource limits of the container's cgroup. Only
+	// set by WithCgroupLimitsEnrichment().
+	CgroupLimits *cgroups.Limits `json:"cgroupLimits,omitempty"`
+
 	// Kubernetes metadata
 	Namespace string            `json:"namespace,omitempty"`
 	Podname   string            `json:"podname,omitempty"`
@@ -167,3 +172,15 @@ func GetColumns() *columns.Columns[Container] {
 func (c *Container) IsEnriched() bool {
 	return c.Name != "" && c.Podname != "" && c.Namespace != "" && c.PodUID != "" && c.Runtime != ""
 }
+
+// CgroupUsage reads the current resource usage of the container's cgroup.
+func (c *Container) CgroupUsage() (*cgroups.Usage, error) {
+	if c.Pid == 0 {
+		return nil, fmt.Errorf("container %s has no pid", c.ID)
+	}
+	cg, err := cgroups.NewCgroup(int(c.Pid))
+	if err != nil {
+		return nil, err
+	}
+	return cg.Usage()
+}
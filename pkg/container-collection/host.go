@@ -0,0 +1,178 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
+)
+
+const (
+	// HostContainerName is the name of the pseudo-container representing the
+	// processes running in the host mount namespace.
+	HostContainerName = "host"
+
+	// RuntimeHost and RuntimeSystemd are the values of the Runtime field of
+	// the pseudo-containers created by WithHostContainer() and
+	// WithSystemdUnitContainers().
+	RuntimeHost    = "host"
+	RuntimeSystemd = "systemd"
+
+	systemdUnitIDPrefix = "systemd/"
+)
+
+// systemdSliceDirs are the directories where systemd creates the cgroups of
+// the system services, depending on the cgroup layout used by the host.
+var systemdSliceDirs = []string{
+	"/sys/fs/cgroup/system.slice",
+	"/sys/fs/cgroup/unified/system.slice",
+	"/sys/fs/cgroup/systemd/system.slice",
+}
+
+// IsHostContainer tells if the container is a pseudo-container representing
+// processes that don't run in any container: the host itself or a systemd
+// unit.
+func (c *Container) IsHostContainer() bool {
+	return c.Runtime == RuntimeHost || c.Runtime == RuntimeSystemd
+}
+
+// systemdUnitFromCgroupPath returns the name of the systemd service owning the
+// cgroup path, or an empty string if the path doesn't belong to a service.
+// Example: "/system.slice/kubelet.service" -> "kubelet.service"
+func systemdUnitFromCgroupPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if strings.HasSuffix(parts[i], ".service") {
+			return parts[i]
+		}
+	}
+	return ""
+}
+
+// firstPidInCgroup returns the first process listed in the cgroup.procs file
+// of the cgroup directory or zero if the cgroup is empty.
+func firstPidInCgroup(dir string) (uint32, error) {
+	f, err := os.Open(filepath.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		pid, err := strconv.ParseUint(strings.TrimSpace(scanner.Text()), 10, 32)
+		if err != nil {
+			continue
+		}
+		return uint32(pid), nil
+	}
+	return 0, scanner.Err()
+}
+
+// getSystemdUnits returns the pseudo-containers of the running systemd
+// services, with their mount namespace. If units is not empty, only those
+// units are returned.
+func getSystemdUnits(units []string) ([]*Container, error) {
+	wanted := make(map[string]struct{}, len(units))
+	for _, u := range units {
+		wanted[u] = struct{}{}
+	}
+
+	var sliceDir string
+	for _, dir := range systemdSliceDirs {
+		if _, err := os.Stat(dir); err == nil {
+			sliceDir = dir
+			break
+		}
+	}
+	if sliceDir == "" {
+		return nil, fmt.Errorf("cannot find the systemd system.slice cgroup")
+	}
+
+	containers := []*Container{}
+	err := filepath.WalkDir(sliceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || !strings.HasSuffix(d.Name(), ".service") {
+			return nil
+		}
+
+		unit := systemdUnitFromCgroupPath(path)
+		if _, ok := wanted[unit]; len(wanted) > 0 && !ok {
+			return fs.SkipDir
+		}
+
+		// The processes of a service can be in sub-cgroups of the service,
+		// so look for the first process in the whole sub-tree.
+		var pid uint32
+		filepath.WalkDir(path, func(subpath string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() || pid != 0 {
+				return nil
+			}
+			pid, _ = firstPidInCgroup(subpath)
+			return nil
+		})
+		if pid == 0 {
+			// Inactive unit
+			return fs.SkipDir
+		}
+
+		mntns, err := containerutils.GetMntNs(int(pid))
+		if err != nil {
+			// The process exited meanwhile
+			return fs.SkipDir
+		}
+
+		containers = append(containers, &Container{
+			ID:      systemdUnitIDPrefix + unit,
+			Runtime: RuntimeSystemd,
+			Name:    unit,
+			Pid:     pid,
+			Mntns:   mntns,
+		})
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return containers, nil
+}
+
+// splitSystemdUnits separates the systemd units that have their own mount
+// namespace from the ones that share it with the host or with another unit.
+// Gadgets filter events by mount namespace, so the latter can't be traced
+// without tracing other processes too.
+func splitSystemdUnits(containers []*Container, hostMntns uint64) (own, shared []*Container) {
+	units := make(map[uint64][]*Container, len(containers))
+	for _, c := range containers {
+		units[c.Mntns] = append(units[c.Mntns], c)
+	}
+
+	for _, c := range containers {
+		if c.Mntns == hostMntns || len(units[c.Mntns]) > 1 {
+			shared = append(shared, c)
+			continue
+		}
+		own = append(own, c)
+	}
+
+	return own, shared
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestSystemdUnitFromCgroupPath(t *testing.T) {
+	table := []struct {
+		path     string
+		expected string
+	}{
+		{
+			path:     "/system.slice/kubelet.service",
+			expected: "kubelet.service",
+		},
+		{
+			path:     "/sys/fs/cgroup/systemd/system.slice/containerd.service",
+			expected: "containerd.service",
+		},
+		{
+			path:     "/system.slice/system-getty.slice/getty@tty1.service",
+			expected: "getty@tty1.service",
+		},
+		{
+			path:     "/system.slice/docker.service/subgroup",
+			expected: "docker.service",
+		},
+		{
+			path:     "/user.slice/user-1000.slice/session-2.scope",
+			expected: "",
+		},
+		{
+			path:     "",
+			expected: "",
+		},
+	}
+
+	for i, entry := range table {
+		if unit := systemdUnitFromCgroupPath(entry.path); unit != entry.expected {
+			t.Fatalf("Failed test %d: path %q: got %q, expected %q",
+				i, entry.path, unit, entry.expected)
+		}
+	}
+}
+
+func TestSplitSystemdUnits(t *testing.T) {
+	const hostMntns = 4026531841
+
+	containers := []*Container{
+		{Name: "kubelet.service", Mntns: hostMntns},
+		{Name: "chronyd.service", Mntns: 4026532200},
+		{Name: "a.service", Mntns: 4026532300},
+		{Name: "b.service", Mntns: 4026532300},
+	}
+
+	own, shared := splitSystemdUnits(containers, hostMntns)
+
+	names := func(containers []*Container) []string {
+		ret := []string{}
+		for _, c := range containers {
+			ret = append(ret, c.Name)
+		}
+		return ret
+	}
+	if actual := names(own); !reflect.DeepEqual(actual, []string{"chronyd.service"}) {
+		t.Fatalf("Expected only chronyd.service to have its own mount namespace, got %v", actual)
+	}
+	expected := []string{"kubelet.service", "a.service", "b.service"}
+	if actual := names(shared); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected %v to share their mount namespace, got %v", expected, actual)
+	}
+}
+
+func TestLookupContainerByMntnsSharedWithUnits(t *testing.T) {
+	cc := &ContainerCollection{}
+	if err := cc.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize container collection: %s", err)
+	}
+
+	cc.AddContainer(&Container{ID: "systemd/c.service", Runtime: RuntimeSystemd, Name: "c.service", Mntns: 42})
+	cc.AddContainer(&Container{ID: "systemd/a.service", Runtime: RuntimeSystemd, Name: "a.service", Mntns: 42})
+	cc.AddContainer(&Container{ID: "systemd/b.service", Runtime: RuntimeSystemd, Name: "b.service", Mntns: 42})
+	cc.AddContainer(&Container{ID: "systemd/d.service", Runtime: RuntimeSystemd, Name: "d.service", Mntns: 43})
+	cc.AddContainer(&Container{ID: "systemd/e.service", Runtime: RuntimeSystemd, Name: "e.service", Mntns: 43})
+
+	// Without the host pseudo-container, the unit with the lowest ID is
+	// returned every time.
+	for i := 0; i < 10; i++ {
+		if c := cc.LookupContainerByMntns(42); c == nil || c.Name != "a.service" {
+			t.Fatalf("Expected a.service for mntns 42, got %+v", c)
+		}
+	}
+
+	cc.AddContainer(&Container{ID: HostContainerName, Runtime: RuntimeHost, Name: HostContainerName, Mntns: 42})
+
+	for i := 0; i < 10; i++ {
+		if c := cc.LookupContainerByMntns(42); c == nil || c.Name != HostContainerName {
+			t.Fatalf("Expected the host pseudo-container for mntns 42, got %+v", c)
+		}
+		if c := cc.LookupContainerByMntns(43); c == nil || c.Name != "d.service" {
+			t.Fatalf("Expected d.service for mntns 43, got %+v", c)
+		}
+	}
+}
+
+func TestLookupContainerUIDByMntns(t *testing.T) {
+	cc := &ContainerCollection{}
+	if err := cc.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize container collection: %s", err)
+	}
+
+	cc.AddContainer(&Container{ID: HostContainerName, Runtime: RuntimeHost, Name: HostContainerName, Mntns: 41})
+	cc.AddContainer(&Container{ID: "root", Name: "root", Mntns: 42, OciConfig: &ocispec.Spec{Process: &ocispec.Process{}}})
+	cc.AddContainer(&Container{ID: "nonroot", Name: "nonroot", Mntns: 43, OciConfig: &ocispec.Spec{Process: &ocispec.Process{User: ocispec.User{UID: 1000}}}})
+	cc.AddContainer(&Container{ID: "noconfig", Name: "noconfig", Mntns: 44})
+
+	for _, entry := range []struct {
+		description string
+		mntns       uint64
+		uid         uint32
+		known       bool
+	}{
+		{"host", 41, 0, false},
+		{"root", 42, 0, true},
+		{"non-root", 43, 1000, true},
+		{"without OCI config", 44, 0, false},
+		{"unknown", 45, 0, false},
+	} {
+		uid, known := cc.LookupContainerUIDByMntns(entry.mntns)
+		if uid != entry.uid || known != entry.known {
+			t.Fatalf("Failed test %q: got (%d, %t), expected (%d, %t)",
+				entry.description, uid, known, entry.uid, entry.known)
+		}
+	}
+}
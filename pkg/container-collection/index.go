@@ -0,0 +1,189 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// containerSet is a set of containers indexed by container ID.
+type containerSet map[string]*Container
+
+func indexAdd[K comparable](index map[K]containerSet, key K, c *Container) {
+	set, ok := index[key]
+	if !ok {
+		set = make(containerSet)
+		index[key] = set
+	}
+	set[c.ID] = c
+}
+
+func indexRemove[K comparable](index map[K]containerSet, key K, c *Container) {
+	set, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(set, c.ID)
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+// containerIndex keeps the containers of the collection indexed by the
+// fields used in lookups and container selectors, so that those don't need
+// to go through all the containers. Containers must not be modified once
+// they are added to the index.
+type containerIndex struct {
+	mu sync.RWMutex
+
+	all         containerSet
+	byMntns     map[uint64]containerSet
+	byNetns     map[uint64]containerSet
+	byPid       map[uint32]containerSet
+	byCgroupID  map[uint64]containerSet
+	byNamespace map[string]containerSet
+	byPodname   map[string]containerSet
+	byName      map[string]containerSet
+	byLabelKey  map[string]containerSet
+}
+
+// init allocates the maps of the index, the zero value can be used for reads.
+func (idx *containerIndex) init() {
+	idx.all = make(containerSet)
+	idx.byMntns = make(map[uint64]containerSet)
+	idx.byNetns = make(map[uint64]containerSet)
+	idx.byPid = make(map[uint32]containerSet)
+	idx.byCgroupID = make(map[uint64]containerSet)
+	idx.byNamespace = make(map[string]containerSet)
+	idx.byPodname = make(map[string]containerSet)
+	idx.byName = make(map[string]containerSet)
+	idx.byLabelKey = make(map[string]containerSet)
+}
+
+// add adds the container to the index. Callers must hold mu.
+func (idx *containerIndex) add(c *Container) {
+	if idx.all == nil {
+		idx.init()
+	}
+	idx.all[c.ID] = c
+	indexAdd(idx.byMntns, c.Mntns, c)
+	indexAdd(idx.byNetns, c.Netns, c)
+	indexAdd(idx.byPid, c.Pid, c)
+	indexAdd(idx.byCgroupID, c.CgroupID, c)
+	indexAdd(idx.byNamespace, c.Namespace, c)
+	indexAdd(idx.byPodname, c.Podname, c)
+	indexAdd(idx.byName, c.Name, c)
+	for k := range c.Labels {
+		indexAdd(idx.byLabelKey, k, c)
+	}
+}
+
+// remove removes the container from the index. Callers must hold mu.
+func (idx *containerIndex) remove(c *Container) {
+	delete(idx.all, c.ID)
+	indexRemove(idx.byMntns, c.Mntns, c)
+	indexRemove(idx.byNetns, c.Netns, c)
+	indexRemove(idx.byPid, c.Pid, c)
+	indexRemove(idx.byCgroupID, c.CgroupID, c)
+	indexRemove(idx.byNamespace, c.Namespace, c)
+	indexRemove(idx.byPodname, c.Podname, c)
+	indexRemove(idx.byName, c.Name, c)
+	for k := range c.Labels {
+		indexRemove(idx.byLabelKey, k, c)
+	}
+}
+
+// candidates returns the smallest indexed set of containers that contains all
+// the containers matching the selector. Callers must hold mu and still need
+// to check the selector on each candidate.
+func (idx *containerIndex) candidates(s *ContainerSelector) []containerSet {
+	switch {
+	case s.Podname != "":
+		return []containerSet{idx.byPodname[s.Podname]}
+	case s.Name != "":
+		return []containerSet{idx.byName[s.Name]}
+	case s.Namespace != "":
+		sets := []containerSet{}
+		seen := make(map[string]struct{})
+		for _, ns := range strings.Split(s.Namespace, ",") {
+			if _, ok := seen[ns]; ok {
+				continue
+			}
+			seen[ns] = struct{}{}
+			sets = append(sets, idx.byNamespace[ns])
+		}
+		return sets
+	case len(s.Labels) > 0:
+		var smallest containerSet
+		first := true
+		for k := range s.Labels {
+			set := idx.byLabelKey[k]
+			if first || len(set) < len(smallest) {
+				smallest = set
+				first = false
+			}
+		}
+		return []containerSet{smallest}
+	}
+	return []containerSet{idx.all}
+}
+
+// selectContainers returns the containers matching the selector.
+func (idx *containerIndex) selectContainers(s *ContainerSelector) []*Container {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	ret := []*Container{}
+	for _, set := range idx.candidates(s) {
+		for _, c := range set {
+			if ContainerSelectorMatches(s, c) {
+				ret = append(ret, c)
+			}
+		}
+	}
+	return ret
+}
+
+// lookup returns the containers whose key in the given index is key, sorted
+// by ID.
+func lookup[K comparable](idx *containerIndex, index map[K]containerSet, key K) []*Container {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	set := index[key]
+	ret := make([]*Container, 0, len(set))
+	for _, c := range set {
+		ret = append(ret, c)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret
+}
+
+// lookupOne is like lookup but returns only the first of the containers, or
+// nil.
+func lookupOne[K comparable](idx *containerIndex, index map[K]containerSet, key K) *Container {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var ret *Container
+	for _, c := range index[key] {
+		if ret == nil || c.ID < ret.ID {
+			ret = c
+		}
+	}
+	return ret
+}
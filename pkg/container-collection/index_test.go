@@ -0,0 +1,278 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+func newTestContainer(i int) *Container {
+	return &Container{
+		ID:        fmt.Sprintf("container%d", i),
+		Pid:       uint32(1000 + i),
+		Mntns:     uint64(4026531840 + i),
+		Netns:     uint64(4026532000 + i/2),
+		CgroupID:  uint64(10000 + i),
+		Namespace: fmt.Sprintf("namespace%d", i%10),
+		Podname:   fmt.Sprintf("pod%d", i/2),
+		Name:      fmt.Sprintf("name%d", i%2),
+		Labels: map[string]string{
+			"app":                   fmt.Sprintf("app%d", i%20),
+			fmt.Sprintf("key%d", i): "value",
+		},
+	}
+}
+
+func newTestCollection(t testing.TB, count int) *ContainerCollection {
+	cc := &ContainerCollection{}
+	if err := cc.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize container collection: %s", err)
+	}
+	for i := 0; i < count; i++ {
+		cc.AddContainer(newTestContainer(i))
+	}
+	return cc
+}
+
+func TestIndexedLookups(t *testing.T) {
+	cc := newTestCollection(t, 100)
+
+	if c := cc.LookupContainerByMntns(4026531840 + 42); c == nil || c.ID != "container42" {
+		t.Fatalf("LookupContainerByMntns: got %+v, expected container42", c)
+	}
+	if c := cc.LookupContainerByPid(1042); c == nil || c.ID != "container42" {
+		t.Fatalf("LookupContainerByPid: got %+v, expected container42", c)
+	}
+	if c := cc.LookupContainerByCgroupID(10042); c == nil || c.ID != "container42" {
+		t.Fatalf("LookupContainerByCgroupID: got %+v, expected container42", c)
+	}
+	if cs := cc.LookupContainersByNetns(4026532000 + 21); len(cs) != 2 {
+		t.Fatalf("LookupContainersByNetns: got %d containers, expected 2", len(cs))
+	}
+	if mntns := cc.LookupMntnsByContainer("namespace2", "pod21", "name0"); mntns != 4026531840+42 {
+		t.Fatalf("LookupMntnsByContainer: got %d", mntns)
+	}
+	if pids := cc.LookupPIDByPod("namespace2", "pod21"); len(pids) != 1 || pids["name0"] != 1042 {
+		t.Fatalf("LookupPIDByPod: got %v", pids)
+	}
+
+	table := []struct {
+		description string
+		selector    ContainerSelector
+		expected    int
+	}{
+		{
+			description: "all containers",
+			selector:    ContainerSelector{},
+			expected:    100,
+		},
+		{
+			description: "pod name",
+			selector:    ContainerSelector{Podname: "pod21"},
+			expected:    2,
+		},
+		{
+			description: "namespace and name",
+			selector:    ContainerSelector{Namespace: "namespace2", Name: "name0"},
+			expected:    10,
+		},
+		{
+			description: "several namespaces",
+			selector:    ContainerSelector{Namespace: "namespace2,namespace3,namespace2"},
+			expected:    20,
+		},
+		{
+			description: "labels",
+			selector:    ContainerSelector{Labels: map[string]string{"app": "app2", "key42": "value"}},
+			expected:    1,
+		},
+		{
+			description: "unknown label",
+			selector:    ContainerSelector{Labels: map[string]string{"unknown": "value"}},
+			expected:    0,
+		},
+	}
+
+	for _, entry := range table {
+		if n := len(cc.GetContainersBySelector(&entry.selector)); n != entry.expected {
+			t.Fatalf("Failed test %q: got %d containers, expected %d",
+				entry.description, n, entry.expected)
+		}
+	}
+
+	cc.RemoveContainer("container42")
+	if c := cc.LookupContainerByMntns(4026531840 + 42); c != nil {
+		t.Fatalf("LookupContainerByMntns: container42 still found after removal")
+	}
+	if cs := cc.GetContainersBySelector(&ContainerSelector{Podname: "pod21"}); len(cs) != 1 {
+		t.Fatalf("GetContainersBySelector: got %d containers after removal, expected 1", len(cs))
+	}
+}
+
+func TestEnrichByNetNs(t *testing.T) {
+	cc := newTestCollection(t, 0)
+	cc.nodeName = "node1"
+
+	containers := []*Container{
+		{ID: "a", Namespace: "ns1", Podname: "pod1", Name: "c1", Netns: 1001},
+		{ID: "b", Namespace: "ns1", Podname: "pod1", Name: "c2", Netns: 1001},
+		{ID: "c", Namespace: "ns1", Podname: "pod2", Name: "c1", Netns: 1002},
+		{ID: "d", Namespace: "ns2", Podname: "pod3", Name: "c1", Netns: 1000, HostNetwork: true},
+		// The host network namespace, whatever the container found first
+		{ID: "e", Namespace: "ns2", Podname: "pod4", Name: "c1", Netns: 1000},
+		{ID: "f", Namespace: "ns2", Podname: "pod5", Name: "c1", Netns: 1000},
+	}
+	for _, c := range containers {
+		cc.AddContainer(c)
+	}
+
+	table := []struct {
+		description string
+		netns       uint64
+		expected    eventtypes.CommonData
+	}{
+		{
+			description: "pod with two containers",
+			netns:       1001,
+			expected:    eventtypes.CommonData{Node: "node1", Namespace: "ns1", Pod: "pod1"},
+		},
+		{
+			description: "pod with a single container",
+			netns:       1002,
+			expected:    eventtypes.CommonData{Node: "node1", Namespace: "ns1", Pod: "pod2", Container: "c1"},
+		},
+		{
+			description: "host network",
+			netns:       1000,
+			expected:    eventtypes.CommonData{Node: "node1"},
+		},
+		{
+			description: "unknown network namespace",
+			netns:       1,
+			expected:    eventtypes.CommonData{Node: "node1"},
+		},
+	}
+
+	for _, entry := range table {
+		var data eventtypes.CommonData
+		cc.EnrichByNetNs(&data, entry.netns)
+		if data != entry.expected {
+			t.Fatalf("Failed test %q: got %+v, expected %+v",
+				entry.description, data, entry.expected)
+		}
+	}
+}
+
+func TestEnrichByCgroupID(t *testing.T) {
+	cc := newTestCollection(t, 10)
+	cc.nodeName = "node1"
+
+	var data eventtypes.CommonData
+	if !cc.EnrichByCgroupID(&data, 10003) {
+		t.Fatalf("EnrichByCgroupID: container not found")
+	}
+	expected := eventtypes.CommonData{Node: "node1", Namespace: "namespace3", Pod: "pod1", Container: "name1"}
+	if data != expected {
+		t.Fatalf("EnrichByCgroupID: got %+v, expected %+v", data, expected)
+	}
+
+	data = eventtypes.CommonData{}
+	if cc.EnrichByCgroupID(&data, 1) {
+		t.Fatalf("EnrichByCgroupID: unexpected container found for unknown cgroup")
+	}
+	expected = eventtypes.CommonData{Node: "node1"}
+	if data != expected {
+		t.Fatalf("EnrichByCgroupID: got %+v, expected %+v", data, expected)
+	}
+}
+
+func TestIndexConcurrentAddRemove(t *testing.T) {
+	cc := newTestCollection(t, 0)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < 400; i += 8 {
+				cc.AddContainer(newTestContainer(i))
+				cc.LookupContainerByMntns(uint64(4026531840 + i))
+				if i%2 == 0 {
+					cc.RemoveContainer(fmt.Sprintf("container%d", i))
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if n := cc.ContainerLen(); n != 200 {
+		t.Fatalf("Got %d containers, expected 200", n)
+	}
+	if n := len(cc.GetContainersBySelector(&ContainerSelector{})); n != 200 {
+		t.Fatalf("Index has %d containers, expected 200", n)
+	}
+	for i := 0; i < 400; i++ {
+		c := cc.LookupContainerByPid(uint32(1000 + i))
+		if (c != nil) != (i%2 == 1) {
+			t.Fatalf("Index inconsistent for container%d: %+v", i, c)
+		}
+	}
+}
+
+func BenchmarkLookupContainerByMntns(b *testing.B) {
+	for _, count := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("containers=%d", count), func(b *testing.B) {
+			cc := newTestCollection(b, count)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cc.LookupContainerByMntns(uint64(4026531840 + i%count))
+			}
+		})
+	}
+}
+
+func BenchmarkGetContainersBySelector(b *testing.B) {
+	selectors := map[string]ContainerSelector{
+		"podname":   {Namespace: "namespace2", Podname: "pod21"},
+		"namespace": {Namespace: "namespace2"},
+		"labels":    {Labels: map[string]string{"app": "app2"}},
+	}
+	for _, count := range []int{100, 1000} {
+		for name, selector := range selectors {
+			selector := selector
+			b.Run(fmt.Sprintf("containers=%d/%s", count, name), func(b *testing.B) {
+				cc := newTestCollection(b, count)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					cc.GetContainersBySelector(&selector)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkAddRemoveContainer(b *testing.B) {
+	cc := newTestCollection(b, 1000)
+	c := newTestContainer(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cc.AddContainer(c)
+		cc.RemoveContainer(c.ID)
+	}
+}
@@ -79,7 +79,7 @@ func containerRuntimeEnricher(
 ) bool {
 	// Is container already enriched? Notice that, at this point, the container
 	// was already enriched with the PID by the hook.
-	if container.IsEnriched() {
+	if container.IsEnriched() || container.IsHostContainer() {
 		return true
 	}
 	containerData, err := runtimeClient.GetContainer(container.ID)
@@ -158,6 +158,11 @@ func WithContainerRuntimeEnrichment(runtime *containerutils.RuntimeConfig) Conta
 
 			return nil
 		}
+		if cc.listedRuntimes == nil {
+			cc.listedRuntimes = make(map[string]struct{})
+		}
+		cc.listedRuntimes[runtime.Name] = struct{}{}
+
 		for _, container := range containers {
 			if container.State != runtimeclient.StateRunning {
 				log.Debugf("Runtime enricher(%s): Skip container %q (ID: %s): not running",
@@ -422,7 +427,7 @@ func WithKubernetesEnrichment(nodeName string, kubeconfig *rest.Config) Containe
 
 		// Future containers
 		cc.containerEnrichers = append(cc.containerEnrichers, func(container *Container) bool {
-			if container.Podname != "" {
+			if container.Podname != "" || container.IsHostContainer() {
 				return true
 			}
 
@@ -565,6 +570,36 @@ func WithCgroupEnrichment() ContainerCollectionOption {
 	}
 }
 
+// WithCgroupLimitsEnrichment enables an enricher to add the memory, cpu and
+// pids limits of the container's cgroup. The current usage can then be read
+// on demand with Container.CgroupUsage().
+func WithCgroupLimitsEnrichment() ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		cc.containerEnrichers = append(cc.containerEnrichers, func(container *Container) bool {
+			pid := int(container.Pid)
+			if pid == 0 {
+				log.Errorf("cgroup limits enricher: failed to enrich container %s with pid zero", container.ID)
+				return true
+			}
+
+			cg, err := cgroups.NewCgroup(pid)
+			if err != nil {
+				log.Errorf("cgroup limits enricher: failed to get cgroup of container %s: %s", container.ID, err)
+				return true
+			}
+			limits, err := cg.Limits()
+			if err != nil {
+				log.Errorf("cgroup limits enricher: failed to get limits of container %s: %s", container.ID, err)
+				return true
+			}
+
+			container.CgroupLimits = limits
+			return true
+		})
+		return nil
+	}
+}
+
 // WithLinuxNamespaceEnrichment enables an enricher to add the namespaces metadata
 func WithLinuxNamespaceEnrichment() ContainerCollectionOption {
 	return func(cc *ContainerCollection) error {
@@ -637,6 +672,67 @@ func WithOCIConfigEnrichment() ContainerCollectionOption {
 	}
 }
 
+// WithHostContainer adds a pseudo-container named "host" representing the
+// processes running in the host mount namespace. It allows gadgets to trace
+// and filter node daemons with the same mechanisms used for containers.
+//
+// ContainerCollection.Initialize(WithHostContainer())
+func WithHostContainer() ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		cc.initialContainers = append(cc.initialContainers, &Container{
+			ID:      HostContainerName,
+			Runtime: RuntimeHost,
+			Name:    HostContainerName,
+			Pid:     1,
+		})
+		return nil
+	}
+}
+
+// WithSystemdUnitContainers adds a pseudo-container for each running systemd
+// service, derived from its cgroup, with Name set to the unit name. If units
+// is not empty, only those units are added. Only the services running at
+// initialization time are taken into account.
+//
+// Notice that gadgets filter events by mount namespace, so a unit that doesn't
+// have its own mount namespace is traced together with the host and the other
+// units sharing it. A warning is logged for such units when they are given.
+//
+// ContainerCollection.Initialize(WithSystemdUnitContainers("kubelet.service"))
+func WithSystemdUnitContainers(units ...string) ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		containers, err := getSystemdUnits(units)
+		if err != nil {
+			return fmt.Errorf("failed to get systemd units: %w", err)
+		}
+
+		if len(units) > 0 {
+			hostMntns, err := containerutils.GetMntNs(1)
+			if err != nil {
+				return fmt.Errorf("failed to get the host mount namespace: %w", err)
+			}
+
+			_, shared := splitSystemdUnits(containers, hostMntns)
+			for _, c := range shared {
+				log.Warnf("systemd unit %q doesn't have its own mount namespace, other processes will be traced too", c.Name)
+			}
+		}
+
+		found := make(map[string]struct{}, len(containers))
+		for _, c := range containers {
+			found[c.Name] = struct{}{}
+		}
+		for _, u := range units {
+			if _, ok := found[u]; !ok {
+				log.Warnf("systemd unit %q not found or not running", u)
+			}
+		}
+
+		cc.initialContainers = append(cc.initialContainers, containers...)
+		return nil
+	}
+}
+
 func WithNodeName(nodeName string) ContainerCollectionOption {
 	return func(cc *ContainerCollection) error {
 		cc.nodeName = nodeName
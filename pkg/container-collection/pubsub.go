@@ -17,6 +17,8 @@ package containercollection
 import (
 	"sync"
 	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 type EventType int
@@ -65,67 +67,321 @@ type PubSubEvent struct {
 	Container *Container `json:"container"`
 }
 
-// GadgetPubSub provides a synchronous publish subscribe mechanism for gadgets
-// to be informed of container creation and deletion. It needs to be
-// synchronous so that gadgets have time to attach their tracer before the
-// container is started.
+// slowSubscriberThreshold is the time after which a subscriber handling an
+// event is reported as slow.
+const slowSubscriberThreshold = time.Second
+
+// SubscriberStats are the delivery statistics of a subscriber.
+type SubscriberStats struct {
+	Key   interface{}
+	Async bool
+
+	// Delivered is the number of events handled by the callback.
+	Delivered uint64
+
+	// Pending is the number of events waiting in the queue of an
+	// asynchronous subscriber.
+	Pending int
+
+	// Blocked is the number of events whose publication had to wait for
+	// the queue of an asynchronous subscriber to have room.
+	Blocked uint64
+
+	// Slow is the number of events that took the callback more than
+	// slowSubscriberThreshold to handle.
+	Slow uint64
+
+	// MaxDuration is the longest time taken by the callback.
+	MaxDuration time.Duration
+}
+
+type subscriber struct {
+	key      interface{}
+	callback FuncNotify
+
+	// The fields below are only used for asynchronous subscribers. queue
+	// holds the pending events, up to queueSize of them before Publish()
+	// waits. cond is signaled when events are added to or taken from queue,
+	// and when the subscriber is closed.
+	async     bool
+	queueMu   sync.Mutex
+	cond      *sync.Cond
+	queue     []PubSubEvent
+	queueSize int
+	closed    bool
+	done      chan struct{}
+
+	// overflowing is set while the queue is full, so that only the first
+	// wait is reported. It's protected by queueMu.
+	overflowing bool
+
+	statsMu sync.Mutex
+	stats   SubscriberStats
+}
+
+func (s *subscriber) deliver(event PubSubEvent) {
+	start := time.Now()
+	s.callback(event)
+	duration := time.Since(start)
+
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	s.stats.Delivered++
+	if duration > s.stats.MaxDuration {
+		s.stats.MaxDuration = duration
+	}
+	if duration > slowSubscriberThreshold {
+		s.stats.Slow++
+		log.Warnf("pubsub: subscriber %v took %s to handle %s event of container %s",
+			s.key, duration, event.Type.String(), event.Container.ID)
+	}
+}
+
+// enqueue adds the event to the queue of an asynchronous subscriber. It never
+// waits, so that the order of the events is decided while holding
+// GadgetPubSub.queueMu without a slow subscriber delaying the others. The
+// caller then has to call wait() to let the queue drain if it's full.
+func (s *subscriber) enqueue(event PubSubEvent) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.queue = append(s.queue, event)
+	s.cond.Broadcast()
+}
+
+// wait blocks until the queue of an asynchronous subscriber has at most
+// queueSize events, or the subscriber is closed. Lifecycle events are never
+// dropped: a slow subscriber delays the publisher instead.
+func (s *subscriber) wait() {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if len(s.queue) <= s.queueSize || s.closed {
+		s.overflowing = false
+		return
+	}
+
+	s.statsMu.Lock()
+	s.stats.Blocked++
+	s.statsMu.Unlock()
+
+	if !s.overflowing {
+		s.overflowing = true
+		log.Warnf("pubsub: queue of subscriber %v is full (%d events), waiting for it",
+			s.key, s.queueSize)
+	}
+
+	for len(s.queue) > s.queueSize && !s.closed {
+		s.cond.Wait()
+	}
+}
+
+func (s *subscriber) run() {
+	defer close(s.done)
+	for {
+		s.queueMu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.queueMu.Unlock()
+			return
+		}
+		event := s.queue[0]
+		s.queue[0] = PubSubEvent{}
+		s.queue = s.queue[1:]
+		s.cond.Broadcast()
+		s.queueMu.Unlock()
+
+		s.deliver(event)
+	}
+}
+
+// pending returns the number of events in the queue of an asynchronous
+// subscriber.
+func (s *subscriber) pending() int {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	return len(s.queue)
+}
+
+// close stops the goroutine of an asynchronous subscriber and wakes up the
+// publishers waiting for it. Pending events are dropped.
+func (s *subscriber) close() {
+	if !s.async {
+		return
+	}
+	s.queueMu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.queueMu.Unlock()
+	<-s.done
+}
+
+// GadgetPubSub provides a publish subscribe mechanism for gadgets to be
+// informed of container creation and deletion.
+//
+// Subscribers registered with Subscribe() are called synchronously, so that
+// gadgets have time to attach their tracer before the container is started.
+// Subscribers that don't need it can use SubscribeAsync() to not delay the
+// container: events are then delivered in order through a bounded queue, and
+// publishers wait when it's full.
 type GadgetPubSub struct {
 	mu sync.RWMutex
 
 	// subs is the set of subscribers
-	subs map[interface{}]FuncNotify
+	subs map[interface{}]*subscriber
+
+	// queueMu serializes the enqueuing of events so that all asynchronous
+	// subscribers receive them in the same order. It's never held while
+	// waiting for a subscriber.
+	queueMu sync.Mutex
 }
 
 func NewGadgetPubSub() *GadgetPubSub {
 	return &GadgetPubSub{
-		subs: make(map[interface{}]FuncNotify),
+		subs: make(map[interface{}]*subscriber),
 	}
 }
 
-// Subscribe registers the callback to be called for every container event
-// published with Publish(). Optionally, the caller can pass an initializer()
-// function that is guaranteed to be called before any new container events are
-// published.
+// Subscribe registers the callback to be called synchronously for every
+// container event published with Publish(). Optionally, the caller can pass an
+// initializer() function that is guaranteed to be called before any new
+// container events are published.
 func (g *GadgetPubSub) Subscribe(key interface{}, callback FuncNotify, initializer func()) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+	g.subscribe(&subscriber{
+		key:      key,
+		callback: callback,
+	}, initializer)
+}
 
-	g.subs[key] = callback
+// SubscribeAsync is like Subscribe() but the callback is called from a
+// separate goroutine, in the order the events were published. Up to queueSize
+// events can be pending, Publish() then waits for the callback to catch up and
+// these waits are counted in Stats(). The callback must neither publish events
+// nor unsubscribe itself.
+func (g *GadgetPubSub) SubscribeAsync(key interface{}, callback FuncNotify, initializer func(), queueSize int) {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	s := &subscriber{
+		key:       key,
+		callback:  callback,
+		async:     true,
+		queueSize: queueSize,
+		done:      make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.queueMu)
+	s.stats.Async = true
+
+	go s.run()
+	g.subscribe(s, initializer)
+}
+
+func (g *GadgetPubSub) subscribe(s *subscriber, initializer func()) {
+	s.stats.Key = s.key
+
+	g.mu.Lock()
+	old := g.subs[s.key]
+	g.subs[s.key] = s
 
 	if initializer != nil {
 		initializer()
 	}
+	g.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
 }
 
 func (g *GadgetPubSub) Unsubscribe(key interface{}) {
 	g.mu.Lock()
-	defer g.mu.Unlock()
-
+	s := g.subs[key]
 	delete(g.subs, key)
+	g.mu.Unlock()
+
+	if s != nil {
+		s.close()
+	}
+}
+
+// Close unsubscribes all the subscribers.
+func (g *GadgetPubSub) Close() {
+	g.mu.Lock()
+	subs := g.subs
+	g.subs = make(map[interface{}]*subscriber)
+	g.mu.Unlock()
+
+	for _, s := range subs {
+		s.close()
+	}
+}
+
+// Stats returns the delivery statistics of each subscriber.
+func (g *GadgetPubSub) Stats() []SubscriberStats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	ret := make([]SubscriberStats, 0, len(g.subs))
+	for _, s := range g.subs {
+		s.statsMu.Lock()
+		stats := s.stats
+		s.statsMu.Unlock()
+		if s.async {
+			stats.Pending = s.pending()
+		}
+		ret = append(ret, stats)
+	}
+	return ret
 }
 
 func (g *GadgetPubSub) Publish(eventType EventType, container *Container) {
 	// Make a copy so we don't keep the lock while actually publishing
 	g.mu.RLock()
-	copiedSubs := []FuncNotify{}
-	for _, callback := range g.subs {
-		copiedSubs = append(copiedSubs, callback)
+	syncSubs := []*subscriber{}
+	asyncSubs := []*subscriber{}
+	for _, s := range g.subs {
+		if s.async {
+			asyncSubs = append(asyncSubs, s)
+		} else {
+			syncSubs = append(syncSubs, s)
+		}
 	}
 	g.mu.RUnlock()
 
+	event := PubSubEvent{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Type:      eventType,
+		Container: container,
+	}
+
+	if len(asyncSubs) > 0 {
+		g.queueMu.Lock()
+		for _, s := range asyncSubs {
+			s.enqueue(event)
+		}
+		g.queueMu.Unlock()
+	}
+
 	var wg sync.WaitGroup
-	for _, callback := range copiedSubs {
+	for _, s := range syncSubs {
 		wg.Add(1)
-		go func(callback FuncNotify) {
-			event := PubSubEvent{
-				Timestamp: time.Now().Format(time.RFC3339),
-				Type:      eventType,
-				Container: container,
-			}
-			callback(event)
+		go func(s *subscriber) {
+			s.deliver(event)
 			wg.Done()
-		}(callback)
+		}(s)
 	}
 
 	wg.Wait()
+
+	// Wait for the full queues only once the synchronous subscribers are
+	// done, so that they aren't delayed by the asynchronous ones.
+	for _, s := range asyncSubs {
+		s.wait()
+	}
 }
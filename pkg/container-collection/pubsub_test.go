@@ -15,7 +15,10 @@
 package containercollection
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestPubSub(t *testing.T) {
@@ -87,3 +90,228 @@ func TestPubSubVerifyPointerToContainer(t *testing.T) {
 		t.Fatalf("Pointer doesn't correspond to original object")
 	}
 }
+
+func TestPubSubAsyncOrdering(t *testing.T) {
+	p := NewGadgetPubSub()
+	if p == nil {
+		t.Fatalf("Failed to create new pubsub")
+	}
+	defer p.Close()
+
+	const count = 100
+
+	received := map[string][]string{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, key := range []string{"async1", "async2"} {
+		key := key
+		wg.Add(count)
+		p.SubscribeAsync(key, func(e PubSubEvent) {
+			mu.Lock()
+			received[key] = append(received[key], e.Container.ID)
+			mu.Unlock()
+			wg.Done()
+		}, nil, count)
+	}
+
+	for i := 0; i < count; i++ {
+		p.Publish(EventTypeAddContainer, &Container{ID: fmt.Sprintf("container%d", i)})
+	}
+	wg.Wait()
+
+	for key, ids := range received {
+		if len(ids) != count {
+			t.Fatalf("Subscriber %s received %d events, expected %d", key, len(ids), count)
+		}
+		for i, id := range ids {
+			if expected := fmt.Sprintf("container%d", i); id != expected {
+				t.Fatalf("Subscriber %s received %s at position %d, expected %s", key, id, i, expected)
+			}
+		}
+	}
+}
+
+func TestPubSubAsyncDoesNotBlockSync(t *testing.T) {
+	p := NewGadgetPubSub()
+	if p == nil {
+		t.Fatalf("Failed to create new pubsub")
+	}
+	defer p.Close()
+
+	release := make(chan struct{})
+	asyncDone := make(chan struct{}, 1)
+	p.SubscribeAsync("slow", func(e PubSubEvent) {
+		<-release
+		asyncDone <- struct{}{}
+	}, nil, 8)
+
+	syncCalled := false
+	p.Subscribe("sync", func(e PubSubEvent) {
+		syncCalled = true
+	}, nil)
+
+	// The synchronous subscriber has been called when Publish() returns,
+	// even though the asynchronous one is still blocked.
+	p.Publish(EventTypeAddContainer, &Container{ID: "container1"})
+	if !syncCalled {
+		t.Fatalf("Synchronous subscriber not called before Publish() returned")
+	}
+
+	close(release)
+	select {
+	case <-asyncDone:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Asynchronous subscriber not called")
+	}
+
+	for _, stats := range p.Stats() {
+		if stats.Delivered != 1 {
+			t.Fatalf("Subscriber %v delivered %d events, expected 1", stats.Key, stats.Delivered)
+		}
+		if stats.Async != (stats.Key == "slow") {
+			t.Fatalf("Subscriber %v has wrong mode", stats.Key)
+		}
+	}
+}
+
+func TestPubSubAsyncQueueFull(t *testing.T) {
+	p := NewGadgetPubSub()
+	if p == nil {
+		t.Fatalf("Failed to create new pubsub")
+	}
+	defer p.Close()
+
+	const count = 5
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	received := []string{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(count)
+	p.SubscribeAsync("slow", func(e PubSubEvent) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+
+		mu.Lock()
+		received = append(received, e.Container.ID)
+		mu.Unlock()
+		wg.Done()
+	}, nil, 2)
+
+	// The first event is being handled and the next two fill the queue.
+	p.Publish(EventTypeAddContainer, &Container{ID: "container0"})
+	<-started
+	for i := 1; i < 3; i++ {
+		p.Publish(EventTypeAddContainer, &Container{ID: fmt.Sprintf("container%d", i)})
+	}
+
+	// The next ones wait for the subscriber instead of being dropped.
+	published := make(chan struct{})
+	go func() {
+		for i := 3; i < count; i++ {
+			p.Publish(EventTypeAddContainer, &Container{ID: fmt.Sprintf("container%d", i)})
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatalf("Publish() returned while the queue was full")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	stats := p.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("Expected 1 subscriber, got %d", len(stats))
+	}
+	if stats[0].Pending != 3 || stats[0].Blocked != 1 {
+		t.Fatalf("Expected 3 pending events and 1 blocked publication, got %d and %d",
+			stats[0].Pending, stats[0].Blocked)
+	}
+
+	close(release)
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Publish() still blocked after the queue drained")
+	}
+	wg.Wait()
+
+	for i, id := range received {
+		if expected := fmt.Sprintf("container%d", i); id != expected {
+			t.Fatalf("Received %s at position %d, expected %s", id, i, expected)
+		}
+	}
+}
+
+func TestPubSubAsyncUnsubscribeWakesPublisher(t *testing.T) {
+	p := NewGadgetPubSub()
+	if p == nil {
+		t.Fatalf("Failed to create new pubsub")
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	p.SubscribeAsync("stuck", func(e PubSubEvent) {
+		<-release
+	}, nil, 1)
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			p.Publish(EventTypeAddContainer, &Container{ID: fmt.Sprintf("container%d", i)})
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatalf("Publish() returned while the queue was full")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Unsubscribe() waits for the callback to return
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		release <- struct{}{}
+	}()
+	p.Unsubscribe("stuck")
+
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Publish() still blocked after Unsubscribe()")
+	}
+}
+
+func TestPubSubAsyncUnsubscribe(t *testing.T) {
+	p := NewGadgetPubSub()
+	if p == nil {
+		t.Fatalf("Failed to create new pubsub")
+	}
+
+	counter := 0
+	var mu sync.Mutex
+	p.SubscribeAsync("async", func(e PubSubEvent) {
+		mu.Lock()
+		counter++
+		mu.Unlock()
+	}, nil, 8)
+	p.Unsubscribe("async")
+
+	p.Publish(EventTypeAddContainer, &Container{ID: "container1"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if counter != 0 {
+		t.Fatalf("Callback called after Unsubscribe()")
+	}
+	if len(p.Stats()) != 0 {
+		t.Fatalf("Subscriber still present after Unsubscribe()")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
+)
+
+// stateSaveDelay is how long the write of the state file is delayed after a
+// container is added or removed, so that the changes happening together, like
+// the containers of a pod, are written at once.
+const stateSaveDelay = time.Second
+
+// WithPersistentState keeps a snapshot of the collection in the file at path.
+// The snapshot is updated shortly after containers are added or removed and
+// on Close(), and it is reloaded by Initialize(), so that information only
+// available when the container is created, like its OCI config and bundle,
+// survives a restart. Saved containers whose process isn't running anymore,
+// or that their runtime doesn't list anymore, are dropped.
+//
+// ContainerCollection.Initialize(WithPersistentState("/run/containers.json"))
+func WithPersistentState(path string) ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		if path == "" {
+			return errors.New("state file path not set")
+		}
+		cc.stateFile = path
+		return nil
+	}
+}
+
+// containerAlive tells if the process of a saved container is still running in
+// the same mount namespace, i.e. the pid was not reused by another process.
+// Containers saved without their mount namespace can't be checked, so they are
+// considered stale.
+func containerAlive(c *Container) bool {
+	if c.Pid == 0 || c.Mntns == 0 {
+		return false
+	}
+	mntns, err := containerutils.GetMntNs(int(c.Pid))
+	if err != nil {
+		return false
+	}
+	return c.Mntns == mntns
+}
+
+func loadState(path string) ([]*Container, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := []*Container{}
+	if err := json.Unmarshal(buf, &containers); err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", path, err)
+	}
+	return containers, nil
+}
+
+// restoreState reconciles the saved containers with the initial containers
+// gathered by the functional options: live containers complete the data of
+// the initial ones or are added to them, stale ones are dropped. The runtimes
+// that listed their containers are authoritative: their containers missing
+// from the list are stale even if the process is still there.
+func (cc *ContainerCollection) restoreState() {
+	saved, err := loadState(cc.stateFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warnf("failed to restore containers state: %s", err)
+		}
+		return
+	}
+
+	initialContainers := make(map[string]*Container, len(cc.initialContainers))
+	for _, c := range cc.initialContainers {
+		initialContainers[c.ID] = c
+	}
+
+	for _, c := range saved {
+		if !containerAlive(c) {
+			log.Debugf("dropping stale container %s from saved state", c.ID)
+			continue
+		}
+
+		if ic, ok := initialContainers[c.ID]; ok {
+			if ic.OciConfig == nil {
+				ic.OciConfig = c.OciConfig
+			}
+			if ic.Bundle == "" {
+				ic.Bundle = c.Bundle
+			}
+			continue
+		}
+
+		if _, ok := cc.listedRuntimes[c.Runtime]; ok {
+			log.Debugf("dropping container %s not listed by %s from saved state", c.ID, c.Runtime)
+			continue
+		}
+
+		cc.initialContainers = append(cc.initialContainers, c)
+	}
+}
+
+// saveState schedules the write of the current set of containers to the state
+// file. The changes happening until the write are written together.
+func (cc *ContainerCollection) saveState() {
+	if cc.stateFile == "" {
+		return
+	}
+
+	cc.stateMu.Lock()
+	defer cc.stateMu.Unlock()
+
+	cc.statePending = true
+	if cc.stateTimer == nil {
+		cc.stateTimer = time.AfterFunc(stateSaveDelay, func() {
+			cc.stateMu.Lock()
+			defer cc.stateMu.Unlock()
+
+			cc.stateTimer = nil
+			cc.writeState()
+		})
+	}
+}
+
+// flushState writes the state file now if a write is pending.
+func (cc *ContainerCollection) flushState() {
+	if cc.stateFile == "" {
+		return
+	}
+
+	cc.stateMu.Lock()
+	defer cc.stateMu.Unlock()
+
+	if cc.stateTimer != nil {
+		cc.stateTimer.Stop()
+		cc.stateTimer = nil
+	}
+	cc.writeState()
+}
+
+// writeState writes the current set of containers to the state file if a
+// write is pending. The file is replaced atomically so that a crash never
+// leaves a truncated snapshot. It must be called with stateMu held.
+func (cc *ContainerCollection) writeState() {
+	if !cc.statePending {
+		return
+	}
+	cc.statePending = false
+
+	containers := []*Container{}
+	cc.ContainerRange(func(c *Container) {
+		// Pseudo-containers are re-created by their options
+		if c.IsHostContainer() {
+			return
+		}
+		containers = append(containers, c)
+	})
+
+	buf, err := json.Marshal(containers)
+	if err != nil {
+		log.Warnf("failed to marshal containers state: %s", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cc.stateFile), 0o700); err != nil {
+		log.Warnf("failed to save containers state: %s", err)
+		return
+	}
+	tmp := cc.stateFile + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		log.Warnf("failed to save containers state: %s", err)
+		return
+	}
+	if err := os.Rename(tmp, cc.stateFile); err != nil {
+		log.Warnf("failed to save containers state: %s", err)
+	}
+}
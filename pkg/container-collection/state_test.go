@@ -0,0 +1,133 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/runtime-spec/specs-go"
+
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
+)
+
+func TestPersistentState(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "containers.json")
+
+	mntns, err := containerutils.GetMntNs(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to get mount namespace: %s", err)
+	}
+
+	cc1 := &ContainerCollection{}
+	if err := cc1.Initialize(WithPersistentState(stateFile)); err != nil {
+		t.Fatalf("Failed to initialize container collection: %s", err)
+	}
+
+	cc1.AddContainer(&Container{
+		ID:        "alive",
+		Pid:       uint32(os.Getpid()),
+		Mntns:     mntns,
+		Bundle:    "/run/bundle/alive",
+		OciConfig: &ocispec.Spec{Hostname: "alive"},
+	})
+	cc1.AddContainer(&Container{
+		ID:    "pid-reused",
+		Pid:   uint32(os.Getpid()),
+		Mntns: mntns + 1,
+	})
+	cc1.AddContainer(&Container{
+		ID:  "removed",
+		Pid: uint32(os.Getpid()),
+	})
+	cc1.AddContainer(&Container{
+		ID:  "no-mntns",
+		Pid: uint32(os.Getpid()),
+	})
+	cc1.RemoveContainer("removed")
+	cc1.Close()
+
+	cc2 := &ContainerCollection{}
+	if err := cc2.Initialize(WithPersistentState(stateFile)); err != nil {
+		t.Fatalf("Failed to initialize container collection: %s", err)
+	}
+
+	if n := cc2.ContainerLen(); n != 1 {
+		t.Fatalf("Expected 1 restored container, got %d", n)
+	}
+	c := cc2.GetContainer("alive")
+	if c == nil {
+		t.Fatalf("Container \"alive\" was not restored")
+	}
+	if c.Bundle != "/run/bundle/alive" || c.OciConfig == nil || c.OciConfig.Hostname != "alive" {
+		t.Fatalf("Container \"alive\" was not restored correctly: %+v", c)
+	}
+}
+
+func TestPersistentStateReconcile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "containers.json")
+
+	mntns, err := containerutils.GetMntNs(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to get mount namespace: %s", err)
+	}
+
+	cc1 := &ContainerCollection{}
+	if err := cc1.Initialize(WithPersistentState(stateFile)); err != nil {
+		t.Fatalf("Failed to initialize container collection: %s", err)
+	}
+	cc1.AddContainer(&Container{
+		ID:        "container1",
+		Runtime:   "containerd",
+		Pid:       uint32(os.Getpid()),
+		Mntns:     mntns,
+		OciConfig: &ocispec.Spec{Hostname: "container1"},
+	})
+	// Its process is still there but the runtime doesn't list it anymore
+	cc1.AddContainer(&Container{
+		ID:      "container2",
+		Runtime: "containerd",
+		Pid:     uint32(os.Getpid()),
+		Mntns:   mntns,
+	})
+	cc1.Close()
+
+	// The runtime lists the container again, but without its OCI config
+	withRuntimeContainer := func(cc *ContainerCollection) error {
+		cc.initialContainers = append(cc.initialContainers, &Container{
+			ID:      "container1",
+			Runtime: "containerd",
+			Pid:     uint32(os.Getpid()),
+			Mntns:   mntns,
+			Name:    "from-runtime",
+		})
+		cc.listedRuntimes = map[string]struct{}{"containerd": {}}
+		return nil
+	}
+
+	cc2 := &ContainerCollection{}
+	if err := cc2.Initialize(withRuntimeContainer, WithPersistentState(stateFile)); err != nil {
+		t.Fatalf("Failed to initialize container collection: %s", err)
+	}
+
+	if n := cc2.ContainerLen(); n != 1 {
+		t.Fatalf("Expected 1 container, got %d", n)
+	}
+	c := cc2.GetContainer("container1")
+	if c == nil || c.Name != "from-runtime" || c.OciConfig == nil || c.OciConfig.Hostname != "container1" {
+		t.Fatalf("Container was not reconciled correctly: %+v", c)
+	}
+}
@@ -91,3 +91,13 @@ func GetCgroupPaths(pid int) (string, string, error) {
 
 	return cgroupPathV1, cgroupPathV2, nil
 }
+
+// IsCgroupV2 returns whether the host uses the unified hierarchy only, i.e.
+// all the controllers, like the memory one, are in cgroup v2.
+func IsCgroupV2() bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs("/sys/fs/cgroup", &st); err != nil {
+		return false
+	}
+	return st.Type == unix.CGROUP2_SUPER_MAGIC
+}
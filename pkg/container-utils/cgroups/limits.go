@@ -0,0 +1,271 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroups
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cgroup v1 reports "no limit" with the largest page-aligned int64. Consider
+// anything above this threshold as unlimited.
+const unlimitedV1 = uint64(1) << 62
+
+// Limits are the resource limits configured on a cgroup. A zero value means
+// there is no limit.
+type Limits struct {
+	// MemoryMax is the memory limit in bytes.
+	MemoryMax uint64 `json:"memoryMax,omitempty"`
+
+	// CPUQuota is the CPU time in microseconds the cgroup can use in each
+	// CPUPeriod.
+	CPUQuota  uint64 `json:"cpuQuota,omitempty"`
+	CPUPeriod uint64 `json:"cpuPeriod,omitempty"`
+
+	// PidsMax is the maximum number of tasks.
+	PidsMax uint64 `json:"pidsMax,omitempty"`
+}
+
+// CPUs returns the CPU limit as a number of CPUs, or zero if unlimited.
+func (l *Limits) CPUs() float64 {
+	if l.CPUQuota == 0 || l.CPUPeriod == 0 {
+		return 0
+	}
+	return float64(l.CPUQuota) / float64(l.CPUPeriod)
+}
+
+// Usage is the current resource usage of a cgroup.
+type Usage struct {
+	// MemoryCurrent is the memory used in bytes.
+	MemoryCurrent uint64
+
+	// CPUUsage is the cumulative CPU time consumed by the tasks.
+	CPUUsage time.Duration
+
+	// PidsCurrent is the number of tasks.
+	PidsCurrent uint64
+}
+
+// MemoryPercent returns the memory usage as a percentage of the limit, or zero
+// if there is no limit.
+func (u *Usage) MemoryPercent(l *Limits) float64 {
+	if l == nil || l.MemoryMax == 0 {
+		return 0
+	}
+	return 100 * float64(u.MemoryCurrent) / float64(l.MemoryMax)
+}
+
+// Cgroup gives access to the controller files of a cgroup. Controllers found
+// in V1 are read from their cgroup v1 hierarchy, the others from the unified
+// hierarchy.
+type Cgroup struct {
+	// V2 is the cgroup directory in the unified hierarchy, with mountpoint.
+	V2 string
+
+	// V1 is the cgroup directory, with mountpoint, of each cgroup v1
+	// controller. It's empty on a pure cgroup v2 system.
+	V1 map[string]string
+}
+
+// NewCgroup returns the cgroup of a process, for both cgroup v1 and v2
+// layouts.
+func NewCgroup(pid int) (*Cgroup, error) {
+	cgroupFile, err := os.Open(filepath.Join("/proc", fmt.Sprintf("%d", pid), "cgroup"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cgroup: %w", err)
+	}
+	defer cgroupFile.Close()
+
+	cg := &Cgroup{V1: make(map[string]string)}
+
+	scanner := bufio.NewScanner(cgroupFile)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		if parts[0] == "0" && parts[1] == "" {
+			// The unified hierarchy could be absent, e.g. on pure
+			// cgroup v1 systems.
+			cg.V2, _ = CgroupPathV2AddMountpoint(parts[2])
+			continue
+		}
+
+		for _, controller := range strings.Split(parts[1], ",") {
+			if controller == "" || strings.HasPrefix(controller, "name=") {
+				continue
+			}
+			// Co-mounted controllers like "cpu,cpuacct" have a symlink
+			// for each of them.
+			cg.V1[controller] = filepath.Join("/sys/fs/cgroup", controller, parts[2])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot parse cgroup: %w", err)
+	}
+
+	return cg, nil
+}
+
+func (cg *Cgroup) readFile(controller, v1File, v2File string) (string, bool, error) {
+	var path string
+	v1 := false
+	if dir, ok := cg.V1[controller]; ok {
+		path = filepath.Join(dir, v1File)
+		v1 = true
+	} else if cg.V2 != "" {
+		path = filepath.Join(cg.V2, v2File)
+	} else {
+		return "", false, fmt.Errorf("controller %q not found: %w", controller, os.ErrNotExist)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", v1, err
+	}
+	return strings.TrimSpace(string(buf)), v1, nil
+}
+
+// parseLimit parses a limit as written in cgroup files, where "max" (v2) or
+// "-1" (v1) mean unlimited.
+func parseLimit(s string) (uint64, error) {
+	if s == "max" || s == "-1" {
+		return 0, nil
+	}
+	val, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing limit %q: %w", s, err)
+	}
+	if val >= unlimitedV1 {
+		return 0, nil
+	}
+	return val, nil
+}
+
+// Limits reads the memory, cpu and pids limits of the cgroup. Controllers
+// that are not enabled for the cgroup are reported as unlimited.
+func (cg *Cgroup) Limits() (*Limits, error) {
+	limits := &Limits{}
+
+	s, _, err := cg.readFile("memory", "memory.limit_in_bytes", "memory.max")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if limits.MemoryMax, err = parseLimit(s); err != nil {
+			return nil, err
+		}
+	}
+
+	s, v1, err := cg.readFile("cpu", "cpu.cfs_quota_us", "cpu.max")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		// cpu.max is "$MAX $PERIOD" while cgroup v1 uses two files
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("parsing cpu quota: empty file")
+		}
+		if limits.CPUQuota, err = parseLimit(fields[0]); err != nil {
+			return nil, err
+		}
+
+		period := ""
+		if v1 {
+			period, _, err = cg.readFile("cpu", "cpu.cfs_period_us", "")
+			if err != nil {
+				return nil, err
+			}
+		} else if len(fields) > 1 {
+			period = fields[1]
+		}
+		if limits.CPUQuota != 0 && period != "" {
+			if limits.CPUPeriod, err = strconv.ParseUint(period, 10, 64); err != nil {
+				return nil, fmt.Errorf("parsing cpu period %q: %w", period, err)
+			}
+		}
+	}
+
+	s, _, err = cg.readFile("pids", "pids.max", "pids.max")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if limits.PidsMax, err = parseLimit(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return limits, nil
+}
+
+// Usage reads the current memory, cpu and pids usage of the cgroup.
+func (cg *Cgroup) Usage() (*Usage, error) {
+	usage := &Usage{}
+
+	s, _, err := cg.readFile("memory", "memory.usage_in_bytes", "memory.current")
+	if err != nil {
+		return nil, err
+	}
+	if usage.MemoryCurrent, err = strconv.ParseUint(s, 10, 64); err != nil {
+		return nil, fmt.Errorf("parsing memory usage %q: %w", s, err)
+	}
+
+	// cpuacct.usage is in nanoseconds, cpu.stat's usage_usec in microseconds
+	s, v1, err := cg.readFile("cpuacct", "cpuacct.usage", "cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	if v1 {
+		ns, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing cpu usage %q: %w", s, err)
+		}
+		usage.CPUUsage = time.Duration(ns)
+	} else {
+		for _, line := range strings.Split(s, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 || fields[0] != "usage_usec" {
+				continue
+			}
+			us, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing cpu usage %q: %w", fields[1], err)
+			}
+			usage.CPUUsage = time.Duration(us) * time.Microsecond
+		}
+	}
+
+	s, _, err = cg.readFile("pids", "pids.current", "pids.current")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if usage.PidsCurrent, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("parsing pids usage %q: %w", s, err)
+		}
+	}
+
+	return usage, nil
+}
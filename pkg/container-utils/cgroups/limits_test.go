@@ -0,0 +1,137 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCgroupV2(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"memory.max":     "536870912",
+		"memory.current": "526133493",
+		"cpu.max":        "50000 100000",
+		"cpu.stat":       "usage_usec 1500000\nuser_usec 1000000\nsystem_usec 500000",
+		"pids.max":       "max",
+		"pids.current":   "7",
+	})
+
+	cg := &Cgroup{V2: dir}
+
+	limits, err := cg.Limits()
+	if err != nil {
+		t.Fatalf("Limits() failed: %s", err)
+	}
+	expected := Limits{MemoryMax: 536870912, CPUQuota: 50000, CPUPeriod: 100000}
+	if *limits != expected {
+		t.Fatalf("got limits %+v, expected %+v", *limits, expected)
+	}
+	if cpus := limits.CPUs(); cpus != 0.5 {
+		t.Fatalf("got %f CPUs, expected 0.5", cpus)
+	}
+
+	usage, err := cg.Usage()
+	if err != nil {
+		t.Fatalf("Usage() failed: %s", err)
+	}
+	if usage.MemoryCurrent != 526133493 || usage.CPUUsage != 1500*time.Millisecond || usage.PidsCurrent != 7 {
+		t.Fatalf("unexpected usage %+v", *usage)
+	}
+	if p := usage.MemoryPercent(limits); p < 97.9 || p > 98.1 {
+		t.Fatalf("got memory usage of %f%%, expected 98%%", p)
+	}
+}
+
+func TestCgroupV1(t *testing.T) {
+	root := t.TempDir()
+	cg := &Cgroup{
+		V1: map[string]string{
+			"memory":  filepath.Join(root, "memory"),
+			"cpu":     filepath.Join(root, "cpu,cpuacct"),
+			"cpuacct": filepath.Join(root, "cpu,cpuacct"),
+			"pids":    filepath.Join(root, "pids"),
+		},
+	}
+	writeFiles(t, cg.V1["memory"], map[string]string{
+		"memory.limit_in_bytes": "9223372036854771712",
+		"memory.usage_in_bytes": "1048576",
+	})
+	writeFiles(t, cg.V1["cpu"], map[string]string{
+		"cpu.cfs_quota_us":  "200000",
+		"cpu.cfs_period_us": "100000",
+		"cpuacct.usage":     "2000000000",
+	})
+	writeFiles(t, cg.V1["pids"], map[string]string{
+		"pids.max":     "100",
+		"pids.current": "3",
+	})
+
+	limits, err := cg.Limits()
+	if err != nil {
+		t.Fatalf("Limits() failed: %s", err)
+	}
+	expected := Limits{CPUQuota: 200000, CPUPeriod: 100000, PidsMax: 100}
+	if *limits != expected {
+		t.Fatalf("got limits %+v, expected %+v", *limits, expected)
+	}
+
+	usage, err := cg.Usage()
+	if err != nil {
+		t.Fatalf("Usage() failed: %s", err)
+	}
+	if usage.MemoryCurrent != 1048576 || usage.CPUUsage != 2*time.Second || usage.PidsCurrent != 3 {
+		t.Fatalf("unexpected usage %+v", *usage)
+	}
+	if p := usage.MemoryPercent(limits); p != 0 {
+		t.Fatalf("got memory usage of %f%% without limit, expected 0", p)
+	}
+}
+
+func TestCgroupV1Unlimited(t *testing.T) {
+	root := t.TempDir()
+	cg := &Cgroup{
+		V1: map[string]string{
+			"cpu": filepath.Join(root, "cpu"),
+		},
+	}
+	writeFiles(t, cg.V1["cpu"], map[string]string{
+		"cpu.cfs_quota_us":  "-1",
+		"cpu.cfs_period_us": "100000",
+	})
+
+	limits, err := cg.Limits()
+	if err != nil {
+		t.Fatalf("Limits() failed: %s", err)
+	}
+	if *limits != (Limits{}) {
+		t.Fatalf("got limits %+v, expected none", *limits)
+	}
+}
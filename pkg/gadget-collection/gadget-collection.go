@@ -28,10 +28,16 @@ import (
 	tcptop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/top/tcp"
 	bindsnoop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/bind"
 	capabilities "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/capabilities"
+	creds "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/creds"
 	dns "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/dns"
 	execsnoop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/exec"
+	exitsnoop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/exit"
+	fsops "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/fsops"
 	fsslower "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/fsslower"
+	httptrace "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/http"
+	kernelload "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/kernel-load"
 	mountsnoop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/mount"
+	namespaces "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/namespaces"
 	networkgraph "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/network"
 	oomkill "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/oomkill"
 	opensnoop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/open"
@@ -39,6 +45,8 @@ import (
 	snisnoop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/sni"
 	tcptracer "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/tcp"
 	tcpconnect "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/tcpconnect"
+	tcpdrop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/tcpdrop"
+	tcpretrans "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/tcpretrans"
 	traceloop "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/traceloop"
 )
 
@@ -49,13 +57,19 @@ func TraceFactories() map[string]gadgets.TraceFactory {
 		"biolatency":        biolatency.NewFactory(),
 		"biotop":            biotop.NewFactory(),
 		"capabilities":      capabilities.NewFactory(),
+		"creds":             creds.NewFactory(),
 		"dns":               dns.NewFactory(),
 		"ebpftop":           ebpftop.NewFactory(),
 		"execsnoop":         execsnoop.NewFactory(),
+		"exitsnoop":         exitsnoop.NewFactory(),
 		"filetop":           filetop.NewFactory(),
+		"fsops":             fsops.NewFactory(),
 		"fsslower":          fsslower.NewFactory(),
+		"http":              httptrace.NewFactory(),
+		"kernel-load":       kernelload.NewFactory(),
 		"opensnoop":         opensnoop.NewFactory(),
 		"mountsnoop":        mountsnoop.NewFactory(),
+		"namespaces":        namespaces.NewFactory(),
 		"network-graph":     networkgraph.NewFactory(),
 		"oomkill":           oomkill.NewFactory(),
 		"process-collector": processcollector.NewFactory(),
@@ -65,6 +79,8 @@ func TraceFactories() map[string]gadgets.TraceFactory {
 		"snisnoop":          snisnoop.NewFactory(),
 		"socket-collector":  socketcollector.NewFactory(),
 		"tcpconnect":        tcpconnect.NewFactory(),
+		"tcpdrop":           tcpdrop.NewFactory(),
+		"tcpretrans":        tcpretrans.NewFactory(),
 		"tcptop":            tcptop.NewFactory(),
 		"tcptracer":         tcptracer.NewFactory(),
 		"traceloop":         traceloop.NewFactory(),
@@ -74,13 +90,17 @@ func TraceFactories() map[string]gadgets.TraceFactory {
 func TraceFactoriesForLocalGadget() map[string]gadgets.TraceFactory {
 	return map[string]gadgets.TraceFactory{
 		"capabilities":      capabilities.NewFactory(),
+		"creds":             creds.NewFactory(),
 		"dns":               dns.NewFactory(),
 		"ebpftop":           ebpftop.NewFactory(),
+		"http":              httptrace.NewFactory(),
 		"network-graph":     networkgraph.NewFactory(),
 		"process-collector": processcollector.NewFactory(),
 		"socket-collector":  socketcollector.NewFactory(),
 		"snisnoop":          snisnoop.NewFactory(),
 		"sigsnoop":          sigsnoop.NewFactory(),
+		"tcpdrop":           tcpdrop.NewFactory(),
+		"tcpretrans":        tcpretrans.NewFactory(),
 		"traceloop":         traceloop.NewFactory(),
 	}
 }
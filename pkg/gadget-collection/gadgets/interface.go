@@ -81,6 +81,8 @@ type TraceOperation struct {
 type GadgetHelpers interface {
 	containercollection.ContainerResolver
 	gadgets.DataEnricher
+	gadgets.DataEnricherByNetNs
+	gadgets.DataEnricherByCgroupID
 
 	PublishEvent(tracerID string, line string) error
 	TracerMountNsMap(tracerID string) (*ebpf.Map, error)
@@ -50,7 +50,13 @@ func NewFactory() gadgets.TraceFactory {
 }
 
 func (f *TraceFactory) Description() string {
-	return `bindsnoop traces the kernel functions performing socket binding.`
+	return `bindsnoop traces the kernel functions performing socket binding.
+
+The following parameters are supported:
+- pid: Show only bind events generated by this particular PID.
+- ports: Trace only bind events involving these ports, separated by commas.
+- ignore_errors: Show only events where the bind succeeded (default to false).
+- listen: Show also the listen() calls, with their backlog, and the close of the listening sockets (default to false).`
 }
 
 func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
@@ -145,6 +151,17 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		ignoreErrors = ignoreErrorsParsed
 	}
 
+	listen := false
+	if val, ok := params["listen"]; ok {
+		listenParsed, err := strconv.ParseBool(val)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for listen", val)
+			return
+		}
+
+		listen = listenParsed
+	}
+
 	var err error
 
 	mountNsMap, err := t.helpers.TracerMountNsMap(traceName)
@@ -157,6 +174,7 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		TargetPid:    targetPid,
 		TargetPorts:  targetPorts,
 		IgnoreErrors: ignoreErrors,
+		Listen:       listen,
 	}
 	t.tracer, err = tracer.NewTracer(config, t.helpers, eventCallback)
 	if err != nil {
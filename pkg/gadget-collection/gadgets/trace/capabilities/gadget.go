@@ -96,6 +96,7 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 
 	auditOnly := types.AuditOnlyDefault
 	unique := types.UniqueDefault
+	printStack := types.PrintStackDefault
 
 	if trace.Spec.Parameters != nil {
 		params := trace.Spec.Parameters
@@ -115,6 +116,13 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 				return
 			}
 		}
+		if val, ok := params[types.PrintStackParam]; ok {
+			printStack, err = strconv.ParseBool(val)
+			if err != nil {
+				trace.Status.OperationError = fmt.Sprintf("%q is not valid for %q", val, types.PrintStackParam)
+				return
+			}
+		}
 	}
 
 	traceName := gadgets.TraceName(trace.ObjectMeta.Namespace, trace.ObjectMeta.Name)
@@ -139,6 +147,7 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		MountnsMap: mountNsMap,
 		AuditOnly:  auditOnly,
 		Unique:     unique,
+		PrintStack: printStack,
 	}
 
 	t.tracer, err = tracer.NewTracer(config, t.helpers, eventCallback)
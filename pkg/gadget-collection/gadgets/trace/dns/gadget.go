@@ -16,6 +16,7 @@ package dns
 
 import (
 	"fmt"
+	"strconv"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -49,7 +50,10 @@ func NewFactory() gadgets.TraceFactory {
 }
 
 func (f *TraceFactory) Description() string {
-	return `The dns gadget traces DNS requests.`
+	return `The dns gadget traces DNS requests.
+
+The following parameters are supported:
+- responsesonly: Only show responses, with the latency since their query. (default false)`
 }
 
 func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
@@ -107,8 +111,18 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		return
 	}
 
+	tracerConfig := &dnsTracer.Config{}
+	if val, ok := trace.Spec.Parameters["responsesonly"]; ok {
+		responsesOnly, err := strconv.ParseBool(val)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for responsesonly", val)
+			return
+		}
+		tracerConfig.ResponsesOnly = responsesOnly
+	}
+
 	var err error
-	t.tracer, err = dnsTracer.NewTracer()
+	t.tracer, err = dnsTracer.NewTracer(tracerConfig)
 	if err != nil {
 		trace.Status.OperationError = fmt.Sprintf("Failed to start dns tracer: %s", err)
 		return
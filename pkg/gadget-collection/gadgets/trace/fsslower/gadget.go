@@ -29,7 +29,7 @@ import (
 	gadgetv1alpha1 "github.com/lato333/inspektor-gadget/pkg/apis/gadget/v1alpha1"
 )
 
-var validFilesystems = []string{"btrfs", "ext4", "nfs", "xfs"}
+var validFilesystems = []string{"btrfs", "ext4", "nfs", "nfs4", "xfs"}
 
 type Trace struct {
 	helpers gadgets.GadgetHelpers
@@ -52,7 +52,7 @@ func (f *TraceFactory) Description() string {
 	t := `fsslower shows open, read, write and fsync operations slower than a threshold
 
 The following parameters are supported:
-- filesystem: Which filesystem to trace [%s]
+- filesystem: Which filesystem to trace [%s] (default to all the supported filesystems whose module is loaded)
 - minlatency: Min latency to trace, in ms. (default %d)`
 
 	return fmt.Sprintf(t, strings.Join(validFilesystems, ", "), types.MinLatencyDefault)
@@ -113,18 +113,9 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 
 	var err error
 
-	if trace.Spec.Parameters == nil {
-		trace.Status.OperationError = "missing parameters"
-		return
-	}
-
 	params := trace.Spec.Parameters
 
-	filesystem, ok := params["filesystem"]
-	if !ok {
-		trace.Status.OperationError = "missing filesystem"
-		return
-	}
+	filesystem := params["filesystem"]
 
 	minLatency := types.MinLatencyDefault
 
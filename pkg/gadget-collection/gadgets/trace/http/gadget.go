@@ -0,0 +1,177 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gadgetv1alpha1 "github.com/lato333/inspektor-gadget/pkg/apis/gadget/v1alpha1"
+	containercollection "github.com/lato333/inspektor-gadget/pkg/container-collection"
+	"github.com/lato333/inspektor-gadget/pkg/container-collection/networktracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets"
+	httpTracer "github.com/lato333/inspektor-gadget/pkg/gadgets/trace/http/tracer"
+	httpTypes "github.com/lato333/inspektor-gadget/pkg/gadgets/trace/http/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type Trace struct {
+	helpers gadgets.GadgetHelpers
+	client  client.Client
+
+	started bool
+
+	tracer *httpTracer.Tracer
+	conn   *networktracer.ConnectionToContainerCollection
+}
+
+type TraceFactory struct {
+	gadgets.BaseFactory
+}
+
+func NewFactory() gadgets.TraceFactory {
+	return &TraceFactory{
+		BaseFactory: gadgets.BaseFactory{DeleteTrace: deleteTrace},
+	}
+}
+
+func (f *TraceFactory) Description() string {
+	return `The http gadget traces plain-text HTTP/1.x requests and their responses.
+
+The following parameters are supported:
+- ports: Comma-separated list of server ports to trace. (default 80,8080)`
+}
+
+func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
+	return map[gadgetv1alpha1.TraceOutputMode]struct{}{
+		gadgetv1alpha1.TraceOutputModeStream: {},
+	}
+}
+
+func deleteTrace(name string, t interface{}) {
+	trace := t.(*Trace)
+	if trace.started {
+		if trace.conn != nil {
+			trace.conn.Close()
+		}
+		trace.tracer.Close()
+		trace.tracer = nil
+	}
+}
+
+func (f *TraceFactory) Operations() map[gadgetv1alpha1.Operation]gadgets.TraceOperation {
+	n := func() interface{} {
+		return &Trace{
+			client:  f.Client,
+			helpers: f.Helpers,
+		}
+	}
+
+	return map[gadgetv1alpha1.Operation]gadgets.TraceOperation{
+		gadgetv1alpha1.OperationStart: {
+			Doc: "Start http",
+			Operation: func(name string, trace *gadgetv1alpha1.Trace) {
+				f.LookupOrCreate(name, n).(*Trace).Start(trace)
+			},
+		},
+		gadgetv1alpha1.OperationStop: {
+			Doc: "Stop http",
+			Operation: func(name string, trace *gadgetv1alpha1.Trace) {
+				f.LookupOrCreate(name, n).(*Trace).Stop(trace)
+			},
+		},
+	}
+}
+
+func (t *Trace) publishEvent(trace *gadgetv1alpha1.Trace, event *httpTypes.Event) {
+	traceName := gadgets.TraceName(trace.ObjectMeta.Namespace, trace.ObjectMeta.Name)
+	t.helpers.PublishEvent(
+		traceName,
+		eventtypes.EventString(event),
+	)
+}
+
+func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
+	if t.started {
+		trace.Status.State = gadgetv1alpha1.TraceStateStarted
+		return
+	}
+
+	tracerConfig := &httpTracer.Config{}
+	if val, ok := trace.Spec.Parameters["ports"]; ok && len(val) > 0 {
+		for _, portString := range strings.Split(val, ",") {
+			port, err := strconv.ParseUint(portString, 10, 16)
+			if err != nil || port == 0 {
+				trace.Status.OperationError = fmt.Sprintf("%q is not valid for port", portString)
+				return
+			}
+			tracerConfig.Ports = append(tracerConfig.Ports, uint16(port))
+		}
+	}
+
+	var err error
+	t.tracer, err = httpTracer.NewTracer(tracerConfig)
+	if err != nil {
+		trace.Status.OperationError = fmt.Sprintf("Failed to start http tracer: %s", err)
+		return
+	}
+
+	eventCallback := func(container *containercollection.Container, event httpTypes.Event) {
+		// Enrich event with data from container
+		event.Node = trace.Spec.Node
+		if !container.HostNetwork {
+			event.Namespace = container.Namespace
+			event.Pod = container.Podname
+		}
+
+		t.publishEvent(trace, &event)
+	}
+
+	config := &networktracer.ConnectToContainerCollectionConfig[httpTypes.Event]{
+		Tracer:        t.tracer,
+		Resolver:      t.helpers,
+		Selector:      *gadgets.ContainerSelectorFromContainerFilter(trace.Spec.Filter),
+		EventCallback: eventCallback,
+		Base:          httpTypes.Base,
+	}
+	t.conn, err = networktracer.ConnectToContainerCollection(config)
+	if err != nil {
+		trace.Status.OperationError = fmt.Sprintf("Failed to start http tracer: %s", err)
+		return
+	}
+
+	t.started = true
+
+	trace.Status.State = gadgetv1alpha1.TraceStateStarted
+}
+
+func (t *Trace) Stop(trace *gadgetv1alpha1.Trace) {
+	if !t.started {
+		trace.Status.OperationError = "Not started"
+		return
+	}
+
+	if t.conn != nil {
+		t.conn.Close()
+	}
+	t.tracer.Close()
+	t.tracer = nil
+	t.started = false
+
+	trace.Status.State = gadgetv1alpha1.TraceStateStopped
+}
@@ -49,7 +49,8 @@ func NewFactory() gadgets.TraceFactory {
 }
 
 func (f *TraceFactory) Description() string {
-	return `mountsnoop traces mount and umount syscalls`
+	return `mountsnoop traces mount and umount syscalls, as well as the new mount API
+(fsopen, fsconfig, fsmount, move_mount, open_tree and mount_setattr)`
 }
 
 func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
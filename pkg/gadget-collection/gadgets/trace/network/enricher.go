@@ -52,7 +52,63 @@ func NewEnricher(withKubernetes bool, node string) (*Enricher, error) {
 	}, nil
 }
 
-func enrich(event *types.Event, node string, pods *corev1.PodList, svcs *corev1.ServiceList) {
+// Remote is the pod or the service having the address of the remote endpoint
+// of a connection.
+type Remote struct {
+	Kind      types.RemoteKind
+	Namespace string
+	Name      string
+	Labels    map[string]string
+}
+
+// RemotePodIP returns the address remote endpoints are matched against for
+// this pod. It's empty for the pods using the host network, as their address
+// is the one of the node.
+func RemotePodIP(pod *corev1.Pod) string {
+	if pod.Spec.HostNetwork {
+		return ""
+	}
+	return pod.Status.PodIP
+}
+
+// LookupRemotePod returns the pod having the address addr, if any.
+func LookupRemotePod(addr string, pods []*corev1.Pod) (Remote, bool) {
+	for _, pod := range pods {
+		if ip := RemotePodIP(pod); ip != "" && ip == addr {
+			return Remote{
+				Kind:      types.RemoteKindPod,
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Labels:    pod.Labels,
+			}, true
+		}
+	}
+	return Remote{}, false
+}
+
+// LookupRemoteService returns the service whose cluster IP is addr, if any.
+func LookupRemoteService(addr string, svcs []*corev1.Service) (Remote, bool) {
+	for _, svc := range svcs {
+		if svc.Spec.ClusterIP == addr {
+			return Remote{
+				Kind:      types.RemoteKindService,
+				Namespace: svc.Namespace,
+				Name:      svc.Name,
+				Labels:    svc.Spec.Selector,
+			}, true
+		}
+	}
+	return Remote{}, false
+}
+
+func setRemote(event *types.Event, remote Remote) {
+	event.RemoteKind = remote.Kind
+	event.RemoteNamespace = remote.Namespace
+	event.RemoteName = remote.Name
+	event.RemoteLabels = remote.Labels
+}
+
+func enrich(event *types.Event, node string, pods []*corev1.Pod, svcs []*corev1.Service) {
 	var namespace, name string
 	parts := strings.Split(event.Key, "/")
 	if len(parts) == 2 {
@@ -64,10 +120,10 @@ func enrich(event *types.Event, node string, pods *corev1.PodList, svcs *corev1.
 	event.Pod = name
 
 	// Find the pod resource where the packet capture occurred
-	localPodIndex := -1
-	for i, pod := range pods.Items {
+	var localPod *corev1.Pod
+	for _, pod := range pods {
 		if pod.GetNamespace() == namespace && pod.GetName() == name {
-			localPodIndex = i
+			localPod = pod
 			event.PodLabels = pod.Labels
 			// Kubernetes Network Policies can't block traffic from
 			// a pod's resident node. Therefore we must not
@@ -80,26 +136,17 @@ func enrich(event *types.Event, node string, pods *corev1.PodList, svcs *corev1.
 	}
 
 	// Find the remote pod, if any
-	for _, pod := range pods.Items {
-		if pod.Spec.HostNetwork {
-			continue
-		}
-		if pod.Status.PodIP == event.RemoteAddr {
-			event.RemoteKind = types.RemoteKindPod
-			event.RemoteNamespace = pod.Namespace
-			event.RemoteName = pod.Name
-			event.RemoteLabels = pod.Labels
-			break
-		}
+	if remote, ok := LookupRemotePod(event.RemoteAddr, pods); ok {
+		setRemote(event, remote)
 	}
-	if localPodIndex == -1 {
+	if localPod == nil {
 		return
 	}
 
 	// When the pod belongs to Deployment, ReplicaSet or DaemonSet, find the
 	// shorter name without the random suffix. That will be used to
 	// generate the network policy name.
-	if pods.Items[localPodIndex].OwnerReferences != nil {
+	if localPod.OwnerReferences != nil {
 		nameItems := strings.Split(event.Pod, "-")
 		if len(nameItems) > 2 {
 			event.PodOwner = strings.Join(nameItems[:len(nameItems)-2], "-")
@@ -107,14 +154,8 @@ func enrich(event *types.Event, node string, pods *corev1.PodList, svcs *corev1.
 	}
 
 	if event.RemoteKind == "" {
-		for _, svc := range svcs.Items {
-			if svc.Spec.ClusterIP == event.RemoteAddr {
-				event.RemoteKind = types.RemoteKindService
-				event.RemoteNamespace = svc.Namespace
-				event.RemoteName = svc.Name
-				event.RemoteLabels = svc.Spec.Selector
-				break
-			}
+		if remote, ok := LookupRemoteService(event.RemoteAddr, svcs); ok {
+			setRemote(event, remote)
 		}
 	}
 
@@ -124,21 +165,27 @@ func enrich(event *types.Event, node string, pods *corev1.PodList, svcs *corev1.
 }
 
 func (e *Enricher) Enrich(events []*types.Event) {
-	var err error
-	pods := &corev1.PodList{}
-	svcs := &corev1.ServiceList{}
+	pods := []*corev1.Pod{}
+	svcs := []*corev1.Service{}
 
 	if e.withKubernetes {
-		pods, err = e.clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+		podList, err := e.clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
 			log.Errorf("%s", err)
 			return
 		}
-		svcs, err = e.clientset.CoreV1().Services("").List(context.TODO(), metav1.ListOptions{})
+		svcList, err := e.clientset.CoreV1().Services("").List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
 			log.Errorf("%s", err)
 			return
 		}
+
+		for i := range podList.Items {
+			pods = append(pods, &podList.Items[i])
+		}
+		for i := range svcList.Items {
+			svcs = append(svcs, &svcList.Items[i])
+		}
 	}
 
 	for _, event := range events {
@@ -54,6 +54,7 @@ The following parameters are supported:
 - failed: Trace only failed signal sending (default to false).
 - signal: Which particular signal to trace (default to all).
 - pid: Which particular pid to trace (default to all).
+- crosscontainer: Trace only the signals sent to processes in another container, or between a container and the host. The filter on the containers then matches the sender or the target of the signals (default to false).
 `
 }
 
@@ -139,6 +140,17 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		failedOnly = failedParsed
 	}
 
+	crossContainerOnly := false
+	if crossContainer, ok := params["crosscontainer"]; ok {
+		crossContainerParsed, err := strconv.ParseBool(crossContainer)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for crosscontainer", crossContainer)
+			return
+		}
+
+		crossContainerOnly = crossContainerParsed
+	}
+
 	var err error
 
 	mountNsMap, err := t.helpers.TracerMountNsMap(traceName)
@@ -147,10 +159,11 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		return
 	}
 	config := &tracer.Config{
-		MountnsMap:   mountNsMap,
-		TargetPid:    targetPid,
-		TargetSignal: targetSignal,
-		FailedOnly:   failedOnly,
+		MountnsMap:         mountNsMap,
+		TargetPid:          targetPid,
+		TargetSignal:       targetSignal,
+		FailedOnly:         failedOnly,
+		CrossContainerOnly: crossContainerOnly,
 	}
 	t.tracer, err = tracer.NewTracer(config, t.helpers, eventCallback)
 	if err != nil {
@@ -16,6 +16,7 @@ package snisnoop
 
 import (
 	"fmt"
+	"strconv"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -49,7 +50,11 @@ func NewFactory() gadgets.TraceFactory {
 }
 
 func (f *TraceFactory) Description() string {
-	return `The snisnoop gadget retrieves Server Name Indication (SNI) from TLS requests.`
+	return `The snisnoop gadget retrieves Server Name Indication (SNI) from TLS requests.
+
+The following parameters are supported:
+- negotiated: Show one event per handshake, when the server answers, with the TLS version and cipher it selected. (default false)
+- withoutsni: Also show the TLS handshakes without Server Name Indication. (default false)`
 }
 
 func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
@@ -107,8 +112,26 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		return
 	}
 
+	tracerConfig := &sniTracer.Config{}
+	if val, ok := trace.Spec.Parameters["negotiated"]; ok {
+		negotiated, err := strconv.ParseBool(val)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for negotiated", val)
+			return
+		}
+		tracerConfig.Negotiated = negotiated
+	}
+	if val, ok := trace.Spec.Parameters["withoutsni"]; ok {
+		withoutSNI, err := strconv.ParseBool(val)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for withoutsni", val)
+			return
+		}
+		tracerConfig.WithoutSNI = withoutSNI
+	}
+
 	var err error
-	t.tracer, err = sniTracer.NewTracer()
+	t.tracer, err = sniTracer.NewTracer(tracerConfig)
 	if err != nil {
 		trace.Status.OperationError = fmt.Sprintf("Failed to start sni tracer: %s", err)
 		return
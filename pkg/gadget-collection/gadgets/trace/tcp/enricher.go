@@ -0,0 +1,135 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcptracer
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+
+	networkgraph "github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets/trace/network"
+	networkTypes "github.com/lato333/inspektor-gadget/pkg/gadgets/trace/network/types"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcp/types"
+	"github.com/lato333/inspektor-gadget/pkg/k8sutil"
+)
+
+// ipIndex is the name of the indexer keeping the pods and services by the
+// address remote endpoints are matched against.
+const ipIndex = "ip"
+
+// cacheSyncTimeout is how long to wait for the initial lists of pods and
+// services.
+const cacheSyncTimeout = 30 * time.Second
+
+// RemoteEnricher resolves the remote endpoint of the connections to a pod or
+// a service, like the network gadget does. The pods and services are kept up
+// to date by informers and indexed by address.
+type RemoteEnricher struct {
+	pods cache.Indexer
+	svcs cache.Indexer
+
+	stop chan struct{}
+}
+
+func NewRemoteEnricher() (*RemoteEnricher, error) {
+	clientset, err := k8sutil.NewClientset("")
+	if err != nil {
+		return nil, err
+	}
+
+	podListWatcher := cache.NewListWatchFromClient(clientset.CoreV1().RESTClient(), "pods", "", fields.Everything())
+	pods, podInformer := cache.NewIndexerInformer(podListWatcher, &corev1.Pod{}, 0, cache.ResourceEventHandlerFuncs{}, cache.Indexers{
+		ipIndex: func(obj interface{}) ([]string, error) {
+			if ip := networkgraph.RemotePodIP(obj.(*corev1.Pod)); ip != "" {
+				return []string{ip}, nil
+			}
+			return nil, nil
+		},
+	})
+
+	svcListWatcher := cache.NewListWatchFromClient(clientset.CoreV1().RESTClient(), "services", "", fields.Everything())
+	svcs, svcInformer := cache.NewIndexerInformer(svcListWatcher, &corev1.Service{}, 0, cache.ResourceEventHandlerFuncs{}, cache.Indexers{
+		ipIndex: func(obj interface{}) ([]string, error) {
+			if ip := obj.(*corev1.Service).Spec.ClusterIP; ip != "" {
+				return []string{ip}, nil
+			}
+			return nil, nil
+		},
+	})
+
+	e := &RemoteEnricher{
+		pods: pods,
+		svcs: svcs,
+		stop: make(chan struct{}),
+	}
+
+	go podInformer.Run(e.stop)
+	go svcInformer.Run(e.stop)
+
+	syncStop := make(chan struct{})
+	timer := time.AfterFunc(cacheSyncTimeout, func() { close(syncStop) })
+	synced := cache.WaitForCacheSync(syncStop, podInformer.HasSynced, svcInformer.HasSynced)
+	timer.Stop()
+	if !synced {
+		e.Close()
+		return nil, errors.New("timed out waiting for the pods and services to be listed")
+	}
+
+	return e, nil
+}
+
+func (e *RemoteEnricher) Enrich(event *types.Event) {
+	objs, err := e.pods.ByIndex(ipIndex, event.Daddr)
+	if err != nil {
+		log.Errorf("%s", err)
+		return
+	}
+	pods := make([]*corev1.Pod, 0, len(objs))
+	for _, obj := range objs {
+		pods = append(pods, obj.(*corev1.Pod))
+	}
+	remote, ok := networkgraph.LookupRemotePod(event.Daddr, pods)
+
+	if !ok {
+		objs, err = e.svcs.ByIndex(ipIndex, event.Daddr)
+		if err != nil {
+			log.Errorf("%s", err)
+			return
+		}
+		svcs := make([]*corev1.Service, 0, len(objs))
+		for _, obj := range objs {
+			svcs = append(svcs, obj.(*corev1.Service))
+		}
+		remote, ok = networkgraph.LookupRemoteService(event.Daddr, svcs)
+	}
+
+	if !ok {
+		event.RemoteKind = networkTypes.RemoteKindOther
+		return
+	}
+
+	event.RemoteKind = remote.Kind
+	event.RemoteNamespace = remote.Namespace
+	event.RemoteName = remote.Name
+}
+
+// Close stops the informers.
+func (e *RemoteEnricher) Close() {
+	close(e.stop)
+}
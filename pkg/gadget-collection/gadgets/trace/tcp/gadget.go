@@ -17,6 +17,7 @@ package tcptracer
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
@@ -28,13 +29,15 @@ import (
 	standardtracer "github.com/lato333/inspektor-gadget/pkg/standardgadgets/trace/tcp"
 
 	gadgetv1alpha1 "github.com/lato333/inspektor-gadget/pkg/apis/gadget/v1alpha1"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
 
 type Trace struct {
 	helpers gadgets.GadgetHelpers
 
-	started bool
-	tracer  trace.Tracer
+	started  bool
+	tracer   trace.Tracer
+	enricher *RemoteEnricher
 }
 
 type TraceFactory struct {
@@ -48,7 +51,10 @@ func NewFactory() gadgets.TraceFactory {
 }
 
 func (f *TraceFactory) Description() string {
-	return `Trace tcp connect, accept and close`
+	return `Trace tcp connect, accept and close
+
+The following parameters are supported:
+- lifetime: Trace one event per connection closed, with its duration, the bytes sent and received and the remote pod or service (default to false).`
 }
 
 func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
@@ -62,6 +68,9 @@ func deleteTrace(name string, t interface{}) {
 	if trace.tracer != nil {
 		trace.tracer.Stop()
 	}
+	if trace.enricher != nil {
+		trace.enricher.Close()
+	}
 }
 
 func (f *TraceFactory) Operations() map[gadgetv1alpha1.Operation]gadgets.TraceOperation {
@@ -95,7 +104,34 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 
 	traceName := gadgets.TraceName(trace.ObjectMeta.Namespace, trace.ObjectMeta.Name)
 
+	params := trace.Spec.Parameters
+
+	lifetime := false
+	if val, ok := params["lifetime"]; ok {
+		lifetimeParsed, err := strconv.ParseBool(val)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for lifetime", val)
+			return
+		}
+
+		lifetime = lifetimeParsed
+	}
+
+	var enricher *RemoteEnricher
+	if lifetime {
+		var err error
+		enricher, err = NewRemoteEnricher()
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("failed to create remote enricher: %s", err)
+			return
+		}
+	}
+
 	eventCallback := func(event types.Event) {
+		if enricher != nil && event.Type == eventtypes.NORMAL {
+			enricher.Enrich(&event)
+		}
+
 		r, err := json.Marshal(event)
 		if err != nil {
 			log.Warnf("Gadget %s: error marshalling event: %s", trace.Spec.Gadget, err)
@@ -113,6 +149,7 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 	}
 	config := &tracer.Config{
 		MountnsMap: mountNsMap,
+		Lifetime:   lifetime,
 	}
 
 	t.tracer, err = tracer.NewTracer(config, t.helpers, eventCallback)
@@ -125,11 +162,15 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 
 		t.tracer, err = standardtracer.NewTracer(config, eventCallback)
 		if err != nil {
+			if enricher != nil {
+				enricher.Close()
+			}
 			trace.Status.OperationError = fmt.Sprintf("failed to create tracer: %s", err)
 			return
 		}
 	}
 
+	t.enricher = enricher
 	t.started = true
 
 	trace.Status.State = gadgetv1alpha1.TraceStateStarted
@@ -143,6 +184,10 @@ func (t *Trace) Stop(trace *gadgetv1alpha1.Trace) {
 
 	t.tracer.Stop()
 	t.tracer = nil
+	if t.enricher != nil {
+		t.enricher.Close()
+		t.enricher = nil
+	}
 	t.started = false
 
 	trace.Status.State = gadgetv1alpha1.TraceStateStopped
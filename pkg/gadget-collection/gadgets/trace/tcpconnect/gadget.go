@@ -17,6 +17,7 @@ package tcpconnect
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
@@ -48,7 +49,10 @@ func NewFactory() gadgets.TraceFactory {
 }
 
 func (f *TraceFactory) Description() string {
-	return `tcpconnect traces connect() system calls`
+	return `tcpconnect traces connect() system calls, with the latency of the handshake or the reason of the failure.
+
+The following parameters are supported:
+- minlatency: Min latency of the connections established to trace, in ms. Failed connections are always traced. (default 0)`
 }
 
 func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
@@ -104,6 +108,19 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 		t.helpers.PublishEvent(traceName, string(r))
 	}
 
+	params := trace.Spec.Parameters
+
+	minLatency := uint(0)
+	if val, ok := params["minlatency"]; ok {
+		minLatencyParsed, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for minlatency", val)
+			return
+		}
+
+		minLatency = uint(minLatencyParsed)
+	}
+
 	var err error
 
 	mountNsMap, err := t.helpers.TracerMountNsMap(traceName)
@@ -113,6 +130,7 @@ func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
 	}
 	config := &tracer.Config{
 		MountnsMap: mountNsMap,
+		MinLatency: minLatency,
 	}
 	t.tracer, err = tracer.NewTracer(config, t.helpers, eventCallback)
 	if err != nil {
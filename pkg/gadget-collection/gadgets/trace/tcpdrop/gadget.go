@@ -0,0 +1,202 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcpdrop
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	gadgetv1alpha1 "github.com/lato333/inspektor-gadget/pkg/apis/gadget/v1alpha1"
+	containercollection "github.com/lato333/inspektor-gadget/pkg/container-collection"
+	"github.com/lato333/inspektor-gadget/pkg/gadget-collection/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcpdrop/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcpdrop/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type Trace struct {
+	helpers gadgets.GadgetHelpers
+
+	started bool
+	tracer  *tracer.Tracer
+
+	// subKey is set when the trace is restricted to the network
+	// namespaces of the containers matching the filter
+	subKey string
+}
+
+type TraceFactory struct {
+	gadgets.BaseFactory
+}
+
+func NewFactory() gadgets.TraceFactory {
+	return &TraceFactory{
+		BaseFactory: gadgets.BaseFactory{DeleteTrace: deleteTrace},
+	}
+}
+
+func (f *TraceFactory) Description() string {
+	return `The tcpdrop gadget traces TCP and UDP packets dropped by the kernel.
+
+The following parameters are supported:
+- kernelstack: Collect the kernel stack of each drop. (default false)`
+}
+
+func (f *TraceFactory) OutputModesSupported() map[gadgetv1alpha1.TraceOutputMode]struct{} {
+	return map[gadgetv1alpha1.TraceOutputMode]struct{}{
+		gadgetv1alpha1.TraceOutputModeStream: {},
+	}
+}
+
+func deleteTrace(name string, t interface{}) {
+	trace := t.(*Trace)
+	if trace.started {
+		trace.stop()
+	}
+}
+
+func (f *TraceFactory) Operations() map[gadgetv1alpha1.Operation]gadgets.TraceOperation {
+	n := func() interface{} {
+		return &Trace{
+			helpers: f.Helpers,
+		}
+	}
+
+	return map[gadgetv1alpha1.Operation]gadgets.TraceOperation{
+		gadgetv1alpha1.OperationStart: {
+			Doc: "Start tcpdrop gadget",
+			Operation: func(name string, trace *gadgetv1alpha1.Trace) {
+				f.LookupOrCreate(name, n).(*Trace).Start(trace)
+			},
+		},
+		gadgetv1alpha1.OperationStop: {
+			Doc: "Stop tcpdrop gadget",
+			Operation: func(name string, trace *gadgetv1alpha1.Trace) {
+				f.LookupOrCreate(name, n).(*Trace).Stop(trace)
+			},
+		},
+	}
+}
+
+// filterIsEmpty returns true if the filter selects all the containers. In
+// that case, drops in all the network namespaces of the node are traced,
+// including the host one.
+func filterIsEmpty(f *gadgetv1alpha1.ContainerFilter) bool {
+	return f == nil || (f.Namespace == "" && f.Podname == "" &&
+		f.ContainerName == "" && len(f.Labels) == 0)
+}
+
+func (t *Trace) Start(trace *gadgetv1alpha1.Trace) {
+	if t.started {
+		trace.Status.State = gadgetv1alpha1.TraceStateStarted
+		return
+	}
+
+	traceName := gadgets.TraceName(trace.ObjectMeta.Namespace, trace.ObjectMeta.Name)
+
+	eventCallback := func(event types.Event) {
+		r, err := json.Marshal(event)
+		if err != nil {
+			fmt.Printf("error marshalling event: %s\n", err)
+			return
+		}
+		t.helpers.PublishEvent(traceName, string(r))
+	}
+
+	config := &tracer.Config{
+		FilterByNetns: !filterIsEmpty(trace.Spec.Filter),
+	}
+
+	if val, ok := trace.Spec.Parameters["kernelstack"]; ok {
+		kernelStack, err := strconv.ParseBool(val)
+		if err != nil {
+			trace.Status.OperationError = fmt.Sprintf("%q is not valid for kernelstack", val)
+			return
+		}
+		config.KernelStack = kernelStack
+	}
+
+	var err error
+	t.tracer, err = tracer.NewTracer(config, t.helpers, eventCallback)
+	if err != nil {
+		trace.Status.OperationError = fmt.Sprintf("failed to create tracer: %s", err)
+		return
+	}
+
+	if config.FilterByNetns {
+		t.subKey = traceName
+		t.subscribe(*gadgets.ContainerSelectorFromContainerFilter(trace.Spec.Filter), eventCallback)
+	}
+
+	t.started = true
+
+	trace.Status.State = gadgetv1alpha1.TraceStateStarted
+}
+
+// subscribe keeps the network namespace filter of the tracer in sync with the
+// containers matching the selector.
+func (t *Trace) subscribe(selector containercollection.ContainerSelector, eventCallback func(types.Event)) {
+	addNetns := func(container *containercollection.Container) {
+		if err := t.tracer.AddNetns(container.Netns); err != nil {
+			msg := fmt.Sprintf("start tracing container %q: %s", container.Name, err)
+			eventCallback(types.Base(eventtypes.Err(msg)))
+		}
+	}
+	removeNetns := func(container *containercollection.Container) {
+		if err := t.tracer.RemoveNetns(container.Netns); err != nil {
+			msg := fmt.Sprintf("stop tracing container %q: %s", container.Name, err)
+			eventCallback(types.Base(eventtypes.Err(msg)))
+		}
+	}
+
+	containers := t.helpers.Subscribe(
+		t.subKey,
+		selector,
+		func(event containercollection.PubSubEvent) {
+			switch event.Type {
+			case containercollection.EventTypeAddContainer:
+				addNetns(event.Container)
+			case containercollection.EventTypeRemoveContainer:
+				removeNetns(event.Container)
+			}
+		},
+	)
+
+	for _, container := range containers {
+		addNetns(container)
+	}
+}
+
+func (t *Trace) stop() {
+	if t.subKey != "" {
+		t.helpers.Unsubscribe(t.subKey)
+		t.subKey = ""
+	}
+	t.tracer.Stop()
+	t.tracer = nil
+	t.started = false
+}
+
+func (t *Trace) Stop(trace *gadgetv1alpha1.Trace) {
+	if !t.started {
+		trace.Status.OperationError = "Not started"
+		return
+	}
+
+	t.stop()
+
+	trace.Status.State = gadgetv1alpha1.TraceStateStopped
+}
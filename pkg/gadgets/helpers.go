@@ -47,6 +47,27 @@ type DataEnricher interface {
 	Enrich(event *types.CommonData, mountnsid uint64)
 }
 
+// DataEnricherByNetNs is like DataEnricher but it uses the network namespace
+// to find the container.
+type DataEnricherByNetNs interface {
+	EnrichByNetNs(event *types.CommonData, netnsid uint64)
+}
+
+// DataEnricherByCgroupID is like DataEnricher but it uses the cgroup v2 ID
+// to find the container. It returns false if no container has this cgroup,
+// callers then fall back to DataEnricher with the mount namespace. It's only
+// meaningful on cgroup v2 hosts, see cgroups.IsCgroupV2().
+type DataEnricherByCgroupID interface {
+	EnrichByCgroupID(event *types.CommonData, cgroupid uint64) bool
+}
+
+// ContainerUIDLookup is implemented by the data enrichers that know the user
+// ID the processes of a container are started with. It returns false if the
+// container or its user ID is unknown.
+type ContainerUIDLookup interface {
+	LookupContainerUIDByMntns(mntnsid uint64) (uint32, bool)
+}
+
 func FromCString(in []byte) string {
 	for i := 0; i < len(in); i++ {
 		if in[i] == 0 {
@@ -0,0 +1,97 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kallsyms resolves kernel instruction pointers, e.g. the ones of a
+// kernel stack, to the names of the kernel symbols using /proc/kallsyms.
+package kallsyms
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type kernelSymbol struct {
+	addr uint64
+	name string
+}
+
+// KAllSyms holds the kernel symbols sorted by address.
+type KAllSyms struct {
+	symbols []kernelSymbol
+}
+
+// NewKAllSyms reads the kernel symbols from /proc/kallsyms.
+func NewKAllSyms() (*KAllSyms, error) {
+	file, err := os.Open("/proc/kallsyms")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return newKAllSymsFromReader(file)
+}
+
+func newKAllSymsFromReader(reader io.Reader) (*KAllSyms, error) {
+	symbols := []kernelSymbol{}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		// The kernel function is the third field in /proc/kallsyms line:
+		// 0000000000000000 t acpi_video_unregister_backlight      [video]
+		// First is the symbol address and second is described in man nm.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		addr, err := strconv.ParseUint(fields[0], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		symbols = append(symbols, kernelSymbol{
+			addr: addr,
+			name: fields[2],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return symbols[i].addr < symbols[j].addr
+	})
+
+	return &KAllSyms{symbols: symbols}, nil
+}
+
+// LookupByInstructionPointer returns the name of the kernel symbol containing
+// the given instruction pointer. For example, if the instruction pointer is
+// 0x1004 and there is a symbol whose address is 0x1000, it returns the name of
+// this symbol. If no symbol is found, it returns "[unknown]".
+func (k *KAllSyms) LookupByInstructionPointer(ip uint64) string {
+	i := sort.Search(len(k.symbols), func(i int) bool {
+		return k.symbols[i].addr > ip
+	})
+	// Addresses are zero when kptr_restrict hides them
+	if i == 0 || k.symbols[i-1].addr == 0 {
+		return "[unknown]"
+	}
+
+	return k.symbols[i-1].name
+}
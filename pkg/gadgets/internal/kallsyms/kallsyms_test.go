@@ -0,0 +1,57 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kallsyms
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupByInstructionPointer(t *testing.T) {
+	kAllSyms, err := newKAllSymsFromReader(strings.NewReader(`ffffffff81000000 T _stext
+ffffffff81002000 t tcp_v4_rcv
+ffffffff81001000 T do_syscall_64
+ffffffffc0a01000 t nf_hook_slow	[nf_tables]
+`))
+	if err != nil {
+		t.Fatalf("reading symbols: %s", err)
+	}
+
+	for ip, expected := range map[uint64]string{
+		0x1000:             "[unknown]",
+		0xffffffff81000000: "_stext",
+		0xffffffff81001004: "do_syscall_64",
+		0xffffffff81002fff: "tcp_v4_rcv",
+		0xffffffffc0a01010: "nf_hook_slow",
+	} {
+		if actual := kAllSyms.LookupByInstructionPointer(ip); actual != expected {
+			t.Errorf("LookupByInstructionPointer(%#x): expected %q, got %q", ip, expected, actual)
+		}
+	}
+}
+
+func TestLookupByInstructionPointerRestricted(t *testing.T) {
+	// Without CAP_SYSLOG, the addresses are all zero
+	kAllSyms, err := newKAllSymsFromReader(strings.NewReader(`0000000000000000 T _stext
+0000000000000000 t tcp_v4_rcv
+`))
+	if err != nil {
+		t.Fatalf("reading symbols: %s", err)
+	}
+
+	if actual := kAllSyms.LookupByInstructionPointer(0xffffffff81001004); actual != "[unknown]" {
+		t.Errorf("expected [unknown], got %q", actual)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networktracer
+
+import (
+	"encoding/binary"
+)
+
+// TCPSegment is the payload of a TCP segment captured by a socket filter.
+type TCPSegment struct {
+	Payload []byte
+
+	// Truncated is true if the payload was truncated by the eBPF program
+	Truncated bool
+}
+
+// ParseTCPSegment returns the TCP payload of a packet captured with its
+// Ethernet header.
+func ParseTCPSegment(packet []byte) (TCPSegment, bool) {
+	const ipOff = 14
+
+	if len(packet) < ipOff+20 {
+		return TCPSegment{}, false
+	}
+	ihl := int(packet[ipOff]&0x0F) * 4
+	ipEnd := ipOff + int(binary.BigEndian.Uint16(packet[ipOff+2:]))
+
+	tcpOff := ipOff + ihl
+	if tcpOff+20 > len(packet) || tcpOff+20 > ipEnd {
+		return TCPSegment{}, false
+	}
+	payloadOff := tcpOff + int(packet[tcpOff+12]>>4)*4
+	if payloadOff > len(packet) || payloadOff > ipEnd {
+		return TCPSegment{}, false
+	}
+
+	// The capture can be truncated, or have padding after the IP packet
+	if ipEnd > len(packet) {
+		return TCPSegment{Payload: packet[payloadOff:], Truncated: true}, true
+	}
+	return TCPSegment{Payload: packet[payloadOff:ipEnd]}, true
+}
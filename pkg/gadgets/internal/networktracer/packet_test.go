@@ -0,0 +1,41 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networktracer
+
+import (
+	"testing"
+)
+
+func TestParseTCPSegment(t *testing.T) {
+	packet := make([]byte, 14+20+20+4)
+	packet[14] = 0x45                       // IPv4, IHL 5
+	packet[14+2], packet[14+3] = 0, 20+20+2 // total length
+	packet[14+20+12] = 5 << 4               // TCP data offset
+	copy(packet[14+20+20:], "ok")           // payload, followed by padding
+
+	seg, ok := ParseTCPSegment(packet)
+	if !ok || seg.Truncated || string(seg.Payload) != "ok" {
+		t.Fatalf("Got unexpected segment %+v", seg)
+	}
+
+	seg, ok = ParseTCPSegment(packet[:14+20+20+1])
+	if !ok || !seg.Truncated || string(seg.Payload) != "o" {
+		t.Fatalf("Got unexpected truncated segment %+v", seg)
+	}
+
+	if seg, ok = ParseTCPSegment(packet[:14+20+10]); ok {
+		t.Fatalf("Got unexpected segment from a truncated header %+v", seg)
+	}
+}
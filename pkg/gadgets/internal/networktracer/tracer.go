@@ -107,8 +107,10 @@ type Tracer[Event any] struct {
 	bpfPerfMapName  string
 	bpfSocketAttach int
 
-	baseEvent  func(ev types.Event) Event
-	parseEvent func([]byte) (*Event, error)
+	baseEvent func(ev types.Event) Event
+	// parseEvent gets the network namespace the sample was captured in,
+	// for the parsers keeping state across samples.
+	parseEvent func(netns uint64, sample []byte) ([]*Event, error)
 }
 
 func NewTracer[Event any](
@@ -117,7 +119,7 @@ func NewTracer[Event any](
 	bpfPerfMapName string,
 	bpfSocketAttach int,
 	baseEvent func(ev types.Event) Event,
-	parseEvent func([]byte) (*Event, error),
+	parseEvent func(netns uint64, sample []byte) ([]*Event, error),
 ) *Tracer[Event] {
 	return &Tracer[Event]{
 		spec:            spec,
@@ -155,7 +157,7 @@ func (t *Tracer[Event]) listen(
 	netns uint64,
 	rd *perf.Reader,
 	baseEvent func(ev types.Event) Event,
-	parseEvent func([]byte) (*Event, error),
+	parseEvent func(netns uint64, sample []byte) ([]*Event, error),
 	eventCallback func(Event),
 ) {
 	for {
@@ -176,15 +178,16 @@ func (t *Tracer[Event]) listen(
 			continue
 		}
 
-		event, err := parseEvent(record.RawSample)
+		// A sample can contain zero, one or several events, e.g. when
+		// the eBPF program sends data that needs to be reassembled.
+		events, err := parseEvent(netns, record.RawSample)
 		if err != nil {
 			eventCallback(baseEvent(types.Err(err.Error())))
 			continue
 		}
-		if event == nil {
-			continue
+		for _, event := range events {
+			eventCallback(*event)
 		}
-		eventCallback(*event)
 	}
 }
 
@@ -20,12 +20,20 @@ type bindsnoopBindEvent struct {
 	Pid        uint32
 	BoundDevIf uint32
 	Ret        int32
+	Backlog    uint32
 	Port       uint16
 	Proto      uint16
 	Opts       uint8
 	Ver        uint8
+	Op         uint8
 	Task       [16]uint8
-	_          [6]byte
+	_          [1]byte
+}
+
+type bindsnoopListenArgs struct {
+	Socket  uint64
+	Backlog int32
+	_       [4]byte
 }
 
 // loadBindsnoop returns the embedded CollectionSpec for bindsnoop.
@@ -69,10 +77,13 @@ type bindsnoopSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bindsnoopProgramSpecs struct {
-	IgBindIpv4E *ebpf.ProgramSpec `ebpf:"ig_bind_ipv4_e"`
-	IgBindIpv4X *ebpf.ProgramSpec `ebpf:"ig_bind_ipv4_x"`
-	IgBindIpv6E *ebpf.ProgramSpec `ebpf:"ig_bind_ipv6_e"`
-	IgBindIpv6X *ebpf.ProgramSpec `ebpf:"ig_bind_ipv6_x"`
+	IgBindIpv4E  *ebpf.ProgramSpec `ebpf:"ig_bind_ipv4_e"`
+	IgBindIpv4X  *ebpf.ProgramSpec `ebpf:"ig_bind_ipv4_x"`
+	IgBindIpv6E  *ebpf.ProgramSpec `ebpf:"ig_bind_ipv6_e"`
+	IgBindIpv6X  *ebpf.ProgramSpec `ebpf:"ig_bind_ipv6_x"`
+	IgListenE    *ebpf.ProgramSpec `ebpf:"ig_listen_e"`
+	IgListenStop *ebpf.ProgramSpec `ebpf:"ig_listen_stop"`
+	IgListenX    *ebpf.ProgramSpec `ebpf:"ig_listen_x"`
 }
 
 // bindsnoopMapSpecs contains maps before they are loaded into the kernel.
@@ -80,6 +91,8 @@ type bindsnoopProgramSpecs struct {
 // It can be passed ebpf.CollectionSpec.Assign.
 type bindsnoopMapSpecs struct {
 	Events        *ebpf.MapSpec `ebpf:"events"`
+	ListenArgs    *ebpf.MapSpec `ebpf:"listen_args"`
+	Listeners     *ebpf.MapSpec `ebpf:"listeners"`
 	MountNsFilter *ebpf.MapSpec `ebpf:"mount_ns_filter"`
 	Ports         *ebpf.MapSpec `ebpf:"ports"`
 	Sockets       *ebpf.MapSpec `ebpf:"sockets"`
@@ -105,6 +118,8 @@ func (o *bindsnoopObjects) Close() error {
 // It can be passed to loadBindsnoopObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bindsnoopMaps struct {
 	Events        *ebpf.Map `ebpf:"events"`
+	ListenArgs    *ebpf.Map `ebpf:"listen_args"`
+	Listeners     *ebpf.Map `ebpf:"listeners"`
 	MountNsFilter *ebpf.Map `ebpf:"mount_ns_filter"`
 	Ports         *ebpf.Map `ebpf:"ports"`
 	Sockets       *ebpf.Map `ebpf:"sockets"`
@@ -113,6 +128,8 @@ type bindsnoopMaps struct {
 func (m *bindsnoopMaps) Close() error {
 	return _BindsnoopClose(
 		m.Events,
+		m.ListenArgs,
+		m.Listeners,
 		m.MountNsFilter,
 		m.Ports,
 		m.Sockets,
@@ -123,10 +140,13 @@ func (m *bindsnoopMaps) Close() error {
 //
 // It can be passed to loadBindsnoopObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bindsnoopPrograms struct {
-	IgBindIpv4E *ebpf.Program `ebpf:"ig_bind_ipv4_e"`
-	IgBindIpv4X *ebpf.Program `ebpf:"ig_bind_ipv4_x"`
-	IgBindIpv6E *ebpf.Program `ebpf:"ig_bind_ipv6_e"`
-	IgBindIpv6X *ebpf.Program `ebpf:"ig_bind_ipv6_x"`
+	IgBindIpv4E  *ebpf.Program `ebpf:"ig_bind_ipv4_e"`
+	IgBindIpv4X  *ebpf.Program `ebpf:"ig_bind_ipv4_x"`
+	IgBindIpv6E  *ebpf.Program `ebpf:"ig_bind_ipv6_e"`
+	IgBindIpv6X  *ebpf.Program `ebpf:"ig_bind_ipv6_x"`
+	IgListenE    *ebpf.Program `ebpf:"ig_listen_e"`
+	IgListenStop *ebpf.Program `ebpf:"ig_listen_stop"`
+	IgListenX    *ebpf.Program `ebpf:"ig_listen_x"`
 }
 
 func (p *bindsnoopPrograms) Close() error {
@@ -135,6 +155,9 @@ func (p *bindsnoopPrograms) Close() error {
 		p.IgBindIpv4X,
 		p.IgBindIpv6E,
 		p.IgBindIpv6X,
+		p.IgListenE,
+		p.IgListenStop,
+		p.IgListenX,
 	)
 }
 
@@ -148,5 +171,6 @@ func _BindsnoopClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed bindsnoop_bpfel_arm64.o
 var _BindsnoopBytes []byte
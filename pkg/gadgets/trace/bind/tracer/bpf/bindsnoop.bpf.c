@@ -10,6 +10,8 @@
 #define MAX_ENTRIES	10240
 #define MAX_PORTS	1024
 
+#define AF_INET		2
+
 const volatile pid_t target_pid = 0;
 const volatile bool ignore_errors = true;
 const volatile bool filter_by_port = false;
@@ -25,6 +27,30 @@ struct {
 	__type(value, struct socket *);
 } sockets SEC(".maps");
 
+// The socket is kept as an integer: bpf2go can't generate Go types for
+// pointers.
+struct listen_args {
+	__u64 socket;
+	int backlog;
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, __u32);
+	__type(value, struct listen_args);
+} listen_args SEC(".maps");
+
+// listeners keeps the mount namespace of the processes that started
+// listening on the sockets, as it's gone when the sockets are closed at
+// the exit of the processes.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, struct sock *);
+	__type(value, u64);
+} listeners SEC(".maps");
+
 struct {
 	__uint(type, BPF_MAP_TYPE_HASH);
 	__uint(max_entries, MAX_PORTS);
@@ -45,6 +71,44 @@ struct {
 	__uint(value_size, sizeof(u32));
 } mount_ns_filter SEC(".maps");
 
+// fill_event fills the fields of the event read from the socket, it returns
+// false if the port isn't traced.
+static __always_inline bool
+fill_event(struct bind_event *event, struct sock *sock, __u8 ver)
+{
+	struct inet_sock *inet_sock = (struct inet_sock *)sock;
+	union bind_options opts;
+	__u16 sport;
+
+	sport = bpf_ntohs(BPF_CORE_READ(inet_sock, inet_sport));
+	if (filter_by_port && !bpf_map_lookup_elem(&ports, &sport))
+		return false;
+
+	opts.fields.freebind             = BPF_CORE_READ_BITFIELD_PROBED(inet_sock, freebind);
+	opts.fields.transparent          = BPF_CORE_READ_BITFIELD_PROBED(inet_sock, transparent);
+	opts.fields.bind_address_no_port = BPF_CORE_READ_BITFIELD_PROBED(inet_sock, bind_address_no_port);
+	opts.fields.reuseaddress         = BPF_CORE_READ_BITFIELD_PROBED(sock, __sk_common.skc_reuse);
+	opts.fields.reuseport            = BPF_CORE_READ_BITFIELD_PROBED(sock, __sk_common.skc_reuseport);
+	event->opts = opts.data;
+	event->ts_us = bpf_ktime_get_ns() / 1000;
+	event->port = sport;
+	event->bound_dev_if = BPF_CORE_READ(sock, __sk_common.skc_bound_dev_if);
+	event->proto = BPF_CORE_READ_BITFIELD_PROBED(sock, sk_protocol);
+	event->ver = ver;
+	bpf_get_current_comm(&event->task, sizeof(event->task));
+	if (ver == 4)
+		bpf_probe_read_kernel(&event->addr, sizeof(event->addr), &inet_sock->inet_saddr);
+	else /* ver == 6 */
+		bpf_probe_read_kernel(&event->addr, sizeof(event->addr), sock->__sk_common.skc_v6_rcv_saddr.in6_u.u6_addr32);
+
+	return true;
+}
+
+static __always_inline __u8 sock_ver(struct sock *sock)
+{
+	return BPF_CORE_READ(sock, __sk_common.skc_family) == AF_INET ? 4 : 6;
+}
+
 static int probe_entry(struct pt_regs *ctx, struct socket *socket)
 {
 	__u64 pid_tgid = bpf_get_current_pid_tgid();
@@ -66,11 +130,8 @@ static int probe_exit(struct pt_regs *ctx, short ver)
 	u64 mntns_id;
 	struct task_struct *task;
 	struct socket **socketp, *socket;
-	struct inet_sock *inet_sock;
 	struct sock *sock;
-	union bind_options opts;
 	struct bind_event event = {};
-	__u16 sport = 0, *port;
 	int ret;
 
 	socketp = bpf_map_lookup_elem(&sockets, &tid);
@@ -89,34 +150,14 @@ static int probe_exit(struct pt_regs *ctx, short ver)
 
 	socket = *socketp;
 	sock = BPF_CORE_READ(socket, sk);
-	inet_sock = (struct inet_sock *)sock;
 
-	sport = bpf_ntohs(BPF_CORE_READ(inet_sock, inet_sport));
-	port = bpf_map_lookup_elem(&ports, &sport);
-	if (filter_by_port && !port)
+	if (!fill_event(&event, sock, ver))
 		goto cleanup;
 
-	opts.fields.freebind             = BPF_CORE_READ_BITFIELD_PROBED(inet_sock, freebind);
-	opts.fields.transparent          = BPF_CORE_READ_BITFIELD_PROBED(inet_sock, transparent);
-	opts.fields.bind_address_no_port = BPF_CORE_READ_BITFIELD_PROBED(inet_sock, bind_address_no_port);
-	opts.fields.reuseaddress         = BPF_CORE_READ_BITFIELD_PROBED(sock, __sk_common.skc_reuse);
-	opts.fields.reuseport            = BPF_CORE_READ_BITFIELD_PROBED(sock, __sk_common.skc_reuseport);
-	event.opts = opts.data;
-	event.ts_us = bpf_ktime_get_ns() / 1000;
 	event.pid = pid;
-	event.port = sport;
-	event.bound_dev_if = BPF_CORE_READ(sock, __sk_common.skc_bound_dev_if);
 	event.ret = ret;
-	event.proto = BPF_CORE_READ_BITFIELD_PROBED(sock, sk_protocol);
+	event.op = BIND_OP_BIND;
 	event.mount_ns_id = mntns_id;
-	bpf_get_current_comm(&event.task, sizeof(event.task));
-	if (ver == 4) {
-		event.ver = ver;
-		bpf_probe_read_kernel(&event.addr, sizeof(event.addr), &inet_sock->inet_saddr);
-	} else { /* ver == 6 */
-		event.ver = ver;
-		bpf_probe_read_kernel(&event.addr, sizeof(event.addr), sock->__sk_common.skc_v6_rcv_saddr.in6_u.u6_addr32);
-	}
 	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
 
 cleanup:
@@ -148,4 +189,97 @@ int BPF_KRETPROBE(ig_bind_ipv6_x)
 	return probe_exit(ctx, 6);
 }
 
+SEC("kprobe/inet_listen")
+int BPF_KPROBE(ig_listen_e, struct socket *socket, int backlog)
+{
+	__u64 pid_tgid = bpf_get_current_pid_tgid();
+	__u32 pid = pid_tgid >> 32;
+	__u32 tid = (__u32)pid_tgid;
+	struct listen_args args = {};
+
+	if (target_pid && target_pid != pid)
+		return 0;
+
+	args.socket = (__u64)socket;
+	args.backlog = backlog;
+	bpf_map_update_elem(&listen_args, &tid, &args, BPF_ANY);
+	return 0;
+}
+
+SEC("kretprobe/inet_listen")
+int BPF_KRETPROBE(ig_listen_x)
+{
+	__u64 pid_tgid = bpf_get_current_pid_tgid();
+	__u32 pid = pid_tgid >> 32;
+	__u32 tid = (__u32)pid_tgid;
+	struct bind_event event = {};
+	struct listen_args *args;
+	struct task_struct *task;
+	struct socket *socket;
+	struct sock *sock;
+	u64 mntns_id;
+	int ret;
+
+	args = bpf_map_lookup_elem(&listen_args, &tid);
+	if (!args)
+		return 0;
+
+	task = (struct task_struct*) bpf_get_current_task();
+	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+
+	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+		goto cleanup;
+
+	ret = PT_REGS_RC(ctx);
+	if (ignore_errors && ret != 0)
+		goto cleanup;
+
+	socket = (struct socket *)args->socket;
+	sock = BPF_CORE_READ(socket, sk);
+
+	if (!fill_event(&event, sock, sock_ver(sock)))
+		goto cleanup;
+
+	event.pid = pid;
+	event.ret = ret;
+	event.backlog = args->backlog;
+	event.op = BIND_OP_LISTEN;
+	event.mount_ns_id = mntns_id;
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
+
+	if (ret == 0)
+		bpf_map_update_elem(&listeners, &sock, &mntns_id, BPF_ANY);
+
+cleanup:
+	bpf_map_delete_elem(&listen_args, &tid);
+	return 0;
+}
+
+// inet_csk_listen_stop() is called when a listening socket is closed, or
+// shut down. Only the sockets seen starting to listen are reported, as the
+// mount namespace of the process could already be gone.
+SEC("kprobe/inet_csk_listen_stop")
+int BPF_KPROBE(ig_listen_stop, struct sock *sock)
+{
+	__u64 pid_tgid = bpf_get_current_pid_tgid();
+	struct bind_event event = {};
+	u64 *mntns_id;
+
+	mntns_id = bpf_map_lookup_elem(&listeners, &sock);
+	if (!mntns_id)
+		return 0;
+
+	if (!fill_event(&event, sock, sock_ver(sock)))
+		goto cleanup;
+
+	event.pid = pid_tgid >> 32;
+	event.op = BIND_OP_CLOSE;
+	event.mount_ns_id = *mntns_id;
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
+
+cleanup:
+	bpf_map_delete_elem(&listeners, &sock);
+	return 0;
+}
+
 char LICENSE[] SEC("license") = "Dual BSD/GPL";
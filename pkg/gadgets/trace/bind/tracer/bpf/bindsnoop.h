@@ -4,6 +4,12 @@
 
 #define TASK_COMM_LEN	16
 
+enum bind_op {
+	BIND_OP_BIND,
+	BIND_OP_LISTEN,
+	BIND_OP_CLOSE,
+};
+
 struct bind_event {
     __u8 addr[16];
 	__u64 mount_ns_id;
@@ -11,10 +17,12 @@ struct bind_event {
 	__u32 pid;
 	__u32 bound_dev_if;
 	int ret;
+	__u32 backlog;
 	__u16 port;
 	__u16 proto;
 	__u8 opts;
 	__u8 ver;
+	__u8 op;
 	__u8 task[TASK_COMM_LEN];
 };
 
@@ -40,6 +40,9 @@ type Config struct {
 	TargetPid    int32
 	TargetPorts  []uint16
 	IgnoreErrors bool
+	// Listen also reports the calls to listen() and the close of the
+	// sockets that were listening.
+	Listen bool
 }
 
 type Tracer struct {
@@ -47,12 +50,15 @@ type Tracer struct {
 	enricher      gadgets.DataEnricher
 	eventCallback func(types.Event)
 
-	objs      bindsnoopObjects
-	ipv4Entry link.Link
-	ipv4Exit  link.Link
-	ipv6Entry link.Link
-	ipv6Exit  link.Link
-	reader    *perf.Reader
+	objs        bindsnoopObjects
+	ipv4Entry   link.Link
+	ipv4Exit    link.Link
+	ipv6Entry   link.Link
+	ipv6Exit    link.Link
+	listenEntry link.Link
+	listenExit  link.Link
+	listenStop  link.Link
+	reader      *perf.Reader
 }
 
 func NewTracer(config *Config, enricher gadgets.DataEnricher,
@@ -77,6 +83,9 @@ func (t *Tracer) Stop() {
 	t.ipv4Exit = gadgets.CloseLink(t.ipv4Exit)
 	t.ipv6Entry = gadgets.CloseLink(t.ipv6Entry)
 	t.ipv6Exit = gadgets.CloseLink(t.ipv6Exit)
+	t.listenEntry = gadgets.CloseLink(t.listenEntry)
+	t.listenExit = gadgets.CloseLink(t.listenExit)
+	t.listenStop = gadgets.CloseLink(t.listenStop)
 
 	if t.reader != nil {
 		t.reader.Close()
@@ -148,6 +157,23 @@ func (t *Tracer) start() error {
 		return fmt.Errorf("error opening ipv6 kprobe: %w", err)
 	}
 
+	if t.config.Listen {
+		t.listenEntry, err = link.Kprobe("inet_listen", t.objs.IgListenE, nil)
+		if err != nil {
+			return fmt.Errorf("error opening listen kprobe: %w", err)
+		}
+
+		t.listenExit, err = link.Kretprobe("inet_listen", t.objs.IgListenX, nil)
+		if err != nil {
+			return fmt.Errorf("error opening listen kprobe: %w", err)
+		}
+
+		t.listenStop, err = link.Kprobe("inet_csk_listen_stop", t.objs.IgListenStop, nil)
+		if err != nil {
+			return fmt.Errorf("error opening listen stop kprobe: %w", err)
+		}
+	}
+
 	t.reader, err = perf.NewReader(t.objs.bindsnoopMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
 	if err != nil {
 		return fmt.Errorf("error creating perf ring buffer: %w", err)
@@ -178,6 +204,13 @@ func optionsToString(options uint8) string {
 	return ret
 }
 
+// operations are the names of the values of enum bind_op
+var operations = map[uint8]string{
+	0: "bind",
+	1: "listen",
+	2: "close",
+}
+
 // Taken from:
 // https://elixir.bootlin.com/linux/v5.16.10/source/include/uapi/linux/in.h#L28
 var socketProtocol = map[uint16]string{
@@ -267,6 +300,8 @@ func (t *Tracer) run() {
 				Type: eventtypes.NORMAL,
 			},
 			Pid:       bpfEvent.Pid,
+			Operation: operations[bpfEvent.Op],
+			Backlog:   bpfEvent.Backlog,
 			Protocol:  protocolToString(bpfEvent.Proto),
 			Addr:      addr,
 			Port:      bpfEvent.Port,
@@ -87,6 +87,7 @@ func TestBindTracer(t *testing.T) {
 					},
 					Pid:       uint32(info.Pid),
 					Comm:      info.Comm,
+					Operation: "bind",
 					Protocol:  "TCP",
 					Addr:      "127.0.0.1",
 					Port:      port,
@@ -119,6 +120,7 @@ func TestBindTracer(t *testing.T) {
 					},
 					Pid:       uint32(info.Pid),
 					Comm:      info.Comm,
+					Operation: "bind",
 					Protocol:  "TCP",
 					Addr:      "127.0.0.1",
 					Port:      port,
@@ -304,6 +306,53 @@ func TestBindTracer(t *testing.T) {
 				}
 			},
 		},
+		"listen": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+					Listen:     true,
+				}
+			},
+			generateEvent: listenSocket,
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, port uint16, events []types.Event) {
+				if len(events) != 3 {
+					t.Fatalf("Wrong number of events received %d, expected 3", len(events))
+				}
+
+				// Order of events is not guaranteed
+				byOperation := map[string]types.Event{}
+				for _, event := range events {
+					byOperation[event.Operation] = event
+				}
+
+				for _, operation := range []string{"bind", "listen", "close"} {
+					event, ok := byOperation[operation]
+					if !ok {
+						t.Fatalf("No %s event captured", operation)
+					}
+
+					utilstest.Equal(t, port, event.Port, "Captured event has bad Port")
+					utilstest.Equal(t, info.MountNsID, event.MountNsID, "Captured event has bad MountNsID")
+				}
+
+				utilstest.Equal(t, uint32(listenBacklog), byOperation["listen"].Backlog, "Captured event has bad Backlog")
+			},
+		},
+		"listen_disabled": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: listenSocket,
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, port uint16, events []types.Event) {
+				if len(events) != 1 {
+					t.Fatalf("Wrong number of events received %d, expected 1", len(events))
+				}
+
+				utilstest.Equal(t, "bind", events[0].Operation, "Captured event has bad Operation")
+			},
+		},
 		"ignore_errors_true": {
 			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
 				return &tracer.Config{
@@ -645,3 +694,29 @@ func bindSocketError() (uint16, error) {
 
 	return 0, nil
 }
+
+const listenBacklog = 42
+
+// listenSocket creates a socket, binds it, listens on it and closes it.
+func listenSocket() (uint16, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Bind(fd, &unix.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		return 0, fmt.Errorf("Bind: %w", err)
+	}
+
+	if err := unix.Listen(fd, listenBacklog); err != nil {
+		return 0, fmt.Errorf("Listen: %w", err)
+	}
+
+	sa, err := unix.Getsockname(fd)
+	if err != nil {
+		return 0, fmt.Errorf("Getsockname: %w", err)
+	}
+
+	return uint16(sa.(*unix.SockaddrInet4).Port), nil
+}
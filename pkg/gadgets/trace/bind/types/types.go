@@ -24,11 +24,13 @@ type Event struct {
 
 	Pid       uint32 `json:"pid,omitempty" column:"pid,template:pid"`
 	Comm      string `json:"comm,omitempty" column:"comm,template:comm"`
+	Operation string `json:"operation,omitempty" column:"op,width:6,fixed,hide"`
 	Protocol  string `json:"proto,omitempty" column:"proto,width:5,fixed"`
 	Addr      string `json:"addr,omitempty" column:"addr,template:ipaddr"`
 	Port      uint16 `json:"port,omitempty" column:"port,template:ipport"`
 	Options   string `json:"opts,omitempty" column:"opts,width:5,fixed"`
 	Interface string `json:"if,omitempty" column:"if,width:12"`
+	Backlog   uint32 `json:"backlog,omitempty" column:"backlog,width:7,align:right,hide"`
 	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
 }
 
@@ -36,6 +38,19 @@ func GetColumns() *columns.Columns[Event] {
 	return columns.MustCreateColumns[Event]()
 }
 
+// GetListenColumns returns the columns used when the calls to listen() and
+// the close of the listening sockets are traced too.
+func GetListenColumns() *columns.Columns[Event] {
+	bindColumns := GetColumns()
+
+	for _, name := range []string{"op", "backlog"} {
+		col, _ := bindColumns.GetColumn(name)
+		col.Visible = true
+	}
+
+	return bindColumns
+}
+
 func Base(ev eventtypes.Event) Event {
 	return Event{
 		Event: ev,
@@ -16,6 +16,11 @@
 #define CAP_OPT_NOAUDIT 1 << 1
 #endif
 
+#define TS_COMPAT 0x0002
+
+// include/linux/sched.h
+#define PF_KTHREAD 0x00200000
+
 
 #define MAX_ENTRIES	10240
 
@@ -25,6 +30,10 @@ const volatile bool filter_by_mnt_ns = false;
 const volatile u32 linux_version_code = 0;
 const volatile bool audit_only = false;
 const volatile bool unique = false;
+// print_stack collects the syscall and the kernel stack of the checks. Each
+// syscall, capability and verdict is then only reported once per mount
+// namespace, the times it's seen are counted in seen_syscalls.
+const volatile bool print_stack = false;
 
 // we need this to make sure the compiler doesn't remove our struct
 const struct cap_event *unusedcapevent __attribute__((unused));
@@ -32,6 +41,8 @@ const struct cap_event *unusedcapevent __attribute__((unused));
 struct args_t {
 	int cap;
 	int cap_opt;
+	int syscall;
+	int kernel_stack_id;
 };
 
 struct {
@@ -66,6 +77,83 @@ struct {
 	__uint(value_size, sizeof(u32));
 } mount_ns_filter SEC(".maps");
 
+struct {
+	__uint(type, BPF_MAP_TYPE_STACK_TRACE);
+	__type(key, u32);
+	__uint(max_entries, 1024);
+	__uint(value_size, MAX_STACK_DEPTH * sizeof(u64));
+} stackmap SEC(".maps");
+
+struct syscall_key {
+	u64 mntns_id;
+	int syscall;
+	int cap;
+	int denied;
+};
+
+// seen_syscalls counts the times each combination was seen. An LRU map is
+// used so that the new combinations are still reported when it's full, at the
+// price of reporting the oldest ones again.
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, struct syscall_key);
+	__type(value, u64);
+} seen_syscalls SEC(".maps");
+
+#ifdef __TARGET_ARCH_x86
+static __always_inline int is_x86_compat(struct task_struct *task)
+{
+	return !!(BPF_CORE_READ(task, thread_info.status) & TS_COMPAT);
+}
+#endif
+
+// current_syscall returns the syscall being run by the current task, or -1
+// if it's unknown. It's syscall_get_nr() of the kernel, for the architectures
+// we support. bpf_task_pt_regs() needs the BTF pointer of the task.
+static __always_inline int current_syscall(void)
+{
+	struct task_struct *task = bpf_get_current_task_btf();
+	struct pt_regs *regs;
+
+	if (BPF_CORE_READ(task, flags) & PF_KTHREAD)
+		return -1;
+
+#ifdef __TARGET_ARCH_x86
+	// The numbers of the 32 bits syscalls are different.
+	if (is_x86_compat(task))
+		return -1;
+#endif
+
+	regs = (struct pt_regs *) bpf_task_pt_regs(task);
+#if defined(__TARGET_ARCH_arm64)
+	return BPF_CORE_READ(regs, syscallno);
+#elif defined(__TARGET_ARCH_x86)
+	return BPF_CORE_READ(regs, orig_ax);
+#else
+	return -1;
+#endif
+}
+
+// syscall_seen returns whether the syscall and capability were already reported for
+// this mount namespace, with any verdict.
+static __always_inline bool syscall_seen(u64 mntns_id, int syscall, int cap)
+{
+	struct syscall_key key;
+
+	__builtin_memset(&key, 0, sizeof(key));
+	key.mntns_id = mntns_id;
+	key.syscall = syscall;
+	key.cap = cap;
+
+	key.denied = 0;
+	if (bpf_map_lookup_elem(&seen_syscalls, &key))
+		return true;
+
+	key.denied = 1;
+	return bpf_map_lookup_elem(&seen_syscalls, &key) != NULL;
+}
+
 SEC("kprobe/cap_capable")
 int BPF_KPROBE(ig_trace_cap_e, const struct cred *cred, struct user_namespace *targ_ns, int cap, int cap_opt)
 {
@@ -116,6 +204,19 @@ int BPF_KPROBE(ig_trace_cap_e, const struct cred *cred, struct user_namespace *t
 	struct args_t args = {};
 	args.cap = cap;
 	args.cap_opt = cap_opt;
+	args.syscall = -1;
+	args.kernel_stack_id = -1;
+
+	if (print_stack) {
+		args.syscall = current_syscall();
+		// Walking the stack is costly: it's only done the first
+		// time the syscall and capability are seen. The verdict
+		// isn't known yet, so the stack isn't collected again when
+		// a combination is seen with another verdict.
+		if (!syscall_seen(mntns_id, args.syscall, cap))
+			args.kernel_stack_id = bpf_get_stackid(ctx, &stackmap, 0);
+	}
+
 	bpf_map_update_elem(&start, &pid_tgid, &args, 0);
 
 	return 0;
@@ -139,6 +240,30 @@ int BPF_KRETPROBE(ig_trace_cap_x)
 
 	task = (struct task_struct*) bpf_get_current_task();
 	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+	ret = PT_REGS_RC(ctx);
+
+	if (print_stack) {
+		struct syscall_key key;
+		u64 one = 1, *count;
+
+		__builtin_memset(&key, 0, sizeof(key));
+		key.mntns_id = mntns_id;
+		key.syscall = ap->syscall;
+		key.cap = ap->cap;
+		key.denied = ret != 0;
+
+		// The combination was already reported, only count it. The
+		// stack map can't be written to from eBPF programs, so the
+		// stack collected when two tasks see the combination for the
+		// first time at once stays there, but that's bounded by the
+		// number of combinations.
+		if (bpf_map_update_elem(&seen_syscalls, &key, &one, BPF_NOEXIST)) {
+			count = bpf_map_lookup_elem(&seen_syscalls, &key);
+			if (count)
+				__sync_fetch_and_add(count, 1);
+			return 0;
+		}
+	}
 
 	struct cap_event event = {};
 	event.pid = pid_tgid >> 32;
@@ -148,7 +273,9 @@ int BPF_KRETPROBE(ig_trace_cap_x)
 	event.mntnsid = mntns_id;
 	event.cap_opt = ap->cap_opt;
 	bpf_get_current_comm(&event.task, sizeof(event.task));
-	event.ret = PT_REGS_RC(ctx);
+	event.ret = ret;
+	event.syscall = ap->syscall;
+	event.kernel_stack_id = ap->kernel_stack_id;
 
 	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
 
@@ -6,6 +6,7 @@
 #define __CAPABLE_H
 
 #define TASK_COMM_LEN	16
+#define MAX_STACK_DEPTH	127
 
 struct cap_event {
 	__u64	mntnsid;
@@ -15,6 +16,10 @@ struct cap_event {
 	__u32	uid;
 	int	cap_opt;
 	int	ret;
+	// syscall and kernel_stack_id are only set with print_stack, they are
+	// -1 when unknown.
+	int	syscall;
+	int	kernel_stack_id;
 	__u8	task[TASK_COMM_LEN];
 };
 
@@ -14,19 +14,31 @@ import (
 )
 
 type capabilitiesArgsT struct {
-	Cap    int32
-	CapOpt int32
+	Cap           int32
+	CapOpt        int32
+	Syscall       int32
+	KernelStackId int32
 }
 
 type capabilitiesCapEvent struct {
-	Mntnsid uint64
-	Pid     uint32
+	Mntnsid       uint64
+	Pid           uint32
+	Cap           int32
+	Tgid          uint32
+	Uid           uint32
+	CapOpt        int32
+	Ret           int32
+	Syscall       int32
+	KernelStackId int32
+	Task          [16]uint8
+}
+
+type capabilitiesSyscallKey struct {
+	MntnsId uint64
+	Syscall int32
 	Cap     int32
-	Tgid    uint32
-	Uid     uint32
-	CapOpt  int32
-	Ret     int32
-	Task    [16]uint8
+	Denied  int32
+	_       [4]byte
 }
 
 type capabilitiesUniqueKey struct {
@@ -87,6 +99,8 @@ type capabilitiesMapSpecs struct {
 	Events        *ebpf.MapSpec `ebpf:"events"`
 	MountNsFilter *ebpf.MapSpec `ebpf:"mount_ns_filter"`
 	Seen          *ebpf.MapSpec `ebpf:"seen"`
+	SeenSyscalls  *ebpf.MapSpec `ebpf:"seen_syscalls"`
+	Stackmap      *ebpf.MapSpec `ebpf:"stackmap"`
 	Start         *ebpf.MapSpec `ebpf:"start"`
 }
 
@@ -112,6 +126,8 @@ type capabilitiesMaps struct {
 	Events        *ebpf.Map `ebpf:"events"`
 	MountNsFilter *ebpf.Map `ebpf:"mount_ns_filter"`
 	Seen          *ebpf.Map `ebpf:"seen"`
+	SeenSyscalls  *ebpf.Map `ebpf:"seen_syscalls"`
+	Stackmap      *ebpf.Map `ebpf:"stackmap"`
 	Start         *ebpf.Map `ebpf:"start"`
 }
 
@@ -120,6 +136,8 @@ func (m *capabilitiesMaps) Close() error {
 		m.Events,
 		m.MountNsFilter,
 		m.Seen,
+		m.SeenSyscalls,
+		m.Stackmap,
 		m.Start,
 	)
 }
@@ -149,5 +167,6 @@ func _CapabilitiesClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed capabilities_bpfel_x86.o
 var _CapabilitiesBytes []byte
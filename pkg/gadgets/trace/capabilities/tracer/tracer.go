@@ -21,6 +21,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/cilium/ebpf"
@@ -28,16 +30,36 @@ import (
 	"github.com/cilium/ebpf/link"
 	"github.com/cilium/ebpf/perf"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/internal/kallsyms"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/capabilities/types"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+	libseccomp "github.com/seccomp/libseccomp-golang"
 )
 
-//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type cap_event capabilities ./bpf/capable.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type cap_event -type syscall_key capabilities ./bpf/capable.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+const maxStackDepth = 127
+
+// countInterval is how often the checks seen again since they were reported
+// are reported with their updated count, with PrintStack.
+const countInterval = 5 * time.Second
 
 type Config struct {
 	MountnsMap *ebpf.Map
 	AuditOnly  bool
 	Unique     bool
+
+	// PrintStack collects the syscall and the kernel stack of the checks.
+	// Each syscall, capability and verdict is then reported once per
+	// container, and again every countInterval with the times it was seen
+	// if it happened since.
+	PrintStack bool
+}
+
+// seenCheck is the last report of a syscall, capability and verdict.
+type seenCheck struct {
+	event types.Event
+	count uint64
 }
 
 type Tracer struct {
@@ -49,6 +71,14 @@ type Tracer struct {
 	enricher             gadgets.DataEnricher
 	eventCallback        func(types.Event)
 	runningKernelVersion uint32
+	kAllSyms             *kallsyms.KAllSyms
+
+	// mu serializes the calls to eventCallback and protects seen, that
+	// are used by run() and countLoop().
+	mu   sync.Mutex
+	seen map[capabilitiesSyscallKey]*seenCheck
+	done chan struct{}
+	wg   sync.WaitGroup
 }
 
 var capabilitiesNames = map[int32]string{
@@ -102,6 +132,7 @@ func NewTracer(c *Config, enricher gadgets.DataEnricher,
 		config:        c,
 		enricher:      enricher,
 		eventCallback: eventCallback,
+		seen:          make(map[capabilitiesSyscallKey]*seenCheck),
 	}
 
 	if err := t.start(); err != nil {
@@ -113,6 +144,12 @@ func NewTracer(c *Config, enricher gadgets.DataEnricher,
 }
 
 func (t *Tracer) Stop() {
+	if t.done != nil {
+		close(t.done)
+		t.wg.Wait()
+		t.done = nil
+	}
+
 	t.capEnterLink = gadgets.CloseLink(t.capEnterLink)
 	t.capExitLink = gadgets.CloseLink(t.capExitLink)
 
@@ -130,6 +167,13 @@ func (t *Tracer) start() error {
 	}
 	t.runningKernelVersion = runningKernelVersion
 
+	if t.config.PrintStack {
+		t.kAllSyms, err = kallsyms.NewKAllSyms()
+		if err != nil {
+			return fmt.Errorf("reading kernel symbols: %w", err)
+		}
+	}
+
 	spec, err := loadCapabilities()
 	if err != nil {
 		return fmt.Errorf("failed to load ebpf program: %w", err)
@@ -148,6 +192,7 @@ func (t *Tracer) start() error {
 		"linux_version_code": runningKernelVersion,
 		"audit_only":         t.config.AuditOnly,
 		"unique":             t.config.Unique,
+		"print_stack":        t.config.PrintStack,
 	}
 
 	if err := spec.RewriteConstants(consts); err != nil {
@@ -182,6 +227,12 @@ func (t *Tracer) start() error {
 
 	go t.run()
 
+	if t.config.PrintStack {
+		t.done = make(chan struct{})
+		t.wg.Add(1)
+		go t.countLoop()
+	}
+
 	return nil
 }
 
@@ -205,7 +256,9 @@ func (t *Tracer) run() {
 			}
 
 			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.mu.Lock()
 			t.eventCallback(types.Base(eventtypes.Err(msg)))
+			t.mu.Unlock()
 			return
 		}
 
@@ -260,10 +313,116 @@ func (t *Tracer) run() {
 			Verdict:   verdict,
 		}
 
+		if bpfEvent.Syscall >= 0 {
+			event.Syscall = syscallToName(int(bpfEvent.Syscall))
+		}
+		if bpfEvent.KernelStackId >= 0 {
+			event.KernelStack = t.kernelStack(bpfEvent.KernelStackId)
+		}
+
 		if t.enricher != nil {
 			t.enricher.Enrich(&event.CommonData, event.MountNsID)
 		}
 
+		t.mu.Lock()
+		if t.config.PrintStack {
+			event.Count = 1
+
+			key := capabilitiesSyscallKey{
+				MntnsId: bpfEvent.Mntnsid,
+				Syscall: bpfEvent.Syscall,
+				Cap:     bpfEvent.Cap,
+			}
+			if bpfEvent.Ret != 0 {
+				key.Denied = 1
+			}
+			t.seen[key] = &seenCheck{event: event, count: 1}
+		}
 		t.eventCallback(event)
+		t.mu.Unlock()
+	}
+}
+
+// countLoop reports the checks seen again, with the times they were seen
+// in total, every countInterval.
+func (t *Tracer) countLoop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(countInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			t.reportCounts()
+		}
+	}
+}
+
+func (t *Tracer) reportCounts() {
+	var key capabilitiesSyscallKey
+	var count uint64
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Only keep the checks still in seen_syscalls: the ones evicted from
+	// the LRU map are reported again as new ones.
+	seen := make(map[capabilitiesSyscallKey]*seenCheck, len(t.seen))
+
+	iter := t.objs.SeenSyscalls.Iterate()
+	for iter.Next(&key, &count) {
+		check, ok := t.seen[key]
+		if !ok {
+			// Its event wasn't read yet
+			continue
+		}
+		seen[key] = check
+
+		if count <= check.count {
+			continue
+		}
+		check.count = count
+
+		event := check.event
+		event.Count = count
+		t.eventCallback(event)
+	}
+	if err := iter.Err(); err != nil {
+		msg := fmt.Sprintf("Error reading the counts of the checks: %s", err)
+		t.eventCallback(types.Base(eventtypes.Warn(msg)))
+		return
+	}
+
+	t.seen = seen
+}
+
+func syscallToName(syscall int) string {
+	name, err := libseccomp.ScmpSyscall(syscall).GetName()
+	if err != nil {
+		return fmt.Sprintf("syscall%d", syscall)
+	}
+	return name
+}
+
+func (t *Tracer) kernelStack(stackID int32) []string {
+	ips := [maxStackDepth]uint64{}
+	if err := t.objs.Stackmap.Lookup(stackID, unsafe.Pointer(&ips)); err != nil {
+		return nil
+	}
+	// Each stack is only reported once, deleting it keeps room for the
+	// other ones. It's done separately as the kernel doesn't support
+	// LookupAndDelete() on stack trace maps.
+	t.objs.Stackmap.Delete(stackID)
+
+	stack := []string{}
+	for _, ip := range ips {
+		if ip == 0 {
+			break
+		}
+		stack = append(stack, t.kAllSyms.LookupByInstructionPointer(ip))
 	}
+	return stack
 }
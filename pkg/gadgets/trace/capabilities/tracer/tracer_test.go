@@ -264,6 +264,35 @@ func TestCapabilitiesTracer(t *testing.T) {
 				}
 			},
 		},
+		"print_stack": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+					PrintStack: true,
+				}
+			},
+			generateEvent: repeatChown,
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, _ interface{}, events []types.Event) {
+				found := []types.Event{}
+				for _, event := range events {
+					if event.CapName == "CHOWN" {
+						found = append(found, event)
+					}
+				}
+
+				if len(found) != 1 {
+					t.Fatalf("Syscall and capability not aggregated: found %d times", len(found))
+				}
+
+				// unix.Chown() uses fchownat.
+				utilstest.Equal(t, "fchownat", found[0].Syscall,
+					"Captured event has bad Syscall")
+
+				if len(found[0].KernelStack) == 0 {
+					t.Fatal("Captured event has no kernel stack")
+				}
+			},
+		},
 	} {
 		test := test
 
@@ -22,13 +22,15 @@ import (
 )
 
 const (
-	AuditOnlyDefault = true
-	UniqueDefault    = false
+	AuditOnlyDefault  = true
+	UniqueDefault     = false
+	PrintStackDefault = false
 )
 
 const (
-	AuditOnlyParam = "audit-only"
-	UniqueParam    = "unique"
+	AuditOnlyParam  = "audit-only"
+	UniqueParam     = "unique"
+	PrintStackParam = "print-stack"
 )
 
 type Event struct {
@@ -43,6 +45,13 @@ type Event struct {
 	Verdict   string `json:"verdict,omitempty" column:"verdict,width:7,fixed"`
 	InsetID   *bool  `json:"insetid,omitempty" column:"insetid,width:7,fixed,hide"`
 	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+
+	// Syscall, KernelStack and Count are only set with the print-stack
+	// parameter. Count is the number of times the syscall, capability and
+	// verdict were seen in the container.
+	Syscall     string   `json:"syscall,omitempty" column:"syscall,width:16,hide"`
+	KernelStack []string `json:"kernelStack,omitempty"`
+	Count       uint64   `json:"count,omitempty" column:"count,minWidth:5,hide"`
 }
 
 func GetColumns() *columns.Columns[Event] {
@@ -59,6 +68,17 @@ func GetColumns() *columns.Columns[Event] {
 	return cols
 }
 
+func GetPrintStackColumns() *columns.Columns[Event] {
+	cols := GetColumns()
+
+	for _, name := range []string{"syscall", "count"} {
+		col, _ := cols.GetColumn(name)
+		col.Visible = true
+	}
+
+	return cols
+}
+
 func Base(ev eventtypes.Event) Event {
 	return Event{
 		Event: ev,
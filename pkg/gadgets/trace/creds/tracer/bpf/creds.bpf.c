@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: GPL-2.0
+/* Copyright (c) 2022 The Inspektor Gadget authors */
+#include <vmlinux/vmlinux.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+#include <bpf/bpf_tracing.h>
+
+#include "creds.h"
+
+#define MAX_ENTRIES	10240
+
+const volatile bool filter_by_mnt_ns = false;
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event *unusedevent __attribute__((unused));
+
+struct pending {
+	__u32 syscall;
+	struct cred_ids old;
+};
+
+// values keeps the credentials of the threads between the enter and the
+// exit of the syscalls
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, __u32);
+	__type(value, struct pending);
+} values SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+	__uint(key_size, sizeof(__u32));
+	__uint(value_size, sizeof(__u32));
+} events SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 1024);
+	__uint(key_size, sizeof(u64));
+	__uint(value_size, sizeof(u32));
+} mount_ns_filter SEC(".maps");
+
+static __always_inline bool filtered_out(struct task_struct *task, u64 *mntns_id)
+{
+	*mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+	return filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, mntns_id);
+}
+
+static __always_inline void read_creds(const struct cred *cred, struct cred_ids *c)
+{
+	c->uid = BPF_CORE_READ(cred, uid.val);
+	c->euid = BPF_CORE_READ(cred, euid.val);
+	c->suid = BPF_CORE_READ(cred, suid.val);
+	c->gid = BPF_CORE_READ(cred, gid.val);
+	c->egid = BPF_CORE_READ(cred, egid.val);
+	c->sgid = BPF_CORE_READ(cred, sgid.val);
+	c->ngroups = BPF_CORE_READ(cred, group_info, ngroups);
+
+	// kernel_cap_t is an array of two __u32 before 6.3 and a __u64 since
+	// then: both have the same layout on little endian.
+	bpf_core_read(&c->cap_effective, sizeof(c->cap_effective), &cred->cap_effective);
+	bpf_core_read(&c->cap_permitted, sizeof(c->cap_permitted), &cred->cap_permitted);
+}
+
+static __always_inline bool creds_changed(const struct cred_ids *old, const struct cred_ids *new)
+{
+	return old->uid != new->uid || old->euid != new->euid ||
+	       old->suid != new->suid || old->gid != new->gid ||
+	       old->egid != new->egid || old->sgid != new->sgid ||
+	       old->ngroups != new->ngroups ||
+	       old->cap_effective != new->cap_effective ||
+	       old->cap_permitted != new->cap_permitted;
+}
+
+static __always_inline void output_event(void *ctx, u64 mntns_id, __u32 syscall,
+					 int ret, const struct cred_ids *old,
+					 const struct cred_ids *new)
+{
+	struct event event;
+
+	// the event is sent as is: make sure the padding is zeroed
+	__builtin_memset(&event, 0, sizeof(event));
+
+	event.mntns_id = mntns_id;
+	event.pid = bpf_get_current_pid_tgid() >> 32;
+	event.syscall = syscall;
+	event.ret = ret;
+	event.old = *old;
+	event.new = *new;
+	bpf_get_current_comm(&event.comm, sizeof(event.comm));
+
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
+}
+
+static __always_inline int probe_entry(enum syscall syscall)
+{
+	struct task_struct *task = (struct task_struct *) bpf_get_current_task();
+	struct pending pending;
+	u64 mntns_id;
+	__u32 tid;
+
+	if (filtered_out(task, &mntns_id))
+		return 0;
+
+	__builtin_memset(&pending, 0, sizeof(pending));
+	pending.syscall = syscall;
+	read_creds(BPF_CORE_READ(task, cred), &pending.old);
+
+	tid = (__u32)bpf_get_current_pid_tgid();
+	bpf_map_update_elem(&values, &tid, &pending, BPF_ANY);
+	return 0;
+}
+
+SEC("tracepoint/syscalls/sys_enter_setuid")
+int ig_setuid_e(void *ctx)
+{
+	return probe_entry(SYSCALL_SETUID);
+}
+
+SEC("tracepoint/syscalls/sys_enter_setreuid")
+int ig_setreuid_e(void *ctx)
+{
+	return probe_entry(SYSCALL_SETREUID);
+}
+
+SEC("tracepoint/syscalls/sys_enter_setresuid")
+int ig_setresuid_e(void *ctx)
+{
+	return probe_entry(SYSCALL_SETRESUID);
+}
+
+SEC("tracepoint/syscalls/sys_enter_setgid")
+int ig_setgid_e(void *ctx)
+{
+	return probe_entry(SYSCALL_SETGID);
+}
+
+SEC("tracepoint/syscalls/sys_enter_setregid")
+int ig_setregid_e(void *ctx)
+{
+	return probe_entry(SYSCALL_SETREGID);
+}
+
+SEC("tracepoint/syscalls/sys_enter_setresgid")
+int ig_setresgid_e(void *ctx)
+{
+	return probe_entry(SYSCALL_SETRESGID);
+}
+
+SEC("tracepoint/syscalls/sys_enter_setgroups")
+int ig_setgroups_e(void *ctx)
+{
+	return probe_entry(SYSCALL_SETGROUPS);
+}
+
+SEC("tracepoint/syscalls/sys_enter_capset")
+int ig_capset_e(void *ctx)
+{
+	return probe_entry(SYSCALL_CAPSET);
+}
+
+// ig_creds_x is attached to the exit tracepoints of all the syscalls: every
+// call is reported, even when it failed or didn't change the credentials.
+SEC("tracepoint/syscalls/sys_exit")
+int ig_creds_x(struct trace_event_raw_sys_exit *ctx)
+{
+	struct task_struct *task = (struct task_struct *) bpf_get_current_task();
+	__u32 tid = (__u32)bpf_get_current_pid_tgid();
+	struct pending *pending;
+	struct cred_ids new;
+	u64 mntns_id;
+
+	pending = bpf_map_lookup_elem(&values, &tid);
+	if (!pending)
+		return 0;
+
+	__builtin_memset(&new, 0, sizeof(new));
+	read_creds(BPF_CORE_READ(task, cred), &new);
+
+	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+	output_event(ctx, mntns_id, pending->syscall, ctx->ret, &pending->old, &new);
+
+	bpf_map_delete_elem(&values, &tid);
+	return 0;
+}
+
+// ig_commit_creds reports the credentials committed outside of the traced
+// syscalls, like on the exec of a set-user-ID binary or of a file with
+// capabilities. Those committed by the traced syscalls are reported on
+// their exit.
+SEC("kprobe/commit_creds")
+int BPF_KPROBE(ig_commit_creds, struct cred *cred)
+{
+	struct task_struct *task = (struct task_struct *) bpf_get_current_task();
+	__u32 tid = (__u32)bpf_get_current_pid_tgid();
+	struct cred_ids old, new;
+	u64 mntns_id;
+
+	if (bpf_map_lookup_elem(&values, &tid))
+		return 0;
+
+	if (filtered_out(task, &mntns_id))
+		return 0;
+
+	__builtin_memset(&old, 0, sizeof(old));
+	__builtin_memset(&new, 0, sizeof(new));
+	read_creds(BPF_CORE_READ(task, cred), &old);
+	read_creds(cred, &new);
+
+	// commit_creds() is called on every exec: only report the transitions
+	if (!creds_changed(&old, &new))
+		return 0;
+
+	output_event(ctx, mntns_id, SYSCALL_NONE, 0, &old, &new);
+	return 0;
+}
+
+char LICENSE[] SEC("license") = "GPL";
@@ -0,0 +1,43 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+#ifndef __CREDS_H
+#define __CREDS_H
+
+#define TASK_COMM_LEN	16
+
+// SYSCALL_NONE is used for the credentials committed by the kernel outside
+// of the traced syscalls, like the exec of a set-user-ID binary.
+enum syscall {
+	SYSCALL_NONE,
+	SYSCALL_SETUID,
+	SYSCALL_SETREUID,
+	SYSCALL_SETRESUID,
+	SYSCALL_SETGID,
+	SYSCALL_SETREGID,
+	SYSCALL_SETRESGID,
+	SYSCALL_SETGROUPS,
+	SYSCALL_CAPSET,
+};
+
+struct cred_ids {
+	__u64 cap_effective;
+	__u64 cap_permitted;
+	__u32 uid;
+	__u32 euid;
+	__u32 suid;
+	__u32 gid;
+	__u32 egid;
+	__u32 sgid;
+	__u32 ngroups;
+};
+
+struct event {
+	__u64 mntns_id;
+	struct cred_ids old;
+	struct cred_ids new;
+	__u32 pid;
+	__u32 syscall;
+	int ret;
+	__u8 comm[TASK_COMM_LEN];
+};
+
+#endif /* __CREDS_H */
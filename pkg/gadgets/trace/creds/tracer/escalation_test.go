@@ -0,0 +1,65 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"testing"
+
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type fakeUIDLookup map[uint64]uint32
+
+func (f fakeUIDLookup) Enrich(event *eventtypes.CommonData, mountnsid uint64) {}
+
+func (f fakeUIDLookup) LookupContainerUIDByMntns(mntnsid uint64) (uint32, bool) {
+	uid, ok := f[mntnsid]
+	return uid, ok
+}
+
+type fakeEnricher struct{}
+
+func (fakeEnricher) Enrich(event *eventtypes.CommonData, mountnsid uint64) {}
+
+func TestIsNonRootContainer(t *testing.T) {
+	tracer := &Tracer{
+		enricher: fakeUIDLookup{
+			4026532001: 0,
+			4026532002: 1000,
+		},
+	}
+
+	for _, entry := range []struct {
+		description string
+		mntnsid     uint64
+		expected    bool
+	}{
+		{"container running as root", 4026532001, false},
+		{"container running as non-root", 4026532002, true},
+		{"unknown container or host", 4026532003, false},
+	} {
+		if actual := tracer.isNonRootContainer(entry.mntnsid); actual != entry.expected {
+			t.Fatalf("Failed test %q: got %t, expected %t", entry.description, actual, entry.expected)
+		}
+	}
+
+	tracer.enricher = fakeEnricher{}
+	if tracer.isNonRootContainer(4026532002) {
+		t.Fatalf("Container flagged as non-root without the user IDs")
+	}
+}
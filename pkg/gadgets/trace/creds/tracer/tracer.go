@@ -0,0 +1,240 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/creds/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event creds ./bpf/creds.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+// Keep in sync with enum syscall in bpf/creds.h
+var syscallNames = map[uint32]string{
+	0: "",
+	1: "setuid",
+	2: "setreuid",
+	3: "setresuid",
+	4: "setgid",
+	5: "setregid",
+	6: "setresgid",
+	7: "setgroups",
+	8: "capset",
+}
+
+type Config struct {
+	MountnsMap *ebpf.Map
+}
+
+type Tracer struct {
+	config        *Config
+	enricher      gadgets.DataEnricher
+	eventCallback func(types.Event)
+
+	objs   credsObjects
+	links  []link.Link
+	reader *perf.Reader
+}
+
+func NewTracer(config *Config, enricher gadgets.DataEnricher,
+	eventCallback func(types.Event),
+) (*Tracer, error) {
+	t := &Tracer{
+		config:        config,
+		enricher:      enricher,
+		eventCallback: eventCallback,
+	}
+
+	if err := t.start(); err != nil {
+		t.Stop()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Tracer) Stop() {
+	for i := range t.links {
+		t.links[i] = gadgets.CloseLink(t.links[i])
+	}
+	t.links = nil
+
+	if t.reader != nil {
+		t.reader.Close()
+	}
+
+	t.objs.Close()
+}
+
+func (t *Tracer) start() error {
+	spec, err := loadCreds()
+	if err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	mapReplacements := map[string]*ebpf.Map{}
+	filterByMntNs := false
+
+	if t.config.MountnsMap != nil {
+		filterByMntNs = true
+		mapReplacements["mount_ns_filter"] = t.config.MountnsMap
+	}
+
+	consts := map[string]interface{}{
+		"filter_by_mnt_ns": filterByMntNs,
+	}
+
+	if err := spec.RewriteConstants(consts); err != nil {
+		return fmt.Errorf("error RewriteConstants: %w", err)
+	}
+
+	opts := ebpf.CollectionOptions{
+		MapReplacements: mapReplacements,
+	}
+
+	if err := spec.LoadAndAssign(&t.objs, &opts); err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	syscalls := []struct {
+		name string
+		prog *ebpf.Program
+	}{
+		{"setuid", t.objs.IgSetuidE},
+		{"setreuid", t.objs.IgSetreuidE},
+		{"setresuid", t.objs.IgSetresuidE},
+		{"setgid", t.objs.IgSetgidE},
+		{"setregid", t.objs.IgSetregidE},
+		{"setresgid", t.objs.IgSetresgidE},
+		{"setgroups", t.objs.IgSetgroupsE},
+		{"capset", t.objs.IgCapsetE},
+	}
+
+	for _, syscall := range syscalls {
+		enter, err := link.Tracepoint("syscalls", "sys_enter_"+syscall.name, syscall.prog, nil)
+		if err != nil {
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, enter)
+
+		exit, err := link.Tracepoint("syscalls", "sys_exit_"+syscall.name, t.objs.IgCredsX, nil)
+		if err != nil {
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, exit)
+	}
+
+	commitCreds, err := link.Kprobe("commit_creds", t.objs.IgCommitCreds, nil)
+	if err != nil {
+		return fmt.Errorf("error opening kprobe: %w", err)
+	}
+	t.links = append(t.links, commitCreds)
+
+	t.reader, err = perf.NewReader(t.objs.credsMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("error creating perf ring buffer: %w", err)
+	}
+
+	go t.run()
+
+	return nil
+}
+
+func (t *Tracer) run() {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				// nothing to do, we're done
+				return
+			}
+
+			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.eventCallback(types.Base(eventtypes.Err(msg)))
+			return
+		}
+
+		if record.LostSamples > 0 {
+			msg := fmt.Sprintf("lost %d samples", record.LostSamples)
+			t.eventCallback(types.Base(eventtypes.Warn(msg)))
+			continue
+		}
+
+		bpfEvent := (*credsEvent)(unsafe.Pointer(&record.RawSample[0]))
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:        bpfEvent.Pid,
+			Comm:       gadgets.FromCString(bpfEvent.Comm[:]),
+			Syscall:    syscallNames[bpfEvent.Syscall],
+			Ret:        int(bpfEvent.Ret),
+			OldUID:     bpfEvent.Old.Uid,
+			NewUID:     bpfEvent.New.Uid,
+			OldEUID:    bpfEvent.Old.Euid,
+			NewEUID:    bpfEvent.New.Euid,
+			OldSUID:    bpfEvent.Old.Suid,
+			NewSUID:    bpfEvent.New.Suid,
+			OldGID:     bpfEvent.Old.Gid,
+			NewGID:     bpfEvent.New.Gid,
+			OldEGID:    bpfEvent.Old.Egid,
+			NewEGID:    bpfEvent.New.Egid,
+			OldSGID:    bpfEvent.Old.Sgid,
+			NewSGID:    bpfEvent.New.Sgid,
+			OldNGroups: bpfEvent.Old.Ngroups,
+			NewNGroups: bpfEvent.New.Ngroups,
+			OldCapEff:  bpfEvent.Old.CapEffective,
+			NewCapEff:  bpfEvent.New.CapEffective,
+			OldCapPrm:  bpfEvent.Old.CapPermitted,
+			NewCapPrm:  bpfEvent.New.CapPermitted,
+			MountNsID:  bpfEvent.MntnsId,
+		}
+		event.Escalated = event.IsEscalation() && t.isNonRootContainer(event.MountNsID)
+
+		if t.enricher != nil {
+			t.enricher.Enrich(&event.CommonData, event.MountNsID)
+		}
+
+		t.eventCallback(event)
+	}
+}
+
+// isNonRootContainer tells if the process is in a known container whose
+// processes are started as a non-root user. The processes of containers
+// started as root can get the user ID 0 back without it being an escalation,
+// e.g. after "su nobody", and the user isn't known for the processes of the
+// host, of unknown containers or if the enricher can't tell.
+func (t *Tracer) isNonRootContainer(mntnsid uint64) bool {
+	lookup, ok := t.enricher.(gadgets.ContainerUIDLookup)
+	if !ok {
+		return false
+	}
+	uid, known := lookup.LookupContainerUIDByMntns(mntnsid)
+	return known && uid != 0
+}
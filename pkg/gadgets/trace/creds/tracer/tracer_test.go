@@ -0,0 +1,264 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	utilstest "github.com/lato333/inspektor-gadget/pkg/gadgets/internal/test"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/creds/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/creds/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+const unprivilegedUID = 1435
+
+func TestCredsTracerCreate(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+	if tracer == nil {
+		t.Fatal("Returned tracer was nil")
+	}
+}
+
+func TestCredsTracerStopIdempotent(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+
+	// Check that a double stop doesn't cause issues
+	tracer.Stop()
+	tracer.Stop()
+}
+
+func TestCredsTracer(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	allCaps, err := allCapabilities()
+	if err != nil {
+		t.Fatalf("Error getting capabilities: %s", err)
+	}
+
+	groups, err := os.Getgroups()
+	if err != nil {
+		t.Fatalf("Error getting groups: %s", err)
+	}
+	ngroups := uint32(len(groups))
+
+	type testDefinition struct {
+		runnerConfig    *utilstest.RunnerConfig
+		getTracerConfig func(info *utilstest.RunnerInfo) *tracer.Config
+		generateEvent   func() error
+		validateEvent   func(t *testing.T, info *utilstest.RunnerInfo, _ interface{}, events []types.Event)
+	}
+
+	// The syscalls are called directly because the functions of the
+	// syscall package change the credentials of all the threads, and only
+	// the ones of the runner thread must be changed.
+	setresuid := func(ruid, euid, suid int) error {
+		_, _, errno := syscall.Syscall(syscall.SYS_SETRESUID, uintptr(ruid), uintptr(euid), uintptr(suid))
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+
+	for name, test := range map[string]testDefinition{
+		"captures_no_events_with_no_matching_filter": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, 0),
+				}
+			},
+			generateEvent: func() error {
+				return setresuid(-1, unprivilegedUID, -1)
+			},
+			validateEvent: utilstest.ExpectNoEvent[types.Event, interface{}],
+		},
+		"captures_setresuid": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func() error {
+				return setresuid(-1, unprivilegedUID, -1)
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, _ interface{}) *types.Event {
+				return &types.Event{
+					Event: eventtypes.Event{
+						Type: eventtypes.NORMAL,
+					},
+					Pid:        uint32(info.Pid),
+					Comm:       info.Comm,
+					Syscall:    "setresuid",
+					NewEUID:    unprivilegedUID,
+					OldNGroups: ngroups,
+					NewNGroups: ngroups,
+					// The effective capabilities are cleared when the
+					// effective user ID isn't 0 anymore.
+					OldCapEff: allCaps,
+					OldCapPrm: allCaps,
+					NewCapPrm: allCaps,
+					MountNsID: info.MountNsID,
+				}
+			}),
+		},
+		"captures_failed_setuid": {
+			runnerConfig: &utilstest.RunnerConfig{UID: unprivilegedUID},
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func() error {
+				_, _, errno := syscall.Syscall(syscall.SYS_SETUID, 0, 0, 0)
+				if errno != syscall.EPERM {
+					return fmt.Errorf("setuid(0) returned %v, expected EPERM", errno)
+				}
+				return nil
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, _ interface{}) *types.Event {
+				return &types.Event{
+					Event: eventtypes.Event{
+						Type: eventtypes.NORMAL,
+					},
+					Pid:        uint32(info.Pid),
+					Comm:       info.Comm,
+					Syscall:    "setuid",
+					Ret:        -int(syscall.EPERM),
+					OldUID:     unprivilegedUID,
+					NewUID:     unprivilegedUID,
+					OldEUID:    unprivilegedUID,
+					NewEUID:    unprivilegedUID,
+					OldSUID:    unprivilegedUID,
+					NewSUID:    unprivilegedUID,
+					OldNGroups: ngroups,
+					NewNGroups: ngroups,
+					MountNsID:  info.MountNsID,
+				}
+			}),
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events := []types.Event{}
+			eventCallback := func(event types.Event) {
+				events = append(events, event)
+			}
+
+			runner := utilstest.NewRunnerWithTest(t, test.runnerConfig)
+
+			createTracer(t, test.getTracerConfig(runner.Info), eventCallback)
+
+			utilstest.RunWithRunner(t, runner, test.generateEvent)
+
+			// Give some time for the tracer to capture the events
+			time.Sleep(100 * time.Millisecond)
+
+			test.validateEvent(t, runner.Info, nil, events)
+		})
+	}
+}
+
+func TestCredsEscalation(t *testing.T) {
+	t.Parallel()
+
+	for name, test := range map[string]struct {
+		event     types.Event
+		escalated bool
+	}{
+		"setuid_binary": {
+			event: types.Event{
+				OldUID: 1000, OldEUID: 1000, OldSUID: 1000,
+				NewUID: 1000, NewEUID: 0, NewSUID: 0,
+			},
+			escalated: true,
+		},
+		"regain_saved_root": {
+			event: types.Event{
+				OldUID: 1000, OldEUID: 1000, OldSUID: 0,
+				NewUID: 1000, NewEUID: 0, NewSUID: 0,
+			},
+		},
+		"drop_root": {
+			event: types.Event{
+				NewUID: 1000, NewEUID: 1000, NewSUID: 1000,
+			},
+		},
+		"non_root": {
+			event: types.Event{
+				OldUID: 1000, OldEUID: 1000, OldSUID: 1000,
+				NewUID: 1001, NewEUID: 1001, NewSUID: 1001,
+			},
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			utilstest.Equal(t, test.escalated, test.event.IsEscalation(),
+				"IsEscalation() returned a wrong value")
+		})
+	}
+}
+
+// allCapabilities returns the capability set with all the capabilities
+// supported by the kernel, the one of a root process.
+func allCapabilities() (uint64, error) {
+	b, err := os.ReadFile("/proc/sys/kernel/cap_last_cap")
+	if err != nil {
+		return 0, err
+	}
+	last, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, err
+	}
+	return 1<<(last+1) - 1, nil
+}
+
+func createTracer(
+	t *testing.T, config *tracer.Config, callback func(types.Event),
+) *tracer.Tracer {
+	t.Helper()
+
+	tracer, err := tracer.NewTracer(config, nil, callback)
+	if err != nil {
+		t.Fatalf("Error creating tracer: %s", err)
+	}
+	t.Cleanup(tracer.Stop)
+
+	return tracer
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type Event struct {
+	eventtypes.Event
+
+	Pid  uint32 `json:"pid,omitempty" column:"pid,template:pid"`
+	Comm string `json:"comm,omitempty" column:"comm,template:comm"`
+
+	// Syscall is empty for the credentials committed by the kernel outside
+	// of the traced syscalls, like on the exec of a set-user-ID binary.
+	Syscall string `json:"syscall,omitempty" column:"syscall,width:9,fixed"`
+	Ret     int    `json:"ret,omitempty" column:"ret,width:4,fixed"`
+
+	// The credentials of the process before and after the transition.
+	OldUID     uint32 `json:"oldUid" column:"olduid,minWidth:6,hide"`
+	NewUID     uint32 `json:"newUid" column:"newuid,minWidth:6,hide"`
+	OldEUID    uint32 `json:"oldEuid" column:"oldeuid,minWidth:7"`
+	NewEUID    uint32 `json:"newEuid" column:"neweuid,minWidth:7"`
+	OldSUID    uint32 `json:"oldSuid" column:"oldsuid,minWidth:7,hide"`
+	NewSUID    uint32 `json:"newSuid" column:"newsuid,minWidth:7,hide"`
+	OldGID     uint32 `json:"oldGid" column:"oldgid,minWidth:6,hide"`
+	NewGID     uint32 `json:"newGid" column:"newgid,minWidth:6,hide"`
+	OldEGID    uint32 `json:"oldEgid" column:"oldegid,minWidth:7"`
+	NewEGID    uint32 `json:"newEgid" column:"newegid,minWidth:7"`
+	OldSGID    uint32 `json:"oldSgid" column:"oldsgid,minWidth:7,hide"`
+	NewSGID    uint32 `json:"newSgid" column:"newsgid,minWidth:7,hide"`
+	OldNGroups uint32 `json:"oldNgroups" column:"oldngroups,minWidth:10,hide"`
+	NewNGroups uint32 `json:"newNgroups" column:"newngroups,minWidth:10,hide"`
+	OldCapEff  uint64 `json:"oldCapEff" column:"oldcapeff,width:16,fixed"`
+	NewCapEff  uint64 `json:"newCapEff" column:"newcapeff,width:16,fixed"`
+	OldCapPrm  uint64 `json:"oldCapPrm" column:"oldcapprm,width:16,fixed,hide"`
+	NewCapPrm  uint64 `json:"newCapPrm" column:"newcapprm,width:16,fixed,hide"`
+
+	// Escalated is set when a process that had neither a real, effective
+	// nor saved user ID 0 gets one, in a known container whose processes
+	// are started as a non-root user.
+	Escalated bool `json:"escalated,omitempty" column:"escalated,width:9,fixed"`
+
+	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+}
+
+// IsEscalation returns whether the transition gives the user ID 0 to a
+// process that didn't have it in any of its real, effective or saved user
+// IDs, like a set-user-ID root binary run by a non-root user.
+func (e *Event) IsEscalation() bool {
+	if e.OldUID == 0 || e.OldEUID == 0 || e.OldSUID == 0 {
+		return false
+	}
+	return e.NewUID == 0 || e.NewEUID == 0 || e.NewSUID == 0
+}
+
+func GetColumns() *columns.Columns[Event] {
+	cols := columns.MustCreateColumns[Event]()
+
+	// Format the capability sets like in /proc/$pid/status, so they can be
+	// decoded with "capsh --decode".
+	capSet := func(v uint64) string {
+		return fmt.Sprintf("%016x", v)
+	}
+	cols.SetExtractor("oldcapeff", func(event *Event) string {
+		return capSet(event.OldCapEff)
+	})
+	cols.SetExtractor("newcapeff", func(event *Event) string {
+		return capSet(event.NewCapEff)
+	})
+	cols.SetExtractor("oldcapprm", func(event *Event) string {
+		return capSet(event.OldCapPrm)
+	})
+	cols.SetExtractor("newcapprm", func(event *Event) string {
+		return capSet(event.NewCapPrm)
+	})
+
+	return cols
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+const (
+	dnsHeaderLen = 12
+
+	// Maximum number of compression pointers to follow in a name, to avoid
+	// loops in malformed messages.
+	maxPointers = 16
+
+	dnsTypeA     = 1
+	dnsTypeCNAME = 5
+	dnsTypeAAAA  = 28
+)
+
+// readName reads a domain name at the given offset of a DNS message, following
+// compression pointers. It returns the name with dots and the offset of the
+// data after the name.
+// See https://datatracker.ietf.org/doc/html/rfc1035#section-4.1.4
+func readName(msg []byte, off int) (string, int, bool) {
+	name := ""
+	next := -1
+
+	for pointers := 0; pointers <= maxPointers; {
+		if off >= len(msg) {
+			return "", 0, false
+		}
+
+		length := int(msg[off])
+		switch length & 0xC0 {
+		case 0x00:
+			if length == 0 {
+				if next == -1 {
+					next = off + 1
+				}
+				if name == "" {
+					name = "."
+				}
+				return name, next, true
+			}
+			if off+1+length > len(msg) {
+				return "", 0, false
+			}
+			name += string(msg[off+1:off+1+length]) + "."
+			off += 1 + length
+		case 0xC0:
+			if off+2 > len(msg) {
+				return "", 0, false
+			}
+			if next == -1 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3FFF)
+			pointers++
+		default:
+			return "", 0, false
+		}
+	}
+
+	return "", 0, false
+}
+
+// parseAddresses returns the A, AAAA and CNAME records found in the answer
+// section of a DNS message. Parsing stops at the first malformed record.
+func parseAddresses(msg []byte) []string {
+	if len(msg) < dnsHeaderLen {
+		return nil
+	}
+
+	qdcount := int(binary.BigEndian.Uint16(msg[4:]))
+	ancount := int(binary.BigEndian.Uint16(msg[6:]))
+
+	off := dnsHeaderLen
+	for i := 0; i < qdcount; i++ {
+		var ok bool
+		if _, off, ok = readName(msg, off); !ok {
+			return nil
+		}
+		// QTYPE and QCLASS
+		off += 4
+	}
+
+	var addresses []string
+	for i := 0; i < ancount; i++ {
+		var ok bool
+		if _, off, ok = readName(msg, off); !ok {
+			break
+		}
+
+		// TYPE, CLASS, TTL and RDLENGTH
+		if off+10 > len(msg) {
+			break
+		}
+		rrType := binary.BigEndian.Uint16(msg[off:])
+		rdLength := int(binary.BigEndian.Uint16(msg[off+8:]))
+		off += 10
+
+		if off+rdLength > len(msg) {
+			break
+		}
+		rdata := msg[off : off+rdLength]
+
+		switch rrType {
+		case dnsTypeA:
+			if rdLength == net.IPv4len {
+				addresses = append(addresses, net.IP(rdata).String())
+			}
+		case dnsTypeAAAA:
+			if rdLength == net.IPv6len {
+				addresses = append(addresses, net.IP(rdata).String())
+			}
+		case dnsTypeCNAME:
+			if name, _, ok := readName(msg, off); ok {
+				addresses = append(addresses, name)
+			}
+		}
+
+		off += rdLength
+	}
+
+	return addresses
+}
@@ -5,6 +5,18 @@
 // https://datatracker.ietf.org/doc/html/rfc1034#section-3.1
 #define MAX_DNS_NAME 255
 
+// Max size of the packet sent to userspace with responses to parse the
+// answers: the max size of a DNS message over UDP without EDNS plus headers.
+// https://datatracker.ietf.org/doc/html/rfc1035#section-2.3.4
+#define MAX_PACKET (512 + 64)
+
+// Max size of the TCP segments sent to userspace. Messages split across
+// segments are reassembled there. Bigger segments, like the ones coalesced
+// by GRO, are truncated and userspace stops following the stream.
+#define MAX_TCP_PACKET 16384
+
+#define DNS_PORT 53
+
 struct event_t {
 	union {
 		__u8 saddr_v6[16];
@@ -14,15 +26,21 @@ struct event_t {
 		__u8 daddr_v6[16];
 		__u32 daddr_v4;
 	};
+	__u64 timestamp;
 	__u32 af; // AF_INET or AF_INET6
 
 	__u16 id;
 	unsigned short qtype;
+	__u16 sport;
+	__u16 dport;
 
 	// qr says if the dns message is a query (0), or a response (1)
 	unsigned char qr;
 	unsigned char pkt_type;
 	unsigned char rcode;
+	// proto is IPPROTO_UDP or IPPROTO_TCP. TCP events only carry the
+	// segment: the DNS fields are filled by userspace.
+	unsigned char proto;
 
 	__u8 name[MAX_DNS_NAME];
 };
@@ -6,6 +6,7 @@
 #include <linux/ip.h>
 #include <linux/in.h>
 #include <linux/udp.h>
+#include <linux/tcp.h>
 #include <sys/socket.h>
 
 #include <bpf/bpf_helpers.h>
@@ -13,7 +14,9 @@
 
 #include "dns-common.h"
 
-#define DNS_OFF (ETH_HLEN + sizeof(struct iphdr) + sizeof(struct udphdr))
+#define UDP_OFF (ETH_HLEN + sizeof(struct iphdr))
+#define TCP_OFF (ETH_HLEN + sizeof(struct iphdr))
+#define DNS_OFF (UDP_OFF + sizeof(struct udphdr))
 
 /* llvm builtin functions that eBPF C program may use to
  * emit BPF_LD_ABS and BPF_LD_IND instructions
@@ -71,6 +74,43 @@ struct dnshdr {
 	__u16 arcount; // number of additional records
 };
 
+// DNS messages over TCP are prefixed with their length and can be split
+// across several segments, so the segments are sent to userspace which
+// reassembles the streams.
+// https://datatracker.ietf.org/doc/html/rfc1035#section-4.2.2
+static __always_inline int
+handle_tcp(struct __sk_buff *skb)
+{
+	__u16 sport = load_half(skb, TCP_OFF + offsetof(struct tcphdr, source));
+	__u16 dport = load_half(skb, TCP_OFF + offsetof(struct tcphdr, dest));
+	if (sport != DNS_PORT && dport != DNS_PORT)
+		return 0;
+
+	// Skip segments without payload, like the ones of the handshake
+	__u32 doff = (load_byte(skb, TCP_OFF + 12) >> 4) * 4;
+	if (skb->len <= TCP_OFF + doff)
+		return 0;
+
+	struct event_t event = {0,};
+	event.timestamp = bpf_ktime_get_ns();
+	event.af = AF_INET;
+	event.proto = IPPROTO_TCP;
+	event.daddr_v4 = bpf_htonl(load_word(skb, ETH_HLEN + offsetof(struct iphdr, daddr)));
+	event.saddr_v4 = bpf_htonl(load_word(skb, ETH_HLEN + offsetof(struct iphdr, saddr)));
+	event.sport = sport;
+	event.dport = dport;
+	event.pkt_type = skb->pkt_type;
+
+	__u64 pkt_len = skb->len;
+	if (pkt_len > MAX_TCP_PACKET)
+		pkt_len = MAX_TCP_PACKET;
+
+	bpf_perf_event_output(skb, &events, BPF_F_CURRENT_CPU | (pkt_len << 32),
+			      &event, sizeof(event));
+
+	return 0;
+}
+
 SEC("socket1")
 int ig_trace_dns(struct __sk_buff *skb)
 {
@@ -78,8 +118,12 @@ int ig_trace_dns(struct __sk_buff *skb)
 	if (load_half(skb, offsetof(struct ethhdr, h_proto)) != ETH_P_IP)
 		return 0;
 
+	__u8 proto = load_byte(skb, ETH_HLEN + offsetof(struct iphdr, protocol));
+	if (proto == IPPROTO_TCP)
+		return handle_tcp(skb);
+
 	// Skip non-UDP packets
-	if (load_byte(skb, ETH_HLEN + offsetof(struct iphdr, protocol)) != IPPROTO_UDP)
+	if (proto != IPPROTO_UDP)
 		return 0;
 
 	union dnsflags flags;
@@ -117,8 +161,12 @@ int ig_trace_dns(struct __sk_buff *skb)
 		return 0;
 
 	struct event_t event = {0,};
+	event.timestamp = bpf_ktime_get_ns();
 	event.id = load_half(skb, DNS_OFF + offsetof(struct dnshdr, id));
+	event.sport = load_half(skb, UDP_OFF + offsetof(struct udphdr, source));
+	event.dport = load_half(skb, UDP_OFF + offsetof(struct udphdr, dest));
 	event.af = AF_INET;
+	event.proto = IPPROTO_UDP;
 	event.daddr_v4 = load_word(skb, ETH_HLEN + offsetof(struct iphdr, daddr));
 	event.saddr_v4 = load_word(skb, ETH_HLEN + offsetof(struct iphdr, saddr));
 	// load_word converts from network to host endianness. Convert back to
@@ -141,7 +189,18 @@ int ig_trace_dns(struct __sk_buff *skb)
 	// https://datatracker.ietf.org/doc/html/rfc1035#section-4.1.2
 	event.qtype = load_half(skb, DNS_OFF + sizeof(struct dnshdr) + len + 1);
 
-	bpf_perf_event_output(skb, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
+	// Send the packet together with responses so that userspace can parse
+	// the answers. The upper 32 bits of the flags are the number of bytes
+	// of the packet to append to the event.
+	__u64 perf_flags = BPF_F_CURRENT_CPU;
+	if (flags.qr == 1) {
+		__u64 pkt_len = skb->len;
+		if (pkt_len > MAX_PACKET)
+			pkt_len = MAX_PACKET;
+		perf_flags |= pkt_len << 32;
+	}
+
+	bpf_perf_event_output(skb, &events, perf_flags, &event, sizeof(event));
 
 	return 0;
 }
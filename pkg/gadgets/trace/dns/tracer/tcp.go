@@ -0,0 +1,293 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/dns/types"
+)
+
+const (
+	// Streams without segments for tcpStreamTimeout are forgotten when
+	// more than maxTCPStreams are followed.
+	maxTCPStreams    = 1024
+	tcpStreamTimeout = 30 * time.Second
+
+	// Maximum number of out-of-order segments kept per stream before
+	// giving up on it.
+	maxPendingSegments = 16
+
+	tcpFlagFIN = 0x01
+	tcpFlagRST = 0x04
+)
+
+// tcpFlow identifies one direction of a TCP connection. The same addresses
+// can be used in different network namespaces.
+type tcpFlow struct {
+	netns   uint64
+	srcAddr [16]byte
+	dstAddr [16]byte
+	srcPort uint16
+	dstPort uint16
+}
+
+type tcpSegment struct {
+	seq     uint32
+	fin     bool
+	rst     bool
+	payload []byte
+}
+
+// tcpStream reassembles the DNS messages sent in one direction of a TCP
+// connection. Each message is prefixed with its length on two bytes.
+// https://datatracker.ietf.org/doc/html/rfc1035#section-4.2.2
+type tcpStream struct {
+	// nextSeq is the sequence number of the next byte expected
+	nextSeq uint32
+
+	// buf contains the bytes received in order that don't make a complete
+	// message yet
+	buf []byte
+
+	// pending keeps the segments received ahead of nextSeq, indexed by
+	// sequence number
+	pending map[uint32][]byte
+
+	// lastSeen is the timestamp of the last segment
+	lastSeen uint64
+}
+
+// parseTCPPacket parses a packet captured with its Ethernet header. It fails
+// if the packet is malformed or the capture is truncated.
+func parseTCPPacket(packet []byte) (tcpSegment, bool) {
+	const ipOff = 14
+
+	if len(packet) < ipOff+20 {
+		return tcpSegment{}, false
+	}
+	ihl := int(packet[ipOff]&0x0F) * 4
+	// The capture can have padding after the IP packet
+	ipEnd := ipOff + int(binary.BigEndian.Uint16(packet[ipOff+2:]))
+	if ipEnd > len(packet) {
+		return tcpSegment{}, false
+	}
+
+	tcpOff := ipOff + ihl
+	if tcpOff+20 > ipEnd {
+		return tcpSegment{}, false
+	}
+	doff := int(packet[tcpOff+12]>>4) * 4
+	if tcpOff+doff > ipEnd {
+		return tcpSegment{}, false
+	}
+	flags := packet[tcpOff+13]
+
+	return tcpSegment{
+		seq:     binary.BigEndian.Uint32(packet[tcpOff+4:]),
+		fin:     flags&tcpFlagFIN != 0,
+		rst:     flags&tcpFlagRST != 0,
+		payload: packet[tcpOff+doff : ipEnd],
+	}, true
+}
+
+func newTCPStream(seq uint32) *tcpStream {
+	return &tcpStream{
+		nextSeq: seq,
+		pending: make(map[uint32][]byte),
+	}
+}
+
+// push adds the payload of a segment to the stream and returns the messages
+// completed by it. It returns false if the stream can't be followed anymore.
+func (s *tcpStream) push(seq uint32, payload []byte) ([][]byte, bool) {
+	// Sequence numbers wrap around, compare them with their difference
+	diff := int32(seq - s.nextSeq)
+	if diff > 0 {
+		if len(s.pending) >= maxPendingSegments {
+			return nil, false
+		}
+		s.pending[seq] = append([]byte(nil), payload...)
+		return nil, true
+	}
+	s.append(payload, int(-diff))
+
+	// Add the pending segments that are now in order
+	for progress := true; progress; {
+		progress = false
+		for seq, payload := range s.pending {
+			diff := int32(seq - s.nextSeq)
+			if diff > 0 {
+				continue
+			}
+			delete(s.pending, seq)
+			s.append(payload, int(-diff))
+			progress = true
+		}
+	}
+
+	var msgs [][]byte
+	for len(s.buf) >= 2 {
+		length := int(binary.BigEndian.Uint16(s.buf))
+		if len(s.buf) < 2+length {
+			break
+		}
+		msgs = append(msgs, s.buf[2:2+length])
+		s.buf = s.buf[2+length:]
+	}
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+
+	return msgs, true
+}
+
+// append adds payload to the buffer, skipping the first seen bytes that were
+// already received, e.g. with retransmissions.
+func (s *tcpStream) append(payload []byte, seen int) {
+	if seen >= len(payload) {
+		return
+	}
+	s.buf = append(s.buf, payload[seen:]...)
+	s.nextSeq += uint32(len(payload) - seen)
+}
+
+// pushSegment adds a segment to the stream of its flow and returns the DNS
+// messages completed by it. Streams are started with the first segment seen
+// with payload, that is supposed to start with a message.
+func (t *Tracer) pushSegment(flow tcpFlow, segment tcpSegment, timestamp uint64) [][]byte {
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	stream, ok := t.streams[flow]
+	if !ok {
+		if len(segment.payload) == 0 {
+			return nil
+		}
+		if len(t.streams) >= maxTCPStreams {
+			for f, s := range t.streams {
+				if timestamp-s.lastSeen > uint64(tcpStreamTimeout) {
+					delete(t.streams, f)
+				}
+			}
+			if len(t.streams) >= maxTCPStreams {
+				return nil
+			}
+		}
+		stream = newTCPStream(segment.seq)
+		t.streams[flow] = stream
+	}
+	stream.lastSeen = timestamp
+
+	msgs, ok := stream.push(segment.seq, segment.payload)
+	if !ok || segment.fin || segment.rst {
+		delete(t.streams, flow)
+	}
+
+	return msgs
+}
+
+func (t *Tracer) deleteStream(flow tcpFlow) {
+	t.streamsMu.Lock()
+	defer t.streamsMu.Unlock()
+
+	delete(t.streams, flow)
+}
+
+type dnsMessage struct {
+	id    uint16
+	qr    uint8
+	rcode uint8
+	name  string
+	qtype uint16
+}
+
+// parseDNSMessage parses the header and the question of a DNS message. It
+// returns false if the message is malformed. Messages skipped by the eBPF
+// program with UDP are reported with an empty name.
+func parseDNSMessage(msg []byte) (dnsMessage, bool) {
+	if len(msg) < dnsHeaderLen {
+		return dnsMessage{}, false
+	}
+
+	flags := binary.BigEndian.Uint16(msg[2:])
+	m := dnsMessage{
+		id:    binary.BigEndian.Uint16(msg),
+		qr:    uint8(flags >> 15),
+		rcode: uint8(flags & 0x0F),
+	}
+
+	qdcount := binary.BigEndian.Uint16(msg[4:])
+	ancount := binary.BigEndian.Uint16(msg[6:])
+	nscount := binary.BigEndian.Uint16(msg[8:])
+	// Same filters as the eBPF program: only messages with one question
+	// and queries without answers.
+	if qdcount != 1 || (m.qr == 0 && ancount+nscount != 0) {
+		return m, true
+	}
+
+	name, off, ok := readName(msg, dnsHeaderLen)
+	if !ok || off+2 > len(msg) {
+		return dnsMessage{}, false
+	}
+	if name != "." {
+		m.name = name
+	}
+	m.qtype = binary.BigEndian.Uint16(msg[off:])
+
+	return m, true
+}
+
+// parseTCPSegment reassembles the DNS messages of a TCP segment sent by the
+// eBPF program and returns their events.
+func (t *Tracer) parseTCPSegment(netns uint64, bpfEvent *dnsEventT, packet []byte) []*types.Event {
+	flow := tcpFlow{
+		netns:   netns,
+		srcAddr: bpfEvent.SaddrV6,
+		dstAddr: bpfEvent.DaddrV6,
+		srcPort: bpfEvent.Sport,
+		dstPort: bpfEvent.Dport,
+	}
+
+	segment, ok := parseTCPPacket(packet)
+	if !ok {
+		// The stream has a hole now, stop following it
+		t.deleteStream(flow)
+		return nil
+	}
+
+	var events []*types.Event
+	for _, msg := range t.pushSegment(flow, segment, bpfEvent.Timestamp) {
+		m, ok := parseDNSMessage(msg)
+		if !ok {
+			// This is likely not the beginning of a message
+			t.deleteStream(flow)
+			break
+		}
+		if m.name == "" {
+			continue
+		}
+
+		event := newEvent(bpfEvent, "TCP")
+		key := setDirection(&event, netns, bpfEvent, m.id, m.qr)
+		event.DNSName = m.name
+		event.QType = qTypeName(m.qtype)
+
+		events = append(events, t.pairEvent(&event, key, bpfEvent.Timestamp, m.rcode, msg)...)
+	}
+
+	return events
+}
@@ -17,7 +17,9 @@ package tracer
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/lato333/inspektor-gadget/pkg/gadgets"
@@ -34,26 +36,108 @@ const (
 	BPFSocketAttach = 50
 )
 
+const (
+	// Offset of the DNS message in the packets sent with responses: the
+	// eBPF program only handles IPv4 without options and UDP.
+	dnsOffset = 14 + 20 + 8
+
+	// Queries without response are forgotten after queryTimeout, and at
+	// most maxPendingQueries are kept per tracer.
+	queryTimeout      = 10 * time.Second
+	maxPendingQueries = 4096
+)
+
+type Config struct {
+	// ResponsesOnly drops the query events: each response carries the
+	// latency since its query instead.
+	ResponsesOnly bool
+}
+
+// queryKey identifies a query and its response. The same addresses can be
+// used in different network namespaces.
+type queryKey struct {
+	netns      uint64
+	id         uint16
+	clientAddr [16]byte
+	clientPort uint16
+	serverAddr [16]byte
+	serverPort uint16
+}
+
 type Tracer struct {
 	*networktracer.Tracer[types.Event]
+
+	config *Config
+
+	mu sync.Mutex
+	// key: query
+	// value: timestamp of the query
+	queries map[queryKey]uint64
+
+	streamsMu sync.Mutex
+	// streams keeps the DNS over TCP streams being reassembled
+	streams map[tcpFlow]*tcpStream
 }
 
-func NewTracer() (*Tracer, error) {
+func NewTracer(config *Config) (*Tracer, error) {
 	spec, err := loadDns()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load asset: %w", err)
 	}
 
-	return &Tracer{
-		Tracer: networktracer.NewTracer(
-			spec,
-			BPFProgName,
-			BPFPerfMapName,
-			BPFSocketAttach,
-			types.Base,
-			parseDNSEvent,
-		),
-	}, nil
+	t := &Tracer{
+		config:  config,
+		queries: make(map[queryKey]uint64),
+		streams: make(map[tcpFlow]*tcpStream),
+	}
+	t.Tracer = networktracer.NewTracer(
+		spec,
+		BPFProgName,
+		BPFPerfMapName,
+		BPFSocketAttach,
+		types.Base,
+		t.parseDNSEvent,
+	)
+
+	return t, nil
+}
+
+// addQuery remembers the timestamp of a query to compute the latency when
+// the response comes.
+func (t *Tracer) addQuery(key queryKey, timestamp uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.queries) >= maxPendingQueries {
+		for k, ts := range t.queries {
+			if timestamp-ts > uint64(queryTimeout) {
+				delete(t.queries, k)
+			}
+		}
+		if len(t.queries) >= maxPendingQueries {
+			return
+		}
+	}
+
+	t.queries[key] = timestamp
+}
+
+// responseLatency returns the time elapsed since the query of the given
+// response, or zero if the query wasn't seen.
+func (t *Tracer) responseLatency(key queryKey, timestamp uint64) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ts, ok := t.queries[key]
+	if !ok {
+		return 0
+	}
+	delete(t.queries, key)
+
+	if timestamp < ts {
+		return 0
+	}
+	return timestamp - ts
 }
 
 // pkt_type definitions:
@@ -195,39 +279,45 @@ func parseLabelSequence(sample []byte) (ret string) {
 	return ret
 }
 
-func parseDNSEvent(rawSample []byte) (*types.Event, error) {
-	event := types.Event{
-		Event: eventtypes.Event{
-			Type: eventtypes.NORMAL,
-		},
-	}
-
+func (t *Tracer) parseDNSEvent(netns uint64, rawSample []byte) ([]*types.Event, error) {
 	bpfEvent := (*dnsEventT)(unsafe.Pointer(&rawSample[0]))
 	if len(rawSample) < int(unsafe.Sizeof(*bpfEvent)) {
 		return nil, errors.New("invalid sample size")
 	}
 
-	event.ID = fmt.Sprintf("%.4x", bpfEvent.Id)
+	// The packet is appended to the event with responses and TCP segments
+	packet := rawSample[unsafe.Sizeof(*bpfEvent):]
 
-	if bpfEvent.Qr == 1 {
-		event.Qr = types.DNSPktTypeResponse
-		if bpfEvent.Af == syscall.AF_INET {
-			event.Nameserver = gadgets.IPStringFromBytes(bpfEvent.SaddrV6, 4)
-		} else if bpfEvent.Af == syscall.AF_INET6 {
-			event.Nameserver = gadgets.IPStringFromBytes(bpfEvent.SaddrV6, 6)
-		}
-	} else {
-		event.Qr = types.DNSPktTypeQuery
-		if bpfEvent.Af == syscall.AF_INET {
-			event.Nameserver = gadgets.IPStringFromBytes(bpfEvent.DaddrV6, 4)
-		} else if bpfEvent.Af == syscall.AF_INET6 {
-			event.Nameserver = gadgets.IPStringFromBytes(bpfEvent.DaddrV6, 6)
-		}
+	if bpfEvent.Proto == syscall.IPPROTO_TCP {
+		return t.parseTCPSegment(netns, bpfEvent, packet), nil
 	}
 
+	event := newEvent(bpfEvent, "UDP")
+	key := setDirection(&event, netns, bpfEvent, bpfEvent.Id, bpfEvent.Qr)
+
 	// Convert name into a string with dots
 	event.DNSName = parseLabelSequence(rawSample[unsafe.Offsetof(bpfEvent.Name):])
 
+	event.QType = qTypeName(bpfEvent.Qtype)
+
+	var msg []byte
+	if len(packet) > dnsOffset {
+		msg = packet[dnsOffset:]
+	}
+
+	return t.pairEvent(&event, key, bpfEvent.Timestamp, bpfEvent.Rcode, msg), nil
+}
+
+// newEvent creates an event with the fields that don't depend on the DNS
+// message.
+func newEvent(bpfEvent *dnsEventT, protocol string) types.Event {
+	event := types.Event{
+		Event: eventtypes.Event{
+			Type: eventtypes.NORMAL,
+		},
+		Protocol: protocol,
+	}
+
 	// Parse the packet type
 	event.PktType = "UNKNOWN"
 	pktTypeUint := uint(bpfEvent.PktType)
@@ -235,20 +325,69 @@ func parseDNSEvent(rawSample []byte) (*types.Event, error) {
 		event.PktType = pktTypeNames[pktTypeUint]
 	}
 
-	qTypeUint := uint(bpfEvent.Qtype)
-	var ok bool
-	event.QType, ok = qTypeNames[qTypeUint]
+	return event
+}
+
+// setDirection sets the ID and the query or response fields of the event and
+// returns the key identifying the query in the network namespace.
+func setDirection(event *types.Event, netns uint64, bpfEvent *dnsEventT, id uint16, qr uint8) queryKey {
+	ipVersion := 0
+	if bpfEvent.Af == syscall.AF_INET {
+		ipVersion = 4
+	} else if bpfEvent.Af == syscall.AF_INET6 {
+		ipVersion = 6
+	}
+
+	event.ID = fmt.Sprintf("%.4x", id)
+
+	// The client is the destination of responses and the source of
+	// queries.
+	key := queryKey{netns: netns, id: id}
+	if qr == 1 {
+		event.Qr = types.DNSPktTypeResponse
+		event.Nameserver = gadgets.IPStringFromBytes(bpfEvent.SaddrV6, ipVersion)
+		key.clientAddr, key.clientPort = bpfEvent.DaddrV6, bpfEvent.Dport
+		key.serverAddr, key.serverPort = bpfEvent.SaddrV6, bpfEvent.Sport
+	} else {
+		event.Qr = types.DNSPktTypeQuery
+		event.Nameserver = gadgets.IPStringFromBytes(bpfEvent.DaddrV6, ipVersion)
+		key.clientAddr, key.clientPort = bpfEvent.SaddrV6, bpfEvent.Sport
+		key.serverAddr, key.serverPort = bpfEvent.DaddrV6, bpfEvent.Dport
+	}
+	return key
+}
+
+func qTypeName(qtype uint16) string {
+	name, ok := qTypeNames[uint(qtype)]
 	if !ok {
-		event.QType = "UNASSIGNED"
+		return "UNASSIGNED"
 	}
+	return name
+}
 
-	if bpfEvent.Qr == 1 {
-		rCodeUint := uint8(bpfEvent.Rcode)
-		event.Rcode, ok = rCodeNames[rCodeUint]
-		if !ok {
-			event.Rcode = "UNKNOWN"
+// pairEvent remembers queries and completes responses with the latency since
+// their query, the response code and the answers found in msg, if any. It
+// returns the events to emit.
+func (t *Tracer) pairEvent(event *types.Event, key queryKey, timestamp uint64, rcode uint8, msg []byte) []*types.Event {
+	if event.Qr == types.DNSPktTypeQuery {
+		t.addQuery(key, timestamp)
+		if t.config.ResponsesOnly {
+			return nil
 		}
+		return []*types.Event{event}
+	}
+
+	var ok bool
+	event.Rcode, ok = rCodeNames[rcode]
+	if !ok {
+		event.Rcode = "UNKNOWN"
+	}
+
+	event.Latency = t.responseLatency(key, timestamp)
+
+	if msg != nil {
+		event.Addresses = parseAddresses(msg)
 	}
 
-	return &event, nil
+	return []*types.Event{event}
 }
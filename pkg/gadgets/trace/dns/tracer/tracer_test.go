@@ -15,6 +15,7 @@
 package tracer
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -67,3 +68,289 @@ func TestParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAddresses(t *testing.T) {
+	header := []byte{
+		0x12, 0x34, // ID
+		0x81, 0x80, // flags
+		0, 1, // QDCOUNT
+		0, 3, // ANCOUNT
+		0, 0, // NSCOUNT
+		0, 0, // ARCOUNT
+	}
+	question := []byte{
+		3, 'w', 'w', 'w',
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e',
+		3, 'c', 'o', 'm',
+		0,
+		0, 1, // QTYPE
+		0, 1, // QCLASS
+	}
+	answers := []byte{
+		// www.example.com. CNAME example.com.
+		0xc0, 12, // pointer to the name in the question
+		0, 5, 0, 1, 0, 0, 0, 60,
+		0, 2, // RDLENGTH
+		0xc0, 16, // pointer to example.com.
+		// example.com. A 93.184.216.34
+		0xc0, 16,
+		0, 1, 0, 1, 0, 0, 0, 60,
+		0, 4,
+		93, 184, 216, 34,
+		// example.com. AAAA 2606:2800:220:1:248:1893:25c8:1946
+		0xc0, 16,
+		0, 28, 0, 1, 0, 0, 0, 60,
+		0, 16,
+		0x26, 0x06, 0x28, 0x00, 0x02, 0x20, 0x00, 0x01,
+		0x02, 0x48, 0x18, 0x93, 0x25, 0xc8, 0x19, 0x46,
+	}
+
+	msg := append(append(append([]byte{}, header...), question...), answers...)
+
+	table := []struct {
+		description string
+		input       []byte
+		output      []string
+	}{
+		{
+			description: "CNAME, A and AAAA answers",
+			input:       msg,
+			output: []string{
+				"example.com.",
+				"93.184.216.34",
+				"2606:2800:220:1:248:1893:25c8:1946",
+			},
+		},
+		{
+			description: "truncated message",
+			input:       msg[:len(msg)-10],
+			output:      []string{"example.com.", "93.184.216.34"},
+		},
+		{
+			description: "pointer loop",
+			input: []byte{
+				0x12, 0x34, 0x81, 0x80,
+				0, 0, 0, 1, 0, 0, 0, 0,
+				0xc0, 12, // pointer to itself
+				0, 1, 0, 1, 0, 0, 0, 60,
+				0, 4,
+				1, 2, 3, 4,
+			},
+			output: nil,
+		},
+		{
+			description: "short message",
+			input:       header[:4],
+			output:      nil,
+		},
+	}
+
+	for _, entry := range table {
+		output := parseAddresses(entry.input)
+		if !reflect.DeepEqual(output, entry.output) {
+			t.Fatalf("Failed test %q: got %q, expected %q", entry.description, output, entry.output)
+		}
+	}
+}
+
+func TestQueryLatency(t *testing.T) {
+	tracer := &Tracer{
+		config:  &Config{},
+		queries: make(map[queryKey]uint64),
+	}
+
+	key := queryKey{id: 0x1234, clientPort: 40000, serverPort: 53}
+	other := key
+	other.clientPort = 40001
+
+	// The same addresses used in another network namespace
+	otherNetns := key
+	otherNetns.netns = 4026532000
+
+	tracer.addQuery(key, 1000)
+	if latency := tracer.responseLatency(other, 3000); latency != 0 {
+		t.Fatalf("Got latency %d for a response without query", latency)
+	}
+	if latency := tracer.responseLatency(otherNetns, 3000); latency != 0 {
+		t.Fatalf("Got latency %d for a response in another network namespace", latency)
+	}
+	if latency := tracer.responseLatency(key, 3000); latency != 2000 {
+		t.Fatalf("Got latency %d, expected 2000", latency)
+	}
+	if latency := tracer.responseLatency(key, 4000); latency != 0 {
+		t.Fatalf("Got latency %d for a duplicated response", latency)
+	}
+
+	// Old queries are forgotten once the limit is reached
+	for i := 0; i < maxPendingQueries; i++ {
+		tracer.addQuery(queryKey{id: uint16(i)}, 0)
+	}
+	tracer.addQuery(key, uint64(queryTimeout)+1)
+	if n := len(tracer.queries); n != 1 {
+		t.Fatalf("Got %d pending queries, expected 1", n)
+	}
+}
+
+func TestTCPStream(t *testing.T) {
+	// Two messages prefixed with their length
+	stream := []byte{0, 3, 'a', 'b', 'c', 0, 2, 'd', 'e'}
+
+	type segment struct {
+		seq     uint32
+		payload []byte
+	}
+
+	table := []struct {
+		description string
+		segments    []segment
+		output      []string
+	}{
+		{
+			description: "two messages in one segment",
+			segments:    []segment{{100, stream}},
+			output:      []string{"abc", "de"},
+		},
+		{
+			description: "messages split across segments",
+			segments: []segment{
+				{100, stream[:1]},
+				{101, stream[1:4]},
+				{104, stream[4:8]},
+				{108, stream[8:]},
+			},
+			output: []string{"abc", "de"},
+		},
+		{
+			description: "out of order segments",
+			segments: []segment{
+				{100, stream[:2]},
+				{105, stream[5:]},
+				{102, stream[2:5]},
+			},
+			output: []string{"abc", "de"},
+		},
+		{
+			description: "retransmissions",
+			segments: []segment{
+				{100, stream[:4]},
+				{100, stream[:4]},
+				{102, stream[2:7]},
+				{107, stream[7:]},
+			},
+			output: []string{"abc", "de"},
+		},
+		{
+			description: "sequence number wrap around",
+			segments: []segment{
+				{0xFFFFFFFE, stream[:4]},
+				{2, stream[4:]},
+			},
+			output: []string{"abc", "de"},
+		},
+		{
+			description: "incomplete message",
+			segments:    []segment{{100, stream[:8]}},
+			output:      []string{"abc"},
+		},
+	}
+
+	for _, entry := range table {
+		s := newTCPStream(entry.segments[0].seq)
+		var output []string
+		for _, seg := range entry.segments {
+			msgs, ok := s.push(seg.seq, seg.payload)
+			if !ok {
+				t.Fatalf("Failed test %q: stream dropped", entry.description)
+			}
+			for _, msg := range msgs {
+				output = append(output, string(msg))
+			}
+		}
+		if !reflect.DeepEqual(output, entry.output) {
+			t.Fatalf("Failed test %q: got %q, expected %q", entry.description, output, entry.output)
+		}
+	}
+
+	// Streams with too many holes are dropped
+	s := newTCPStream(0)
+	for i := 1; i <= maxPendingSegments; i++ {
+		if _, ok := s.push(uint32(i*10), []byte{0}); !ok {
+			t.Fatalf("Stream dropped after %d out of order segments", i)
+		}
+	}
+	if _, ok := s.push(1000, []byte{0}); ok {
+		t.Fatalf("Stream not dropped after %d out of order segments", maxPendingSegments+1)
+	}
+}
+
+func TestTCPStreamsByNetns(t *testing.T) {
+	tracer := &Tracer{
+		config:  &Config{},
+		streams: make(map[tcpFlow]*tcpStream),
+	}
+
+	// The same connection in two network namespaces, with their segments
+	// interleaved.
+	flow1 := tcpFlow{netns: 4026531840, srcPort: 40000, dstPort: 53}
+	flow2 := flow1
+	flow2.netns = 4026532000
+
+	if msgs := tracer.pushSegment(flow1, tcpSegment{seq: 100, payload: []byte{0, 3, 'a'}}, 0); len(msgs) != 0 {
+		t.Fatalf("Got messages %q from an incomplete message", msgs)
+	}
+	if msgs := tracer.pushSegment(flow2, tcpSegment{seq: 500, payload: []byte{0, 2, 'x', 'y'}}, 0); len(msgs) != 1 || string(msgs[0]) != "xy" {
+		t.Fatalf("Got messages %q, expected [\"xy\"]", msgs)
+	}
+	if msgs := tracer.pushSegment(flow1, tcpSegment{seq: 103, payload: []byte{'b', 'c'}}, 0); len(msgs) != 1 || string(msgs[0]) != "abc" {
+		t.Fatalf("Got messages %q, expected [\"abc\"]", msgs)
+	}
+}
+
+func TestParseTCPPacket(t *testing.T) {
+	packet := make([]byte, 14+20+20+4)
+	packet[14] = 0x45                       // IPv4, IHL 5
+	packet[14+2], packet[14+3] = 0, 20+20+2 // total length
+	tcp := packet[14+20:]
+	tcp[4], tcp[5], tcp[6], tcp[7] = 0, 0, 1, 0 // sequence number
+	tcp[12] = 5 << 4                            // data offset
+	tcp[13] = tcpFlagFIN
+	tcp[20], tcp[21] = 0, 7 // payload, followed by padding
+
+	segment, ok := parseTCPPacket(packet)
+	if !ok {
+		t.Fatalf("Failed to parse packet")
+	}
+	if segment.seq != 256 || !segment.fin || segment.rst || !reflect.DeepEqual(segment.payload, []byte{0, 7}) {
+		t.Fatalf("Got unexpected segment %+v", segment)
+	}
+
+	if _, ok := parseTCPPacket(packet[:14+20+20+1]); ok {
+		t.Fatalf("Truncated packet parsed")
+	}
+}
+
+func TestParseDNSMessage(t *testing.T) {
+	msg := []byte{
+		0x12, 0x34, // ID
+		0x81, 0x83, // flags: response, NXDomain
+		0, 1, 0, 0, 0, 0, 0, 0,
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e',
+		3, 'c', 'o', 'm',
+		0,
+		0, 28, // QTYPE
+		0, 1, // QCLASS
+	}
+
+	m, ok := parseDNSMessage(msg)
+	if !ok {
+		t.Fatalf("Failed to parse message")
+	}
+	expected := dnsMessage{id: 0x1234, qr: 1, rcode: 3, name: "example.com.", qtype: 28}
+	if m != expected {
+		t.Fatalf("Got %+v, expected %+v", m, expected)
+	}
+
+	if _, ok := parseDNSMessage(msg[:20]); ok {
+		t.Fatalf("Truncated message parsed")
+	}
+}
@@ -15,6 +15,9 @@
 package types
 
 import (
+	"strings"
+	"time"
+
 	"github.com/lato333/inspektor-gadget/pkg/columns"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
@@ -33,9 +36,15 @@ type Event struct {
 	Qr         DNSPktType `json:"qr,omitempty" column:"qr,width:2,fixed"`
 	Nameserver string     `json:"nameserver,omitempty" column:"nameserver,template:ipaddr"`
 	PktType    string     `json:"pktType,omitempty" column:"type,minWidth:7,maxWidth:9"`
+	Protocol   string     `json:"protocol,omitempty" column:"proto,width:5,fixed,hide"`
 	QType      string     `json:"qtype,omitempty" column:"qtype,minWidth:5,maxWidth:10"`
 	DNSName    string     `json:"name,omitempty" column:"name,width:30"`
 	Rcode      string     `json:"rcode,omitempty" column:"rcode,minWidth:8"`
+
+	// Latency is the time between the query and the response in
+	// nanoseconds. It's only set in responses.
+	Latency   uint64   `json:"latency,omitempty" column:"latency,width:10,align:right"`
+	Addresses []string `json:"addresses,omitempty" column:"addresses,width:32"`
 }
 
 func GetColumns() *columns.Columns[Event] {
@@ -44,6 +53,16 @@ func GetColumns() *columns.Columns[Event] {
 	col, _ := cols.GetColumn("container")
 	col.Visible = false
 
+	cols.MustSetExtractor("latency", func(event *Event) string {
+		if event.Latency == 0 {
+			return ""
+		}
+		return time.Duration(event.Latency).String()
+	})
+	cols.MustSetExtractor("addresses", func(event *Event) string {
+		return strings.Join(event.Addresses, ",")
+	})
+
 	return cols
 }
 
@@ -34,10 +34,94 @@ struct {
 	__uint(value_size, sizeof(u32));
 } mount_ns_filter SEC(".maps");
 
+struct path_buf {
+	__u8 data[PATH_LEN * 2];
+};
+
+// path_bufs is used to build paths from their last component. It's twice as
+// big as the paths so that the verifier can check the accesses with masks.
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, u32);
+	__type(value, struct path_buf);
+} path_bufs SEC(".maps");
+
 static __always_inline bool valid_uid(uid_t uid) {
 	return uid != INVALID_UID;
 }
 
+#define container_of(ptr, type, member) \
+	((type *)((void *)(ptr) - \
+		  __builtin_preserve_field_info(((type *)0)->member, BPF_FIELD_BYTE_OFFSET)))
+
+// read_path writes in out the path of a file relative to root, the root
+// directory of the task. Mount points are crossed like d_path() does, so
+// paths in containers are relative to their root and not to the host's one.
+// Paths too long or too deep are truncated at their beginning.
+static __always_inline void read_path(const struct path *path,
+				      const struct path *root, __u8 *out)
+{
+	struct dentry *dentry = BPF_CORE_READ(path, dentry);
+	struct vfsmount *vfsmnt = BPF_CORE_READ(path, mnt);
+	struct dentry *root_dentry = BPF_CORE_READ(root, dentry);
+	struct vfsmount *root_mnt = BPF_CORE_READ(root, mnt);
+	struct mount *mnt = container_of(vfsmnt, struct mount, mnt);
+	struct mount *mnt_parent;
+	struct dentry *parent;
+	const unsigned char *name;
+	struct path_buf *buf;
+	u32 off = PATH_LEN;
+	u32 zero = 0;
+	u32 len;
+	int i;
+
+	buf = bpf_map_lookup_elem(&path_bufs, &zero);
+	if (!buf)
+		return;
+
+	#pragma unroll
+	for (i = 0; i < MAX_PATH_DEPTH; i++) {
+		if (dentry == root_dentry && vfsmnt == root_mnt)
+			break;
+
+		if (dentry == BPF_CORE_READ(vfsmnt, mnt_root)) {
+			// Go to the mount point in the parent mount
+			mnt_parent = BPF_CORE_READ(mnt, mnt_parent);
+			if (mnt_parent == mnt)
+				break;
+			dentry = BPF_CORE_READ(mnt, mnt_mountpoint);
+			mnt = mnt_parent;
+			vfsmnt = __builtin_preserve_access_index(&mnt->mnt);
+			continue;
+		}
+
+		parent = BPF_CORE_READ(dentry, d_parent);
+		if (parent == dentry)
+			break;
+
+		len = BPF_CORE_READ(dentry, d_name.len);
+		name = BPF_CORE_READ(dentry, d_name.name);
+		// Keep one byte to add the leading '/' of the path
+		if (len == 0 || len > NAME_MAX || len + 1 >= off)
+			break;
+
+		off -= len + 1;
+		buf->data[off & (PATH_LEN - 1)] = '/';
+		bpf_probe_read_kernel(&buf->data[(off + 1) & (PATH_LEN - 1)],
+				      len & NAME_MAX, name);
+
+		dentry = parent;
+	}
+
+	if (off == PATH_LEN)
+		buf->data[--off] = '/';
+
+	len = PATH_LEN - off;
+	bpf_probe_read_kernel(out, len & (PATH_LEN - 1), &buf->data[off & (PATH_LEN - 1)]);
+	out[len & (PATH_LEN - 1)] = '\0';
+}
+
 #ifdef __TARGET_ARCH_arm64
 SEC("kprobe/do_execveat_common.isra.0")
 int BPF_KPROBE(ig_execveat_e)
@@ -138,6 +222,9 @@ int ig_execve_x(struct trace_event_raw_sys_exit* ctx)
 	pid_t pid;
 	int ret;
 	struct event *event;
+	struct task_struct *task;
+	struct fs_struct *fs;
+	struct file *exe_file;
 	u32 uid = (u32)bpf_get_current_uid_gid();
 
 	if (valid_uid(targ_uid) && targ_uid != uid)
@@ -157,6 +244,17 @@ int ig_execve_x(struct trace_event_raw_sys_exit* ctx)
 
 	event->retval = ret;
 	bpf_get_current_comm(&event->comm, sizeof(event->comm));
+
+	task = (struct task_struct*)bpf_get_current_task();
+	fs = BPF_CORE_READ(task, fs);
+	if (ret >= 0) {
+		exe_file = BPF_CORE_READ(task, mm, exe_file);
+		read_path(__builtin_preserve_access_index(&exe_file->f_path),
+			  __builtin_preserve_access_index(&fs->root), event->exepath);
+	}
+	read_path(__builtin_preserve_access_index(&fs->pwd),
+		  __builtin_preserve_access_index(&fs->root), event->cwd);
+
 	size_t len = EVENT_SIZE(event);
 	if (len <= sizeof(*event))
 		bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, event, len);
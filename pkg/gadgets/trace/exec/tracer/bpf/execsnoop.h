@@ -11,6 +11,10 @@
 #define BASE_EVENT_SIZE (size_t)(&((struct event*)0)->args)
 #define EVENT_SIZE(e) (BASE_EVENT_SIZE + e->args_size)
 #define LAST_ARG (FULL_MAX_ARGS_ARR - ARGSIZE)
+/* PATH_LEN must be a power of 2, and bigger than NAME_MAX + 1 */
+#define PATH_LEN 512
+#define MAX_PATH_DEPTH 32
+#define NAME_MAX 255
 
 struct event {
 	__u64 mntns_id;
@@ -21,6 +25,8 @@ struct event {
 	int args_count;
 	unsigned int args_size;
 	__u8 comm[TASK_COMM_LEN];
+	__u8 exepath[PATH_LEN];
+	__u8 cwd[PATH_LEN];
 	__u8 args[FULL_MAX_ARGS_ARR];
 };
 
@@ -22,9 +22,13 @@ type execsnoopEvent struct {
 	ArgsCount int32
 	ArgsSize  uint32
 	Comm      [16]uint8
+	Exepath   [512]uint8
+	Cwd       [512]uint8
 	Args      [7680]uint8
 }
 
+type execsnoopPathBuf struct{ Data [1024]uint8 }
+
 // loadExecsnoop returns the embedded CollectionSpec for execsnoop.
 func loadExecsnoop() (*ebpf.CollectionSpec, error) {
 	reader := bytes.NewReader(_ExecsnoopBytes)
@@ -77,6 +81,7 @@ type execsnoopMapSpecs struct {
 	Events        *ebpf.MapSpec `ebpf:"events"`
 	Execs         *ebpf.MapSpec `ebpf:"execs"`
 	MountNsFilter *ebpf.MapSpec `ebpf:"mount_ns_filter"`
+	PathBufs      *ebpf.MapSpec `ebpf:"path_bufs"`
 }
 
 // execsnoopObjects contains all objects after they have been loaded into the kernel.
@@ -101,6 +106,7 @@ type execsnoopMaps struct {
 	Events        *ebpf.Map `ebpf:"events"`
 	Execs         *ebpf.Map `ebpf:"execs"`
 	MountNsFilter *ebpf.Map `ebpf:"mount_ns_filter"`
+	PathBufs      *ebpf.Map `ebpf:"path_bufs"`
 }
 
 func (m *execsnoopMaps) Close() error {
@@ -108,6 +114,7 @@ func (m *execsnoopMaps) Close() error {
 		m.Events,
 		m.Execs,
 		m.MountNsFilter,
+		m.PathBufs,
 	)
 }
 
@@ -136,5 +143,6 @@ func _ExecsnoopClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed execsnoop_bpfel_arm64.o
 var _ExecsnoopBytes []byte
@@ -154,6 +154,8 @@ func (t *Tracer) run() {
 			MountNsID: bpfEvent.MntnsId,
 			Retval:    int(bpfEvent.Retval),
 			Comm:      gadgets.FromCString(bpfEvent.Comm[:]),
+			ExePath:   gadgets.FromCString(bpfEvent.Exepath[:]),
+			Cwd:       gadgets.FromCString(bpfEvent.Cwd[:]),
 		}
 
 		argsCount := 0
@@ -19,7 +19,9 @@ package tracer_test
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -61,6 +63,17 @@ func TestExecTracer(t *testing.T) {
 
 	const unprivilegedUID = int(1435)
 
+	// The executable path is the one of the file executed, with the
+	// symbolic links resolved.
+	catPath, err := filepath.EvalSymlinks("/bin/cat")
+	if err != nil {
+		t.Fatalf("Error resolving /bin/cat: %s", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Error getting current directory: %s", err)
+	}
+
 	manyArgs := []string{}
 	// 19 is DEFAULT_MAXARGS - 1 (-1 because args[0] is on the first position).
 	for i := 0; i < 19; i++ {
@@ -92,6 +105,8 @@ func TestExecTracer(t *testing.T) {
 					Retval:    0,
 					Comm:      "cat",
 					Args:      []string{"/bin/cat", "/dev/null"},
+					ExePath:   catPath,
+					Cwd:       cwd,
 				}
 			}),
 		},
@@ -123,6 +138,8 @@ func TestExecTracer(t *testing.T) {
 					Retval:    0,
 					Comm:      "cat",
 					Args:      []string{"/bin/cat", "/dev/null"},
+					ExePath:   catPath,
+					Cwd:       cwd,
 				}
 			}),
 		},
@@ -143,6 +160,32 @@ func TestExecTracer(t *testing.T) {
 					"Event has bad UID")
 			},
 		},
+		"event_has_cwd_of_process": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func() (int, error) {
+				cmd := exec.Command("/bin/cat", "/dev/null")
+				cmd.Dir = "/"
+				if err := cmd.Run(); err != nil {
+					return 0, fmt.Errorf("running command: %w", err)
+				}
+
+				return cmd.Process.Pid, nil
+			},
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, _ int, events []types.Event) {
+				if len(events) != 1 {
+					t.Fatalf("One event expected")
+				}
+
+				utilstest.Equal(t, "/", events[0].Cwd,
+					"Event has bad cwd")
+				utilstest.Equal(t, catPath, events[0].ExePath,
+					"Event has bad exepath")
+			},
+		},
 		"truncates_captured_args_in_trace_to_maximum_possible_length": {
 			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
 				return &tracer.Config{
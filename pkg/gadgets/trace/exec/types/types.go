@@ -29,6 +29,8 @@ type Event struct {
 	Comm      string   `json:"comm,omitempty" column:"comm,template:comm"`
 	Retval    int      `json:"ret,omitempty" column:"ret,width:3,fixed"`
 	Args      []string `json:"args,omitempty" column:"args,width:40"`
+	ExePath   string   `json:"exepath,omitempty" column:"exepath,width:40,hide"`
+	Cwd       string   `json:"cwd,omitempty" column:"cwd,width:40,hide"`
 	UID       uint32   `json:"uid,omitempty" column:"uid,minWidth:10,hide"`
 	MountNsID uint64   `json:"mountnsid,omitempty" column:"mntns,template:ns"`
 }
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: GPL-2.0
+/* Copyright (c) 2022 The Inspektor Gadget authors */
+#include <vmlinux/vmlinux.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+
+#include "exitsnoop.h"
+
+#define MAX_ENTRIES	10240
+
+const volatile bool filter_by_mnt_ns = false;
+const volatile bool failed_only = false;
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event *unusedevent __attribute__((unused));
+
+struct exec_info {
+	__u64 timestamp;
+	__u8 filename[FILENAME_LEN];
+};
+
+// execs keeps the processes that called exec since the tracer started, to
+// pair their exit with it. It's an LRU map because processes still running
+// when the tracer stops are never removed from it.
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, __u32);
+	__type(value, struct exec_info);
+} execs SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+	__uint(key_size, sizeof(u32));
+	__uint(value_size, sizeof(u32));
+} events SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 1024);
+	__uint(key_size, sizeof(u64));
+	__uint(value_size, sizeof(u32));
+} mount_ns_filter SEC(".maps");
+
+static __always_inline u64 get_mntns_id(struct task_struct *task)
+{
+	return (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+}
+
+SEC("tracepoint/sched/sched_process_exec")
+int ig_sched_exec(struct trace_event_raw_sched_process_exec *ctx)
+{
+	struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+	struct exec_info info = {};
+	unsigned int filename_off;
+	u64 mntns_id;
+	__u32 pid;
+
+	mntns_id = get_mntns_id(task);
+	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+		return 0;
+
+	pid = bpf_get_current_pid_tgid() >> 32;
+	info.timestamp = bpf_ktime_get_ns();
+
+	// The filename is a dynamic array of the tracepoint: the lower 16 bits
+	// of __data_loc_filename are its offset in the context.
+	filename_off = ctx->__data_loc_filename & 0xFFFF;
+	bpf_probe_read_kernel_str(info.filename, sizeof(info.filename),
+				  (void *)ctx + filename_off);
+
+	bpf_map_update_elem(&execs, &pid, &info, BPF_ANY);
+	return 0;
+}
+
+SEC("tracepoint/sched/sched_process_exit")
+int ig_sched_exit(void *ctx)
+{
+	struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+	struct event event = {};
+	struct exec_info *info;
+	u64 now = bpf_ktime_get_ns();
+	u64 mntns_id;
+	__u32 pid;
+
+	// The live counter of the thread group is decremented before the
+	// tracepoint: only report the exit of its last thread, that is the
+	// exit of the process.
+	if (BPF_CORE_READ(task, signal, live.counter) != 0)
+		return 0;
+
+	mntns_id = get_mntns_id(task);
+	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+		return 0;
+
+	pid = bpf_get_current_pid_tgid() >> 32;
+	info = bpf_map_lookup_elem(&execs, &pid);
+
+	event.exit_code = BPF_CORE_READ(task, exit_code);
+	if (failed_only && event.exit_code == 0)
+		goto cleanup;
+
+	event.pid = pid;
+	event.ppid = BPF_CORE_READ(task, real_parent, tgid);
+	event.mntns_id = mntns_id;
+	event.lifetime = now - BPF_CORE_READ(task, group_leader, start_time);
+	bpf_get_current_comm(&event.comm, sizeof(event.comm));
+
+	if (info) {
+		event.exec_lifetime = now - info->timestamp;
+		__builtin_memcpy(event.filename, info->filename, sizeof(event.filename));
+	}
+
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
+
+cleanup:
+	if (info)
+		bpf_map_delete_elem(&execs, &pid);
+	return 0;
+}
+
+char LICENSE[] SEC("license") = "GPL";
@@ -0,0 +1,22 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+#ifndef __EXITSNOOP_H
+#define __EXITSNOOP_H
+
+#define TASK_COMM_LEN	16
+#define FILENAME_LEN	256
+
+struct event {
+	__u64 mntns_id;
+	// lifetime is the time since the process was forked, exec_lifetime the
+	// time since its last exec if it was seen by the tracer.
+	__u64 lifetime;
+	__u64 exec_lifetime;
+	__u32 pid;
+	__u32 ppid;
+	// exit_code is encoded like the status returned by wait(2)
+	int exit_code;
+	__u8 comm[TASK_COMM_LEN];
+	__u8 filename[FILENAME_LEN];
+};
+
+#endif /* __EXITSNOOP_H */
@@ -0,0 +1,146 @@
+// Code generated by bpf2go; DO NOT EDIT.
+//go:build arm64
+// +build arm64
+
+package tracer
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+
+	"github.com/cilium/ebpf"
+)
+
+type exitsnoopEvent struct {
+	MntnsId      uint64
+	Lifetime     uint64
+	ExecLifetime uint64
+	Pid          uint32
+	Ppid         uint32
+	ExitCode     int32
+	Comm         [16]uint8
+	Filename     [256]uint8
+	_            [4]byte
+}
+
+type exitsnoopExecInfo struct {
+	Timestamp uint64
+	Filename  [256]uint8
+}
+
+// loadExitsnoop returns the embedded CollectionSpec for exitsnoop.
+func loadExitsnoop() (*ebpf.CollectionSpec, error) {
+	reader := bytes.NewReader(_ExitsnoopBytes)
+	spec, err := ebpf.LoadCollectionSpecFromReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("can't load exitsnoop: %w", err)
+	}
+
+	return spec, err
+}
+
+// loadExitsnoopObjects loads exitsnoop and converts it into a struct.
+//
+// The following types are suitable as obj argument:
+//
+//	*exitsnoopObjects
+//	*exitsnoopPrograms
+//	*exitsnoopMaps
+//
+// See ebpf.CollectionSpec.LoadAndAssign documentation for details.
+func loadExitsnoopObjects(obj interface{}, opts *ebpf.CollectionOptions) error {
+	spec, err := loadExitsnoop()
+	if err != nil {
+		return err
+	}
+
+	return spec.LoadAndAssign(obj, opts)
+}
+
+// exitsnoopSpecs contains maps and programs before they are loaded into the kernel.
+//
+// It can be passed ebpf.CollectionSpec.Assign.
+type exitsnoopSpecs struct {
+	exitsnoopProgramSpecs
+	exitsnoopMapSpecs
+}
+
+// exitsnoopSpecs contains programs before they are loaded into the kernel.
+//
+// It can be passed ebpf.CollectionSpec.Assign.
+type exitsnoopProgramSpecs struct {
+	IgSchedExec *ebpf.ProgramSpec `ebpf:"ig_sched_exec"`
+	IgSchedExit *ebpf.ProgramSpec `ebpf:"ig_sched_exit"`
+}
+
+// exitsnoopMapSpecs contains maps before they are loaded into the kernel.
+//
+// It can be passed ebpf.CollectionSpec.Assign.
+type exitsnoopMapSpecs struct {
+	Events        *ebpf.MapSpec `ebpf:"events"`
+	Execs         *ebpf.MapSpec `ebpf:"execs"`
+	MountNsFilter *ebpf.MapSpec `ebpf:"mount_ns_filter"`
+}
+
+// exitsnoopObjects contains all objects after they have been loaded into the kernel.
+//
+// It can be passed to loadExitsnoopObjects or ebpf.CollectionSpec.LoadAndAssign.
+type exitsnoopObjects struct {
+	exitsnoopPrograms
+	exitsnoopMaps
+}
+
+func (o *exitsnoopObjects) Close() error {
+	return _ExitsnoopClose(
+		&o.exitsnoopPrograms,
+		&o.exitsnoopMaps,
+	)
+}
+
+// exitsnoopMaps contains all maps after they have been loaded into the kernel.
+//
+// It can be passed to loadExitsnoopObjects or ebpf.CollectionSpec.LoadAndAssign.
+type exitsnoopMaps struct {
+	Events        *ebpf.Map `ebpf:"events"`
+	Execs         *ebpf.Map `ebpf:"execs"`
+	MountNsFilter *ebpf.Map `ebpf:"mount_ns_filter"`
+}
+
+func (m *exitsnoopMaps) Close() error {
+	return _ExitsnoopClose(
+		m.Events,
+		m.Execs,
+		m.MountNsFilter,
+	)
+}
+
+// exitsnoopPrograms contains all programs after they have been loaded into the kernel.
+//
+// It can be passed to loadExitsnoopObjects or ebpf.CollectionSpec.LoadAndAssign.
+type exitsnoopPrograms struct {
+	IgSchedExec *ebpf.Program `ebpf:"ig_sched_exec"`
+	IgSchedExit *ebpf.Program `ebpf:"ig_sched_exit"`
+}
+
+func (p *exitsnoopPrograms) Close() error {
+	return _ExitsnoopClose(
+		p.IgSchedExec,
+		p.IgSchedExit,
+	)
+}
+
+func _ExitsnoopClose(closers ...io.Closer) error {
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Do not access this directly.
+//
+//go:embed exitsnoop_bpfel_arm64.o
+var _ExitsnoopBytes []byte
@@ -0,0 +1,194 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/exit/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event exitsnoop ./bpf/exitsnoop.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+type Config struct {
+	MountnsMap *ebpf.Map
+
+	// FailedOnly only reports the processes that exited with a non-zero
+	// code or were killed by a signal.
+	FailedOnly bool
+}
+
+type Tracer struct {
+	config        *Config
+	enricher      gadgets.DataEnricher
+	eventCallback func(types.Event)
+
+	objs     exitsnoopObjects
+	execLink link.Link
+	exitLink link.Link
+	reader   *perf.Reader
+}
+
+func NewTracer(config *Config, enricher gadgets.DataEnricher,
+	eventCallback func(types.Event),
+) (*Tracer, error) {
+	t := &Tracer{
+		config:        config,
+		enricher:      enricher,
+		eventCallback: eventCallback,
+	}
+
+	if err := t.start(); err != nil {
+		t.Stop()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Tracer) Stop() {
+	t.execLink = gadgets.CloseLink(t.execLink)
+	t.exitLink = gadgets.CloseLink(t.exitLink)
+
+	if t.reader != nil {
+		t.reader.Close()
+	}
+
+	t.objs.Close()
+}
+
+func (t *Tracer) start() error {
+	spec, err := loadExitsnoop()
+	if err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	mapReplacements := map[string]*ebpf.Map{}
+	filterByMntNs := false
+
+	if t.config.MountnsMap != nil {
+		filterByMntNs = true
+		mapReplacements["mount_ns_filter"] = t.config.MountnsMap
+	}
+
+	consts := map[string]interface{}{
+		"filter_by_mnt_ns": filterByMntNs,
+		"failed_only":      t.config.FailedOnly,
+	}
+
+	if err := spec.RewriteConstants(consts); err != nil {
+		return fmt.Errorf("error RewriteConstants: %w", err)
+	}
+
+	opts := ebpf.CollectionOptions{
+		MapReplacements: mapReplacements,
+	}
+
+	if err := spec.LoadAndAssign(&t.objs, &opts); err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	t.execLink, err = link.Tracepoint("sched", "sched_process_exec", t.objs.IgSchedExec, nil)
+	if err != nil {
+		return fmt.Errorf("error opening tracepoint: %w", err)
+	}
+
+	t.exitLink, err = link.Tracepoint("sched", "sched_process_exit", t.objs.IgSchedExit, nil)
+	if err != nil {
+		return fmt.Errorf("error opening tracepoint: %w", err)
+	}
+
+	t.reader, err = perf.NewReader(t.objs.exitsnoopMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("error creating perf ring buffer: %w", err)
+	}
+
+	go t.run()
+
+	return nil
+}
+
+// decodeExitCode splits an exit code encoded like the status returned by
+// wait(2) into the code passed to exit(2), or the signal that terminated the
+// process and whether it dumped a core.
+func decodeExitCode(code int32) (int, string, bool) {
+	status := syscall.WaitStatus(code)
+	if status.Signaled() {
+		return 0, unix.SignalName(status.Signal()), status.CoreDump()
+	}
+	return status.ExitStatus(), "", false
+}
+
+func (t *Tracer) run() {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				// nothing to do, we're done
+				return
+			}
+
+			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.eventCallback(types.Base(eventtypes.Err(msg)))
+			return
+		}
+
+		if record.LostSamples > 0 {
+			msg := fmt.Sprintf("lost %d samples", record.LostSamples)
+			t.eventCallback(types.Base(eventtypes.Warn(msg)))
+			continue
+		}
+
+		bpfEvent := (*exitsnoopEvent)(unsafe.Pointer(&record.RawSample[0]))
+
+		exitCode, signal, coreDumped := decodeExitCode(bpfEvent.ExitCode)
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:          bpfEvent.Pid,
+			Ppid:         bpfEvent.Ppid,
+			Comm:         gadgets.FromCString(bpfEvent.Comm[:]),
+			ExitCode:     exitCode,
+			Signal:       signal,
+			CoreDumped:   coreDumped,
+			Lifetime:     bpfEvent.Lifetime,
+			Filename:     gadgets.FromCString(bpfEvent.Filename[:]),
+			ExecLifetime: bpfEvent.ExecLifetime,
+			MountNsID:    bpfEvent.MntnsId,
+		}
+
+		if t.enricher != nil {
+			t.enricher.Enrich(&event.CommonData, event.MountNsID)
+		}
+
+		t.eventCallback(event)
+	}
+}
@@ -0,0 +1,195 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer_test
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	utilstest "github.com/lato333/inspektor-gadget/pkg/gadgets/internal/test"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/exit/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/exit/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+func TestExitTracerCreate(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+	if tracer == nil {
+		t.Fatal("Returned tracer was nil")
+	}
+}
+
+func TestExitTracerStopIdempotent(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+
+	// Check that a double stop doesn't cause issues
+	tracer.Stop()
+	tracer.Stop()
+}
+
+func TestExitTracer(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	type testDefinition struct {
+		getTracerConfig func(info *utilstest.RunnerInfo) *tracer.Config
+		generateEvent   func() (int, error)
+		validateEvent   func(t *testing.T, info *utilstest.RunnerInfo, shPid int, events []types.Event)
+	}
+
+	for name, test := range map[string]testDefinition{
+		"captures_no_events_with_no_matching_filter": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, 0),
+				}
+			},
+			generateEvent: generateEvent("exit 3"),
+			validateEvent: utilstest.ExpectNoEvent[types.Event, int],
+		},
+		"captures_exit_code": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: generateEvent("exit 3"),
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, shPid int) *types.Event {
+				return &types.Event{
+					Event: eventtypes.Event{
+						Type: eventtypes.NORMAL,
+					},
+					Pid:       uint32(shPid),
+					Ppid:      uint32(info.Pid),
+					Comm:      "sh",
+					ExitCode:  3,
+					Filename:  "/bin/sh",
+					MountNsID: info.MountNsID,
+				}
+			}),
+		},
+		"captures_terminating_signal": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: generateEvent("kill -TERM $$"),
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, shPid int) *types.Event {
+				return &types.Event{
+					Event: eventtypes.Event{
+						Type: eventtypes.NORMAL,
+					},
+					Pid:       uint32(shPid),
+					Ppid:      uint32(info.Pid),
+					Comm:      "sh",
+					Signal:    "SIGTERM",
+					Filename:  "/bin/sh",
+					MountNsID: info.MountNsID,
+				}
+			}),
+		},
+		"captures_no_successful_exits_with_failed_only": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+					FailedOnly: true,
+				}
+			},
+			generateEvent: generateEvent("exit 0"),
+			validateEvent: utilstest.ExpectNoEvent[types.Event, int],
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events := []types.Event{}
+			eventCallback := func(event types.Event) {
+				// The lifetimes can't be known in advance
+				if event.Lifetime == 0 || event.ExecLifetime == 0 || event.ExecLifetime > event.Lifetime {
+					t.Errorf("Event has bad lifetimes: %d, %d", event.Lifetime, event.ExecLifetime)
+				}
+				event.Lifetime = 0
+				event.ExecLifetime = 0
+
+				events = append(events, event)
+			}
+
+			runner := utilstest.NewRunnerWithTest(t, nil)
+
+			createTracer(t, test.getTracerConfig(runner.Info), eventCallback)
+
+			var shPid int
+
+			utilstest.RunWithRunner(t, runner, func() error {
+				var err error
+				shPid, err = test.generateEvent()
+				return err
+			})
+
+			// Give some time for the tracer to capture the events
+			time.Sleep(100 * time.Millisecond)
+
+			test.validateEvent(t, runner.Info, shPid, events)
+		})
+	}
+}
+
+func createTracer(
+	t *testing.T, config *tracer.Config, callback func(types.Event),
+) *tracer.Tracer {
+	t.Helper()
+
+	tracer, err := tracer.NewTracer(config, nil, callback)
+	if err != nil {
+		t.Fatalf("Error creating tracer: %s", err)
+	}
+	t.Cleanup(tracer.Stop)
+
+	return tracer
+}
+
+// generateEvent returns a function running a shell with the given command
+// and returning its pid. The shell failing is expected.
+func generateEvent(command string) func() (int, error) {
+	return func() (int, error) {
+		cmd := exec.Command("/bin/sh", "-c", command)
+		err := cmd.Run()
+
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return 0, fmt.Errorf("running command: %w", err)
+		}
+
+		return cmd.Process.Pid, nil
+	}
+}
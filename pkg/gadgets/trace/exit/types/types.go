@@ -0,0 +1,79 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"time"
+
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type Event struct {
+	eventtypes.Event
+
+	Pid  uint32 `json:"pid,omitempty" column:"pid,template:pid"`
+	Ppid uint32 `json:"ppid,omitempty" column:"ppid,template:pid"`
+	Comm string `json:"comm,omitempty" column:"comm,template:comm"`
+
+	// ExitCode is the code passed to exit(2). Signal is set instead when
+	// the process was terminated by a signal.
+	ExitCode   int    `json:"code,omitempty" column:"code,width:4,fixed"`
+	Signal     string `json:"signal,omitempty" column:"signal,width:9"`
+	CoreDumped bool   `json:"coreDumped,omitempty" column:"core,width:4,fixed"`
+
+	// Lifetime is the time since the process was forked, in nanoseconds.
+	Lifetime uint64 `json:"lifetime,omitempty" column:"lifetime,width:12,align:right"`
+
+	// Filename and ExecLifetime are only set when the process called exec
+	// after the tracer started: they are the file executed and the time
+	// since then, in nanoseconds.
+	Filename     string `json:"filename,omitempty" column:"filename,width:32"`
+	ExecLifetime uint64 `json:"execLifetime,omitempty" column:"execlifetime,width:12,align:right,hide"`
+
+	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+}
+
+func formatDuration(d uint64) string {
+	if d == 0 {
+		return ""
+	}
+	return time.Duration(d).Round(time.Microsecond).String()
+}
+
+func GetColumns() *columns.Columns[Event] {
+	cols := columns.MustCreateColumns[Event]()
+
+	cols.MustSetExtractor("core", func(event *Event) string {
+		if event.CoreDumped {
+			return "yes"
+		}
+		return ""
+	})
+	cols.MustSetExtractor("lifetime", func(event *Event) string {
+		return formatDuration(event.Lifetime)
+	})
+	cols.MustSetExtractor("execlifetime", func(event *Event) string {
+		return formatDuration(event.ExecLifetime)
+	})
+
+	return cols
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: GPL-2.0
+/* Copyright (c) 2022 The Inspektor Gadget authors */
+#include <vmlinux/vmlinux.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+
+#include "fsops.h"
+
+#define MAX_ENTRIES	10240
+
+/* Define here, because there are conflicts with include files */
+#define AT_REMOVEDIR	0x200
+
+const volatile bool filter_by_mnt_ns = false;
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event *unusedevent __attribute__((unused));
+
+static const struct event empty_event = {};
+
+// values keeps the events between the enter and the exit of the syscalls
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, __u32);
+	__type(value, struct event);
+} values SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+	__uint(key_size, sizeof(__u32));
+	__uint(value_size, sizeof(__u32));
+} events SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 1024);
+	__uint(key_size, sizeof(u64));
+	__uint(value_size, sizeof(u32));
+} mount_ns_filter SEC(".maps");
+
+// probe_entry returns the event of the current thread, or NULL if it's
+// filtered out. The event is too big for the stack: it's built in place in
+// the values map.
+static __always_inline struct event *probe_entry(enum fsops_op op)
+{
+	struct task_struct *task;
+	struct event *event;
+	__u64 pid_tgid;
+	u64 mntns_id;
+	__u32 tid;
+
+	task = (struct task_struct *) bpf_get_current_task();
+	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+		return NULL;
+
+	pid_tgid = bpf_get_current_pid_tgid();
+	tid = (__u32)pid_tgid;
+	if (bpf_map_update_elem(&values, &tid, &empty_event, BPF_ANY))
+		return NULL;
+
+	event = bpf_map_lookup_elem(&values, &tid);
+	if (!event)
+		return NULL;
+
+	event->pid = pid_tgid >> 32;
+	event->uid = (u32)bpf_get_current_uid_gid();
+	event->mntns_id = mntns_id;
+	event->op = op;
+	bpf_get_current_comm(event->comm, sizeof(event->comm));
+
+	return event;
+}
+
+static __always_inline int probe_path(enum fsops_op op, const char *path)
+{
+	struct event *event = probe_entry(op);
+
+	if (!event)
+		return 0;
+
+	bpf_probe_read_user_str(event->path, sizeof(event->path), path);
+	return 0;
+}
+
+static __always_inline int probe_rename(const char *oldpath, const char *newpath)
+{
+	struct event *event = probe_entry(FSOPS_RENAME);
+
+	if (!event)
+		return 0;
+
+	bpf_probe_read_user_str(event->path, sizeof(event->path), oldpath);
+	bpf_probe_read_user_str(event->target, sizeof(event->target), newpath);
+	return 0;
+}
+
+static __always_inline int probe_mode(enum fsops_op op, const char *path, __u32 mode)
+{
+	struct event *event = probe_entry(op);
+
+	if (!event)
+		return 0;
+
+	event->mode = mode;
+	bpf_probe_read_user_str(event->path, sizeof(event->path), path);
+	return 0;
+}
+
+static __always_inline int probe_chown(const char *path, __u32 uid, __u32 gid)
+{
+	struct event *event = probe_entry(FSOPS_CHOWN);
+
+	if (!event)
+		return 0;
+
+	event->new_uid = uid;
+	event->new_gid = gid;
+	bpf_probe_read_user_str(event->path, sizeof(event->path), path);
+	return 0;
+}
+
+static __always_inline int probe_symlink(const char *target, const char *linkpath)
+{
+	struct event *event = probe_entry(FSOPS_SYMLINK);
+
+	if (!event)
+		return 0;
+
+	bpf_probe_read_user_str(event->path, sizeof(event->path), linkpath);
+	bpf_probe_read_user_str(event->target, sizeof(event->target), target);
+	return 0;
+}
+
+SEC("tracepoint/syscalls/sys_enter_unlinkat")
+int ig_unlinkat_e(struct trace_event_raw_sys_enter *ctx)
+{
+	int flags = (int)ctx->args[2];
+
+	return probe_path(flags & AT_REMOVEDIR ? FSOPS_RMDIR : FSOPS_UNLINK,
+			  (const char *)ctx->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_unlink")
+int ig_unlink_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_path(FSOPS_UNLINK, (const char *)ctx->args[0]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_rmdir")
+int ig_rmdir_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_path(FSOPS_RMDIR, (const char *)ctx->args[0]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_renameat2")
+int ig_renameat2_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_rename((const char *)ctx->args[1], (const char *)ctx->args[3]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_renameat")
+int ig_renameat_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_rename((const char *)ctx->args[1], (const char *)ctx->args[3]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_rename")
+int ig_rename_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_rename((const char *)ctx->args[0], (const char *)ctx->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_fchmodat")
+int ig_fchmodat_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_mode(FSOPS_CHMOD, (const char *)ctx->args[1], (__u32)ctx->args[2]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_chmod")
+int ig_chmod_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_mode(FSOPS_CHMOD, (const char *)ctx->args[0], (__u32)ctx->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_fchownat")
+int ig_fchownat_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_chown((const char *)ctx->args[1], (__u32)ctx->args[2],
+			   (__u32)ctx->args[3]);
+}
+
+// chown and lchown only differ by following symbolic links or not
+SEC("tracepoint/syscalls/sys_enter_chown")
+int ig_chown_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_chown((const char *)ctx->args[0], (__u32)ctx->args[1],
+			   (__u32)ctx->args[2]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_mkdirat")
+int ig_mkdirat_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_mode(FSOPS_MKDIR, (const char *)ctx->args[1], (__u32)ctx->args[2]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_mkdir")
+int ig_mkdir_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_mode(FSOPS_MKDIR, (const char *)ctx->args[0], (__u32)ctx->args[1]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_symlinkat")
+int ig_symlinkat_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_symlink((const char *)ctx->args[0], (const char *)ctx->args[2]);
+}
+
+SEC("tracepoint/syscalls/sys_enter_symlink")
+int ig_symlink_e(struct trace_event_raw_sys_enter *ctx)
+{
+	return probe_symlink((const char *)ctx->args[0], (const char *)ctx->args[1]);
+}
+
+// ig_fsops_x is attached to the exit tracepoints of all the syscalls
+SEC("tracepoint/syscalls/sys_exit")
+int ig_fsops_x(struct trace_event_raw_sys_exit *ctx)
+{
+	__u32 tid = (__u32)bpf_get_current_pid_tgid();
+	struct event *event;
+
+	event = bpf_map_lookup_elem(&values, &tid);
+	if (!event)
+		return 0;
+
+	event->ret = ctx->ret;
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, event, sizeof(*event));
+
+	bpf_map_delete_elem(&values, &tid);
+	return 0;
+}
+
+char LICENSE[] SEC("license") = "GPL";
@@ -0,0 +1,34 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+#ifndef __FSOPS_H
+#define __FSOPS_H
+
+#define TASK_COMM_LEN	16
+#define PATH_LEN	256
+
+enum fsops_op {
+	FSOPS_UNLINK = 1,
+	FSOPS_RMDIR,
+	FSOPS_RENAME,
+	FSOPS_CHMOD,
+	FSOPS_CHOWN,
+	FSOPS_MKDIR,
+	FSOPS_SYMLINK,
+};
+
+struct event {
+	__u64 mntns_id;
+	__u32 pid;
+	__u32 uid;
+	__u32 op;
+	int ret;
+	// mode is set by chmod and mkdir, new_uid and new_gid by chown
+	__u32 mode;
+	__u32 new_uid;
+	__u32 new_gid;
+	__u8 comm[TASK_COMM_LEN];
+	__u8 path[PATH_LEN];
+	// target is the new path of renames and the content of symlinks
+	__u8 target[PATH_LEN];
+};
+
+#endif /* __FSOPS_H */
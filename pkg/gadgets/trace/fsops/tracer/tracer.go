@@ -0,0 +1,239 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/fsops/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event fsops ./bpf/fsops.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+// Keep in sync with enum fsops_op in bpf/fsops.h
+var opNames = map[uint32]string{
+	1: types.OpUnlink,
+	2: types.OpRmdir,
+	3: types.OpRename,
+	4: types.OpChmod,
+	5: types.OpChown,
+	6: types.OpMkdir,
+	7: types.OpSymlink,
+}
+
+type Config struct {
+	MountnsMap *ebpf.Map
+}
+
+type Tracer struct {
+	config        *Config
+	enricher      gadgets.DataEnricher
+	eventCallback func(types.Event)
+
+	objs   fsopsObjects
+	links  []link.Link
+	reader *perf.Reader
+}
+
+func NewTracer(config *Config, enricher gadgets.DataEnricher,
+	eventCallback func(types.Event),
+) (*Tracer, error) {
+	t := &Tracer{
+		config:        config,
+		enricher:      enricher,
+		eventCallback: eventCallback,
+	}
+
+	if err := t.start(); err != nil {
+		t.Stop()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Tracer) Stop() {
+	for i := range t.links {
+		t.links[i] = gadgets.CloseLink(t.links[i])
+	}
+	t.links = nil
+
+	if t.reader != nil {
+		t.reader.Close()
+	}
+
+	t.objs.Close()
+}
+
+func (t *Tracer) start() error {
+	spec, err := loadFsops()
+	if err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	mapReplacements := map[string]*ebpf.Map{}
+	filterByMntNs := false
+
+	if t.config.MountnsMap != nil {
+		filterByMntNs = true
+		mapReplacements["mount_ns_filter"] = t.config.MountnsMap
+	}
+
+	consts := map[string]interface{}{
+		"filter_by_mnt_ns": filterByMntNs,
+	}
+
+	if err := spec.RewriteConstants(consts); err != nil {
+		return fmt.Errorf("error RewriteConstants: %w", err)
+	}
+
+	opts := ebpf.CollectionOptions{
+		MapReplacements: mapReplacements,
+	}
+
+	if err := spec.LoadAndAssign(&t.objs, &opts); err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	// The syscalls without the "at" suffix, and renameat, are legacy ones
+	// that don't exist on all the architectures, like arm64.
+	syscalls := []struct {
+		name   string
+		prog   *ebpf.Program
+		legacy bool
+	}{
+		{"unlinkat", t.objs.IgUnlinkatE, false},
+		{"unlink", t.objs.IgUnlinkE, true},
+		{"rmdir", t.objs.IgRmdirE, true},
+		{"renameat2", t.objs.IgRenameat2E, false},
+		{"renameat", t.objs.IgRenameatE, true},
+		{"rename", t.objs.IgRenameE, true},
+		{"fchmodat", t.objs.IgFchmodatE, false},
+		{"chmod", t.objs.IgChmodE, true},
+		{"fchownat", t.objs.IgFchownatE, false},
+		{"chown", t.objs.IgChownE, true},
+		{"lchown", t.objs.IgChownE, true},
+		{"mkdirat", t.objs.IgMkdiratE, false},
+		{"mkdir", t.objs.IgMkdirE, true},
+		{"symlinkat", t.objs.IgSymlinkatE, false},
+		{"symlink", t.objs.IgSymlinkE, true},
+	}
+
+	for _, syscall := range syscalls {
+		enter, err := link.Tracepoint("syscalls", "sys_enter_"+syscall.name, syscall.prog, nil)
+		if err != nil {
+			if syscall.legacy && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, enter)
+
+		exit, err := link.Tracepoint("syscalls", "sys_exit_"+syscall.name, t.objs.IgFsopsX, nil)
+		if err != nil {
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, exit)
+	}
+
+	t.reader, err = perf.NewReader(t.objs.fsopsMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("error creating perf ring buffer: %w", err)
+	}
+
+	go t.run()
+
+	return nil
+}
+
+func formatMode(mode uint32) string {
+	return fmt.Sprintf("%04o", mode&07777)
+}
+
+// formatOwner returns "uid:gid", with "-" for the IDs left unchanged.
+func formatOwner(uid, gid uint32) string {
+	id := func(v uint32) string {
+		if v == math.MaxUint32 {
+			return "-"
+		}
+		return strconv.FormatUint(uint64(v), 10)
+	}
+	return id(uid) + ":" + id(gid)
+}
+
+func (t *Tracer) run() {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				// nothing to do, we're done
+				return
+			}
+
+			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.eventCallback(types.Base(eventtypes.Err(msg)))
+			return
+		}
+
+		if record.LostSamples > 0 {
+			msg := fmt.Sprintf("lost %d samples", record.LostSamples)
+			t.eventCallback(types.Base(eventtypes.Warn(msg)))
+			continue
+		}
+
+		bpfEvent := (*fsopsEvent)(unsafe.Pointer(&record.RawSample[0]))
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:       bpfEvent.Pid,
+			UID:       bpfEvent.Uid,
+			Comm:      gadgets.FromCString(bpfEvent.Comm[:]),
+			Op:        opNames[bpfEvent.Op],
+			Ret:       int(bpfEvent.Ret),
+			Path:      gadgets.FromCString(bpfEvent.Path[:]),
+			Target:    gadgets.FromCString(bpfEvent.Target[:]),
+			MountNsID: bpfEvent.MntnsId,
+		}
+
+		switch event.Op {
+		case types.OpChmod, types.OpMkdir:
+			event.Mode = formatMode(bpfEvent.Mode)
+		case types.OpChown:
+			event.Owner = formatOwner(bpfEvent.NewUid, bpfEvent.NewGid)
+		}
+
+		if t.enricher != nil {
+			t.enricher.Enrich(&event.CommonData, event.MountNsID)
+		}
+
+		t.eventCallback(event)
+	}
+}
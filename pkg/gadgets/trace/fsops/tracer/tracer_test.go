@@ -0,0 +1,257 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	utilstest "github.com/lato333/inspektor-gadget/pkg/gadgets/internal/test"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/fsops/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/fsops/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+func TestFsopsTracerCreate(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+	if tracer == nil {
+		t.Fatal("Returned tracer was nil")
+	}
+}
+
+func TestFsopsTracerStopIdempotent(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+
+	// Check that a double stop doesn't cause issues
+	tracer.Stop()
+	tracer.Stop()
+}
+
+func TestFsopsTracer(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	type testDefinition struct {
+		getTracerConfig func(info *utilstest.RunnerInfo) *tracer.Config
+		// prepare creates the files needed by generateEvent in dir
+		prepare       func(dir string) error
+		generateEvent func(dir string) error
+		validateEvent func(t *testing.T, info *utilstest.RunnerInfo, dir string, events []types.Event)
+	}
+
+	baseEvent := func(info *utilstest.RunnerInfo) types.Event {
+		return types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:       uint32(info.Pid),
+			UID:       uint32(info.UID),
+			Comm:      info.Comm,
+			MountNsID: info.MountNsID,
+		}
+	}
+
+	createFile := func(dir string) error {
+		return os.WriteFile(filepath.Join(dir, "file"), nil, 0o644)
+	}
+
+	for name, test := range map[string]testDefinition{
+		"captures_no_events_with_no_matching_filter": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, 0),
+				}
+			},
+			generateEvent: func(dir string) error {
+				return os.Mkdir(filepath.Join(dir, "foo"), 0o750)
+			},
+			validateEvent: utilstest.ExpectNoEvent[types.Event, string],
+		},
+		"captures_mkdir": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func(dir string) error {
+				return os.Mkdir(filepath.Join(dir, "foo"), 0o750)
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, dir string) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpMkdir
+				event.Path = filepath.Join(dir, "foo")
+				event.Mode = "0750"
+				return &event
+			}),
+		},
+		"captures_failed_unlink": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func(dir string) error {
+				// os.Remove() tries to remove a directory when
+				// unlinking fails, use the syscall directly.
+				err := unix.Unlinkat(unix.AT_FDCWD, filepath.Join(dir, "nonexistent"), 0)
+				if !errors.Is(err, unix.ENOENT) {
+					return fmt.Errorf("unlinking nonexistent file: %w", err)
+				}
+				return nil
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, dir string) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpUnlink
+				event.Path = filepath.Join(dir, "nonexistent")
+				event.Ret = -2 // ENOENT
+				return &event
+			}),
+		},
+		"captures_rename": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			prepare: createFile,
+			generateEvent: func(dir string) error {
+				return os.Rename(filepath.Join(dir, "file"), filepath.Join(dir, "renamed"))
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, dir string) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpRename
+				event.Path = filepath.Join(dir, "file")
+				event.Target = filepath.Join(dir, "renamed")
+				return &event
+			}),
+		},
+		"captures_chmod": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			prepare: createFile,
+			generateEvent: func(dir string) error {
+				return os.Chmod(filepath.Join(dir, "file"), os.ModeSetuid|0o755)
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, dir string) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpChmod
+				event.Path = filepath.Join(dir, "file")
+				event.Mode = "4755"
+				return &event
+			}),
+		},
+		"captures_chown": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			prepare: createFile,
+			generateEvent: func(dir string) error {
+				return os.Chown(filepath.Join(dir, "file"), -1, 1435)
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, dir string) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpChown
+				event.Path = filepath.Join(dir, "file")
+				event.Owner = "-:1435"
+				return &event
+			}),
+		},
+		"captures_symlink": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func(dir string) error {
+				return os.Symlink("/dev/null", filepath.Join(dir, "link"))
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, dir string) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpSymlink
+				event.Path = filepath.Join(dir, "link")
+				event.Target = "/dev/null"
+				return &event
+			}),
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if test.prepare != nil {
+				if err := test.prepare(dir); err != nil {
+					t.Fatalf("Error preparing test: %s", err)
+				}
+			}
+
+			events := []types.Event{}
+			eventCallback := func(event types.Event) {
+				events = append(events, event)
+			}
+
+			runner := utilstest.NewRunnerWithTest(t, nil)
+
+			createTracer(t, test.getTracerConfig(runner.Info), eventCallback)
+
+			utilstest.RunWithRunner(t, runner, func() error {
+				return test.generateEvent(dir)
+			})
+
+			// Give some time for the tracer to capture the events
+			time.Sleep(100 * time.Millisecond)
+
+			test.validateEvent(t, runner.Info, dir, events)
+		})
+	}
+}
+
+func createTracer(
+	t *testing.T, config *tracer.Config, callback func(types.Event),
+) *tracer.Tracer {
+	t.Helper()
+
+	tracer, err := tracer.NewTracer(config, nil, callback)
+	if err != nil {
+		t.Fatalf("Error creating tracer: %s", err)
+	}
+	t.Cleanup(tracer.Stop)
+
+	return tracer
+}
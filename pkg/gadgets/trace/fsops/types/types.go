@@ -0,0 +1,63 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+const (
+	OpUnlink  = "UNLINK"
+	OpRmdir   = "RMDIR"
+	OpRename  = "RENAME"
+	OpChmod   = "CHMOD"
+	OpChown   = "CHOWN"
+	OpMkdir   = "MKDIR"
+	OpSymlink = "SYMLINK"
+)
+
+type Event struct {
+	eventtypes.Event
+
+	Pid  uint32 `json:"pid,omitempty" column:"pid,template:pid"`
+	UID  uint32 `json:"uid,omitempty" column:"uid,minWidth:10,hide"`
+	Comm string `json:"comm,omitempty" column:"comm,template:comm"`
+	Op   string `json:"op,omitempty" column:"op,width:7,fixed"`
+	Ret  int    `json:"ret,omitempty" column:"ret,width:4,fixed"`
+
+	// Path is the file operated on, or the symbolic link created. Target
+	// is the new path of a renamed file, or the content of a symbolic
+	// link. Relative paths are reported as given to the syscall.
+	Path   string `json:"path,omitempty" column:"path,width:32"`
+	Target string `json:"target,omitempty" column:"target,width:32"`
+
+	// Mode is the new mode of chmod and mkdir, in octal. Owner is the new
+	// owner of chown, as "uid:gid", with "-" for the IDs left unchanged.
+	Mode  string `json:"mode,omitempty" column:"mode,width:5,fixed"`
+	Owner string `json:"owner,omitempty" column:"owner,width:11"`
+
+	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+}
+
+func GetColumns() *columns.Columns[Event] {
+	return columns.MustCreateColumns[Event]()
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -42,6 +42,10 @@ struct {
 	__uint(value_size, sizeof(u32));
 } mount_ns_filter SEC(".maps");
 
+#define container_of(ptr, type, member) \
+	((type *)((void *)(ptr) - \
+		  __builtin_preserve_field_info(((type *)0)->member, BPF_FIELD_BYTE_OFFSET)))
+
 static int probe_entry(struct file *fp, loff_t start, loff_t end)
 {
 	__u64 pid_tgid = bpf_get_current_pid_tgid();
@@ -80,6 +84,9 @@ static int probe_exit(void *ctx, enum fs_file_op op, ssize_t size)
 	__u32 tid = (__u32)pid_tgid;
 	__u64 end_ns, delta_ns;
 	const __u8 *file_name;
+	const char *fs_name;
+	struct vfsmount *vfsmnt;
+	struct mount *mnt;
 	struct data *datap;
 	struct event event = {};
 	struct dentry *dentry;
@@ -121,6 +128,12 @@ static int probe_exit(void *ctx, enum fs_file_op op, ssize_t size)
 	dentry = BPF_CORE_READ(fp, f_path.dentry);
 	file_name = BPF_CORE_READ(dentry, d_name.name);
 	bpf_probe_read_kernel_str(&event.file, sizeof(event.file), file_name);
+	vfsmnt = BPF_CORE_READ(fp, f_path.mnt);
+	mnt = container_of(vfsmnt, struct mount, mnt);
+	event.mnt_id = BPF_CORE_READ(mnt, mnt_id);
+	// Several filesystems can be traced at the same time
+	fs_name = BPF_CORE_READ(fp, f_inode, i_sb, s_type, name);
+	bpf_probe_read_kernel_str(&event.fs, sizeof(event.fs), fs_name);
 	bpf_get_current_comm(&event.task, sizeof(event.task));
 	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &event, sizeof(event));
 	return 0;
@@ -4,6 +4,7 @@
 
 #define FILE_NAME_LEN	32
 #define TASK_COMM_LEN	16
+#define FS_NAME_LEN	16
 
 enum fs_file_op {
 	READ,
@@ -21,8 +22,12 @@ struct event {
 	__u64 mntns_id;
 	__u32 pid;
 	enum fs_file_op op;
+	// mnt_id is the ID of the mount the file was opened through, as shown
+	// in /proc/<pid>/mountinfo.
+	__s32 mnt_id;
 	__u8 file[FILE_NAME_LEN];
 	__u8 task[TASK_COMM_LEN];
+	__u8 fs[FS_NAME_LEN];
 };
 
 #endif /* __FSSLOWER_H */
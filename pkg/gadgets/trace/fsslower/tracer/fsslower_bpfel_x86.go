@@ -21,8 +21,11 @@ type fsslowerEvent struct {
 	MntnsId uint64
 	Pid     uint32
 	Op      uint32
+	MntId   int32
 	File    [32]uint8
 	Task    [16]uint8
+	Fs      [16]uint8
+	_       [4]byte
 }
 
 // loadFsslower returns the embedded CollectionSpec for fsslower.
@@ -154,5 +157,6 @@ func _FsslowerClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed fsslower_bpfel_x86.o
 var _FsslowerBytes []byte
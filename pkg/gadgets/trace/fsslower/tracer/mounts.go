@@ -0,0 +1,134 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxCachedMounts bounds the size of the cache of mountPaths. It's emptied
+// once full, which also drops the IDs of the mounts that don't exist anymore
+// and could be reused.
+const maxCachedMounts = 4096
+
+type mountKey struct {
+	mntnsID uint64
+	mntID   int32
+}
+
+// mountPaths resolves the IDs of the mounts to their mount point as seen from
+// their mount namespace, e.g. the path of a PVC in a container, using the
+// mountinfo of the processes.
+type mountPaths struct {
+	cache map[mountKey]string
+}
+
+func newMountPaths() *mountPaths {
+	return &mountPaths{
+		cache: make(map[mountKey]string),
+	}
+}
+
+// path returns the mount point of the given mount, or an empty string if it
+// can't be found. It's the case of the internal mounts of some filesystems,
+// like the layers of overlayfs, which aren't part of any mount namespace.
+func (m *mountPaths) path(pid uint32, mntnsID uint64, mntID int32) string {
+	key := mountKey{mntnsID: mntnsID, mntID: mntID}
+	if path, ok := m.cache[key]; ok {
+		return path
+	}
+
+	file, err := os.Open(filepath.Join("/proc", fmt.Sprint(pid), "mountinfo"))
+	if err != nil {
+		// The process is already gone
+		return ""
+	}
+	defer file.Close()
+
+	mounts, err := parseMountInfo(file)
+	if err != nil {
+		return ""
+	}
+
+	if len(m.cache)+len(mounts) > maxCachedMounts {
+		m.cache = make(map[mountKey]string)
+	}
+
+	// Don't read the mountinfo again for mounts that aren't in it
+	m.cache[key] = ""
+	for id, path := range mounts {
+		m.cache[mountKey{mntnsID: mntnsID, mntID: id}] = path
+	}
+
+	return m.cache[key]
+}
+
+// parseMountInfo returns the mount points indexed by mount ID of a
+// /proc/<pid>/mountinfo file:
+// 36 35 98:0 /mnt1 /mnt/parent rw,noatime master:1 - ext3 /dev/root rw
+func parseMountInfo(r io.Reader) (map[int32]string, error) {
+	mounts := make(map[int32]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		id, err := strconv.ParseInt(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parsing mount ID %q: %w", fields[0], err)
+		}
+
+		mounts[int32(id)] = unescapeMountPath(fields[4])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return mounts, nil
+}
+
+// unescapeMountPath decodes the octal escapes, like \040 for a space, used by
+// the kernel for the paths in mountinfo.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+4 <= len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+
+	return b.String()
+}
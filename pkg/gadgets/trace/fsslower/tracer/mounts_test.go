@@ -0,0 +1,62 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMountInfo(t *testing.T) {
+	mountInfo := `1253 1150 0:132 / / rw,relatime master:434 - overlay overlay rw,lowerdir=/var/lib/containerd/l1,upperdir=/var/lib/containerd/u1
+1254 1253 0:134 / /proc rw,nosuid,nodev,noexec,relatime - proc proc rw
+1262 1253 253:1 /var/lib/kubelet/pods/a7f1/volumes/kubernetes.io~csi/pvc-3c1e/mount /data rw,relatime - xfs /dev/mapper/vg-pvc rw
+1263 1253 0:55 / /mnt/nfs\040share rw,relatime - nfs4 10.0.0.2:/export rw,vers=4.2
+`
+
+	mounts, err := parseMountInfo(strings.NewReader(mountInfo))
+	if err != nil {
+		t.Fatalf("parsing mountinfo: %s", err)
+	}
+
+	expected := map[int32]string{
+		1253: "/",
+		1254: "/proc",
+		1262: "/data",
+		1263: "/mnt/nfs share",
+	}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("expected %v, got %v", expected, mounts)
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/data":              "/data",
+		`/a\040b`:            "/a b",
+		`/a\011b\012c\134d`:  "/a\tb\nc\\d",
+		`/trailing\04`:       `/trailing\04`,
+		`/not\999octal`:      `/not\999octal`,
+		`/nfs\040share\040x`: "/nfs share x",
+	} {
+		if actual := unescapeMountPath(path); actual != expected {
+			t.Errorf("unescapeMountPath(%q): expected %q, got %q", path, expected, actual)
+		}
+	}
+}
@@ -18,9 +18,12 @@
 package tracer
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"unsafe"
 
 	"github.com/cilium/ebpf"
@@ -29,6 +32,7 @@ import (
 	"github.com/lato333/inspektor-gadget/pkg/gadgets"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/fsslower/types"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+	log "github.com/sirupsen/logrus"
 )
 
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -no-global-types -target $TARGET -cc clang -type event fsslower ./bpf/fsslower.bpf.c -- -I./bpf/ -I../../../../${TARGET}
@@ -36,6 +40,10 @@ import (
 type Config struct {
 	MountnsMap *ebpf.Map
 
+	// Filesystem is the filesystem to trace. If empty, all the supported
+	// filesystems registered in the kernel when the tracer starts, i.e.
+	// built-in or whose module is loaded, are traced, skipping the ones
+	// whose functions can't be traced.
 	Filesystem string
 	MinLatency uint
 }
@@ -45,16 +53,10 @@ type Tracer struct {
 	enricher      gadgets.DataEnricher
 	eventCallback func(types.Event)
 
-	objs           fsslowerObjects
-	readEnterLink  link.Link
-	readExitLink   link.Link
-	writeEnterLink link.Link
-	writeExitLink  link.Link
-	openEnterLink  link.Link
-	openExitLink   link.Link
-	syncEnterLink  link.Link
-	syncExitLink   link.Link
-	reader         *perf.Reader
+	objs       fsslowerObjects
+	links      []link.Link
+	reader     *perf.Reader
+	mountPaths *mountPaths
 }
 
 type fsConf struct {
@@ -83,6 +85,13 @@ var fsConfMap = map[string]fsConf{
 		open:  "nfs_file_open",
 		fsync: "nfs_file_fsync",
 	},
+	// NFSv4 only has its own open, the other functions are shared with nfs.
+	"nfs4": {
+		read:  "nfs_file_read",
+		write: "nfs_file_write",
+		open:  "nfs4_file_open",
+		fsync: "nfs_file_fsync",
+	},
 	"xfs": {
 		read:  "xfs_file_read_iter",
 		write: "xfs_file_write_iter",
@@ -98,6 +107,7 @@ func NewTracer(config *Config, enricher gadgets.DataEnricher,
 		config:        config,
 		enricher:      enricher,
 		eventCallback: eventCallback,
+		mountPaths:    newMountPaths(),
 	}
 
 	if err := t.start(); err != nil {
@@ -109,21 +119,10 @@ func NewTracer(config *Config, enricher gadgets.DataEnricher,
 }
 
 func (t *Tracer) Stop() {
-	// read
-	t.readEnterLink = gadgets.CloseLink(t.readEnterLink)
-	t.readExitLink = gadgets.CloseLink(t.readExitLink)
-
-	// write
-	t.writeEnterLink = gadgets.CloseLink(t.writeEnterLink)
-	t.writeExitLink = gadgets.CloseLink(t.writeExitLink)
-
-	// open
-	t.openEnterLink = gadgets.CloseLink(t.openEnterLink)
-	t.openExitLink = gadgets.CloseLink(t.openExitLink)
-
-	// sync
-	t.syncEnterLink = gadgets.CloseLink(t.syncEnterLink)
-	t.syncExitLink = gadgets.CloseLink(t.syncExitLink)
+	for _, l := range t.links {
+		gadgets.CloseLink(l)
+	}
+	t.links = nil
 
 	if t.reader != nil {
 		t.reader.Close()
@@ -165,50 +164,91 @@ func (t *Tracer) start() error {
 		return fmt.Errorf("failed to load ebpf program: %w", err)
 	}
 
-	// choose a configuration based on the filesystem type passed
-	fsConf, ok := fsConfMap[t.config.Filesystem]
-	if !ok {
-		return fmt.Errorf("%q is not a supported filesystem", t.config.Filesystem)
+	var filesystems []string
+	if t.config.Filesystem == "" {
+		filesystems, err = detectFilesystems()
+		if err != nil {
+			return fmt.Errorf("detecting filesystems: %w", err)
+		}
+		if len(filesystems) == 0 {
+			return errors.New("no supported filesystem found")
+		}
 	}
 
-	// read
-	t.readEnterLink, err = link.Kprobe(fsConf.read, t.objs.IgFsslReadE, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
-	}
-	t.readExitLink, err = link.Kretprobe(fsConf.read, t.objs.IgFsslReadX, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
-	}
+	// Filesystems can share functions, like nfs and nfs4, attach them once
+	attached := map[string]bool{}
+	attachFilesystem := func(filesystem string) error {
+		// choose a configuration based on the filesystem type passed
+		fsConf, ok := fsConfMap[filesystem]
+		if !ok {
+			return fmt.Errorf("%q is not a supported filesystem", filesystem)
+		}
 
-	// write
-	t.writeEnterLink, err = link.Kprobe(fsConf.write, t.objs.IgFsslWrE, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
-	}
-	t.writeExitLink, err = link.Kretprobe(fsConf.write, t.objs.IgFsslWrX, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
-	}
+		// The links of a filesystem are only kept once all its functions
+		// are attached.
+		var links []link.Link
+		var symbols []string
+		attach := func(symbol string, enter, exit *ebpf.Program) error {
+			if attached[symbol] {
+				return nil
+			}
 
-	// open
-	t.openEnterLink, err = link.Kprobe(fsConf.open, t.objs.IgFsslOpenE, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
-	}
-	t.openExitLink, err = link.Kretprobe(fsConf.open, t.objs.IgFsslOpenX, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
-	}
+			l, err := link.Kprobe(symbol, enter, nil)
+			if err != nil {
+				return fmt.Errorf("error attaching program to %s: %w", symbol, err)
+			}
+			links = append(links, l)
 
-	// sync
-	t.syncEnterLink, err = link.Kprobe(fsConf.fsync, t.objs.IgFsslSyncE, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
+			l, err = link.Kretprobe(symbol, exit, nil)
+			if err != nil {
+				return fmt.Errorf("error attaching program to %s: %w", symbol, err)
+			}
+			links = append(links, l)
+
+			symbols = append(symbols, symbol)
+			return nil
+		}
+
+		err := attach(fsConf.read, t.objs.IgFsslReadE, t.objs.IgFsslReadX)
+		if err == nil {
+			err = attach(fsConf.write, t.objs.IgFsslWrE, t.objs.IgFsslWrX)
+		}
+		if err == nil {
+			err = attach(fsConf.open, t.objs.IgFsslOpenE, t.objs.IgFsslOpenX)
+		}
+		if err == nil {
+			err = attach(fsConf.fsync, t.objs.IgFsslSyncE, t.objs.IgFsslSyncX)
+		}
+		if err != nil {
+			for _, l := range links {
+				gadgets.CloseLink(l)
+			}
+			return err
+		}
+
+		t.links = append(t.links, links...)
+		for _, symbol := range symbols {
+			attached[symbol] = true
+		}
+		return nil
 	}
-	t.syncExitLink, err = link.Kretprobe(fsConf.fsync, t.objs.IgFsslSyncX, nil)
-	if err != nil {
-		return fmt.Errorf("error attaching program: %w", err)
+
+	if t.config.Filesystem != "" {
+		if err := attachFilesystem(t.config.Filesystem); err != nil {
+			return err
+		}
+	} else {
+		// The functions of a filesystem registered in the kernel can still
+		// be missing, e.g. when they're inlined: trace the other ones.
+		for _, filesystem := range filesystems {
+			if err := attachFilesystem(filesystem); err != nil {
+				log.Warnf("fsslower: not tracing %s: %s", filesystem, err)
+			}
+		}
+		if len(t.links) == 0 {
+			return fmt.Errorf("none of the detected filesystems (%s) can be traced",
+				strings.Join(filesystems, ", "))
+		}
 	}
 
 	t.reader, err = perf.NewReader(t.objs.fsslowerMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
@@ -221,6 +261,40 @@ func (t *Tracer) start() error {
 	return nil
 }
 
+// detectFilesystems returns the supported filesystems registered in the
+// kernel, i.e. the ones built in the kernel or whose module is loaded.
+func detectFilesystems() ([]string, error) {
+	file, err := os.Open("/proc/filesystems")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	filesystems := []string{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// nodev	nfs4
+		//	ext4
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		filesystem := fields[len(fields)-1]
+		if _, ok := fsConfMap[filesystem]; ok {
+			filesystems = append(filesystems, filesystem)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(filesystems)
+
+	return filesystems, nil
+}
+
 var ops = []string{"R", "W", "O", "F"}
 
 func (t *Tracer) run() {
@@ -248,16 +322,19 @@ func (t *Tracer) run() {
 			Event: eventtypes.Event{
 				Type: eventtypes.NORMAL,
 			},
-			MountNsID: bpfEvent.MntnsId,
-			Comm:      gadgets.FromCString(bpfEvent.Task[:]),
-			Pid:       bpfEvent.Pid,
-			Op:        ops[int(bpfEvent.Op)],
-			Bytes:     bpfEvent.Size,
-			Offset:    bpfEvent.Offset,
-			Latency:   bpfEvent.DeltaUs,
-			File:      gadgets.FromCString(bpfEvent.File[:]),
+			MountNsID:  bpfEvent.MntnsId,
+			Comm:       gadgets.FromCString(bpfEvent.Task[:]),
+			Pid:        bpfEvent.Pid,
+			Op:         ops[int(bpfEvent.Op)],
+			Bytes:      bpfEvent.Size,
+			Offset:     bpfEvent.Offset,
+			Latency:    bpfEvent.DeltaUs,
+			File:       gadgets.FromCString(bpfEvent.File[:]),
+			Filesystem: gadgets.FromCString(bpfEvent.Fs[:]),
 		}
 
+		event.MountPath = t.mountPaths.path(event.Pid, event.MountNsID, bpfEvent.MntId)
+
 		if t.enricher != nil {
 			t.enricher.Enrich(&event.CommonData, event.MountNsID)
 		}
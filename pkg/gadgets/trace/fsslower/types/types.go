@@ -34,6 +34,11 @@ type Event struct {
 	Offset    int64  `json:"offset,omitempty" column:"offset,width:10,align:right"`
 	Latency   uint64 `json:"latency,omitempty" column:"lat,width:10,align:right"`
 	File      string `json:"file,omitempty" column:"file,width:24,maxWidth:32"`
+
+	Filesystem string `json:"filesystem,omitempty" column:"fs,width:5"`
+	// MountPath is the mount point, in the container, of the mount the file
+	// was accessed through, e.g. the path of a PVC.
+	MountPath string `json:"mountpath,omitempty" column:"mountpath,width:16,maxWidth:32"`
 }
 
 func GetColumns() *columns.Columns[Event] {
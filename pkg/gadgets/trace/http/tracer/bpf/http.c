@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: GPL-2.0
+/* Copyright (c) 2022 The Inspektor Gadget authors */
+
+#include <linux/bpf.h>
+#include <linux/if_ether.h>
+#include <linux/ip.h>
+#include <linux/in.h>
+#include <linux/tcp.h>
+#include <sys/socket.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_endian.h>
+
+#include "http.h"
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event_t *unusedevent __attribute__((unused));
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+} events SEC(".maps");
+
+// Ports to trace, in host byte order. Filled by userspace.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_PORTS);
+	__type(key, __u16);
+	__type(value, __u8);
+} ports SEC(".maps");
+
+// HTTP/1.x messages are parsed in userspace: the TCP segments from or to
+// the traced ports are sent with the event, truncated to MAX_HTTP_PACKET.
+SEC("socket1")
+int ig_trace_http(struct __sk_buff *skb)
+{
+	// Skip frames with non-IP Ethernet protocol.
+	struct ethhdr ethh;
+	if (bpf_skb_load_bytes(skb, 0, &ethh, sizeof ethh))
+		return 0;
+	if (bpf_ntohs(ethh.h_proto) != ETH_P_IP)
+		return 0;
+
+	int ip_off = ETH_HLEN;
+	// Read the IP header.
+	struct iphdr iph;
+	if (bpf_skb_load_bytes(skb, ip_off, &iph, sizeof iph))
+		return 0;
+
+	// Skip packets with IP protocol other than TCP.
+	if (iph.protocol != IPPROTO_TCP)
+		return 0;
+
+	// The IHL field is the size of the IP header in 32-bit words.
+	int tcp_off = ip_off + iph.ihl * 4;
+
+	// Read the TCP header.
+	struct tcphdr tcph;
+	if (bpf_skb_load_bytes(skb, tcp_off, &tcph, sizeof tcph))
+		return 0;
+
+	__u16 sport = bpf_ntohs(tcph.source);
+	__u16 dport = bpf_ntohs(tcph.dest);
+	if (!bpf_map_lookup_elem(&ports, &sport) &&
+	    !bpf_map_lookup_elem(&ports, &dport))
+		return 0;
+
+	// Skip segments without payload, like the ones of the handshake. The
+	// data offset field is the TCP header length in 32-bit words.
+	if (ip_off + bpf_ntohs(iph.tot_len) <= tcp_off + tcph.doff * 4)
+		return 0;
+
+	struct event_t event = {0,};
+	event.timestamp = bpf_ktime_get_ns();
+	event.af = AF_INET;
+	// inet_ntop() requires the network byte order
+	event.saddr_v4 = iph.saddr;
+	event.daddr_v4 = iph.daddr;
+	event.sport = sport;
+	event.dport = dport;
+	event.pkt_type = skb->pkt_type;
+
+	__u64 pkt_len = skb->len;
+	if (pkt_len > MAX_HTTP_PACKET)
+		pkt_len = MAX_HTTP_PACKET;
+
+	bpf_perf_event_output(skb, &events, BPF_F_CURRENT_CPU | (pkt_len << 32),
+			      &event, sizeof(event));
+
+	return 0;
+}
+
+char _license[] SEC("license") = "GPL";
@@ -0,0 +1,27 @@
+#ifndef GADGET_HTTP_H
+#define GADGET_HTTP_H
+
+// Max size of the packet sent to userspace, headers included. Request and
+// status lines, and the headers following them, are truncated to it.
+#define MAX_HTTP_PACKET 1024
+
+// Max number of ports traced
+#define MAX_PORTS 16
+
+struct event_t {
+	union {
+		__u8 saddr_v6[16];
+		__u32 saddr_v4;
+	};
+	union {
+		__u8 daddr_v6[16];
+		__u32 daddr_v4;
+	};
+	__u64 timestamp;
+	__u32 af; // AF_INET or AF_INET6
+	__u16 sport;
+	__u16 dport;
+	unsigned char pkt_type;
+};
+
+#endif
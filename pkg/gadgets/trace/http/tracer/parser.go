@@ -0,0 +1,170 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/internal/networktracer"
+)
+
+// Request methods defined in RFC 7231 and RFC 5789
+var methods = map[string]struct{}{
+	"GET":     {},
+	"HEAD":    {},
+	"POST":    {},
+	"PUT":     {},
+	"DELETE":  {},
+	"CONNECT": {},
+	"OPTIONS": {},
+	"TRACE":   {},
+	"PATCH":   {},
+}
+
+type message struct {
+	request bool
+
+	// Request fields
+	method string
+	path   string
+	host   string
+
+	// Response fields
+	status uint16
+}
+
+// parseMessages returns the requests or responses starting a segment. Several
+// of them are returned with pipelining, as long as the length of their bodies
+// is known. Segments not starting with a request or status line, like the ones
+// carrying the rest of a body, are ignored.
+func parseMessages(seg networktracer.TCPSegment) []message {
+	var msgs []message
+
+	data := seg.Payload
+	for len(data) > 0 {
+		msg, n, ok := parseMessage(data, seg.Truncated)
+		if !ok {
+			break
+		}
+		msgs = append(msgs, msg)
+		if n == 0 || n >= len(data) {
+			break
+		}
+		data = data[n:]
+	}
+
+	return msgs
+}
+
+// parseMessage parses the start line and the headers of an HTTP/1.x message.
+// It returns the length of the message, or zero if it's unknown or goes
+// beyond data.
+// https://datatracker.ietf.org/doc/html/rfc7230#section-3
+func parseMessage(data []byte, truncated bool) (message, int, bool) {
+	var msg message
+
+	end := bytes.Index(data, []byte("\r\n"))
+	if end == -1 {
+		if !truncated {
+			return message{}, 0, false
+		}
+		// Keep what was captured of long request lines
+		end = len(data)
+	}
+	line := data[:end]
+
+	if bytes.HasPrefix(line, []byte("HTTP/1.")) {
+		// HTTP-version SP status-code SP reason-phrase
+		fields := bytes.SplitN(line, []byte(" "), 3)
+		if len(fields) < 2 || len(fields[1]) != 3 {
+			return message{}, 0, false
+		}
+		status, err := strconv.ParseUint(string(fields[1]), 10, 16)
+		if err != nil {
+			return message{}, 0, false
+		}
+		msg.status = uint16(status)
+	} else {
+		// method SP request-target SP HTTP-version
+		fields := bytes.SplitN(line, []byte(" "), 3)
+		if len(fields) < 2 {
+			return message{}, 0, false
+		}
+		if _, ok := methods[string(fields[0])]; !ok {
+			return message{}, 0, false
+		}
+		if len(fields) == 3 && !bytes.HasPrefix(fields[2], []byte("HTTP/1.")) {
+			return message{}, 0, false
+		}
+		msg.request = true
+		msg.method = string(fields[0])
+		msg.path = string(fields[1])
+	}
+
+	// Requests without Content-Length nor Transfer-Encoding don't have a
+	// body. Responses without them end with the connection.
+	bodyLen := -1
+	if msg.request {
+		bodyLen = 0
+	}
+
+	chunked := false
+	off := end + 2
+	for {
+		if off >= len(data) {
+			// The headers are truncated or continue in the next
+			// segment
+			return msg, 0, true
+		}
+		end := bytes.Index(data[off:], []byte("\r\n"))
+		if end == -1 {
+			return msg, 0, true
+		}
+		header := data[off : off+end]
+		off += end + 2
+		if len(header) == 0 {
+			break
+		}
+
+		name, value, ok := bytes.Cut(header, []byte(":"))
+		if !ok {
+			continue
+		}
+		value = bytes.TrimSpace(value)
+
+		switch {
+		case bytes.EqualFold(name, []byte("Host")):
+			msg.host = string(value)
+		case bytes.EqualFold(name, []byte("Content-Length")):
+			if l, err := strconv.Atoi(string(value)); err == nil && l >= 0 {
+				bodyLen = l
+			}
+		case bytes.EqualFold(name, []byte("Transfer-Encoding")):
+			chunked = true
+		}
+	}
+
+	// Some responses never have a body, whatever their headers say
+	if msg.status/100 == 1 || msg.status == 204 || msg.status == 304 {
+		bodyLen, chunked = 0, false
+	}
+
+	// The length of chunked bodies is unknown
+	if chunked || bodyLen == -1 || off+bodyLen > len(data) {
+		return msg, 0, true
+	}
+	return msg, off + bodyLen, true
+}
@@ -0,0 +1,253 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/internal/networktracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/http/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate bash -c "source ./clangosflags.sh; go run github.com/cilium/ebpf/cmd/bpf2go -target bpfel -cc clang -type event_t http ./bpf/http.c -- $CLANG_OS_FLAGS -I./bpf/"
+
+const (
+	BPFProgName     = "ig_trace_http"
+	BPFPerfMapName  = "events"
+	BPFPortsMapName = "ports"
+	BPFSocketAttach = 50
+)
+
+const (
+	// Requests without response are forgotten after requestTimeout, and at
+	// most maxPendingRequests are kept per connection with pipelining.
+	requestTimeout     = 30 * time.Second
+	maxPendingRequests = 16
+	maxConnections     = 4096
+)
+
+// DefaultPorts are the ports traced when none is configured.
+var DefaultPorts = []uint16{80, 8080}
+
+type Config struct {
+	// Ports are the server ports of the traced connections
+	Ports []uint16
+}
+
+// connKey identifies an HTTP connection. The same addresses can be used in
+// different network namespaces.
+type connKey struct {
+	netns      uint64
+	clientAddr [16]byte
+	clientPort uint16
+	serverAddr [16]byte
+	serverPort uint16
+}
+
+type request struct {
+	method    string
+	path      string
+	host      string
+	timestamp uint64
+}
+
+type conn struct {
+	// requests waiting for their response, in order
+	requests []request
+	lastSeen uint64
+}
+
+type Tracer struct {
+	*networktracer.Tracer[types.Event]
+
+	mu sync.Mutex
+	// conns keeps the requests waiting for their response
+	conns map[connKey]*conn
+}
+
+func NewTracer(config *Config) (*Tracer, error) {
+	spec, err := loadHttp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load asset: %w", err)
+	}
+
+	ports := config.Ports
+	if len(ports) == 0 {
+		ports = DefaultPorts
+	}
+
+	portsSpec, ok := spec.Maps[BPFPortsMapName]
+	if !ok {
+		return nil, fmt.Errorf("map %q not found", BPFPortsMapName)
+	}
+	if len(ports) > int(portsSpec.MaxEntries) {
+		return nil, fmt.Errorf("too many ports: at most %d can be traced", portsSpec.MaxEntries)
+	}
+	// The map is filled in each network namespace the tracer is attached to
+	for _, port := range ports {
+		portsSpec.Contents = append(portsSpec.Contents, ebpf.MapKV{Key: port, Value: uint8(1)})
+	}
+
+	t := &Tracer{
+		conns: make(map[connKey]*conn),
+	}
+	t.Tracer = networktracer.NewTracer(
+		spec,
+		BPFProgName,
+		BPFPerfMapName,
+		BPFSocketAttach,
+		types.Base,
+		t.parseHTTPEvent,
+	)
+
+	return t, nil
+}
+
+// addRequest queues a request until its response comes. Responses come in the
+// same order as requests, even with pipelining.
+func (t *Tracer) addRequest(key connKey, req request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.conns[key]
+	if !ok {
+		if len(t.conns) >= maxConnections {
+			for k, c := range t.conns {
+				if req.timestamp-c.lastSeen > uint64(requestTimeout) {
+					delete(t.conns, k)
+				}
+			}
+			if len(t.conns) >= maxConnections {
+				return
+			}
+		}
+		c = &conn{}
+		t.conns[key] = c
+	}
+	c.lastSeen = req.timestamp
+
+	if len(c.requests) >= maxPendingRequests {
+		// The responses were likely missed, start again
+		c.requests = nil
+	}
+	c.requests = append(c.requests, req)
+}
+
+// popRequest returns the oldest request waiting for a response.
+func (t *Tracer) popRequest(key connKey) (request, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.conns[key]
+	if !ok {
+		return request{}, false
+	}
+
+	req := c.requests[0]
+	c.requests = c.requests[1:]
+	if len(c.requests) == 0 {
+		delete(t.conns, key)
+	}
+	return req, true
+}
+
+func (t *Tracer) parseHTTPEvent(netns uint64, rawSample []byte) ([]*types.Event, error) {
+	bpfEvent := (*httpEventT)(unsafe.Pointer(&rawSample[0]))
+	if len(rawSample) < int(unsafe.Sizeof(*bpfEvent)) {
+		return nil, errors.New("invalid sample size")
+	}
+
+	seg, ok := networktracer.ParseTCPSegment(rawSample[unsafe.Sizeof(*bpfEvent):])
+	if !ok {
+		return nil, nil
+	}
+
+	ipVersion := 0
+	if bpfEvent.Af == syscall.AF_INET {
+		ipVersion = 4
+	} else if bpfEvent.Af == syscall.AF_INET6 {
+		ipVersion = 6
+	}
+
+	var events []*types.Event
+	for _, msg := range parseMessages(seg) {
+		// Requests go from the client to the server
+		if msg.request {
+			key := connKey{
+				netns:      netns,
+				clientAddr: bpfEvent.SaddrV6,
+				clientPort: bpfEvent.Sport,
+				serverAddr: bpfEvent.DaddrV6,
+				serverPort: bpfEvent.Dport,
+			}
+			t.addRequest(key, request{
+				method:    msg.method,
+				path:      msg.path,
+				host:      msg.host,
+				timestamp: bpfEvent.Timestamp,
+			})
+			continue
+		}
+
+		// Informational responses like "100 Continue" come before the
+		// final one
+		if msg.status/100 == 1 {
+			continue
+		}
+
+		key := connKey{
+			netns:      netns,
+			clientAddr: bpfEvent.DaddrV6,
+			clientPort: bpfEvent.Dport,
+			serverAddr: bpfEvent.SaddrV6,
+			serverPort: bpfEvent.Sport,
+		}
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Saddr:  gadgets.IPStringFromBytes(bpfEvent.DaddrV6, ipVersion),
+			Sport:  bpfEvent.Dport,
+			Daddr:  gadgets.IPStringFromBytes(bpfEvent.SaddrV6, ipVersion),
+			Dport:  bpfEvent.Sport,
+			Status: msg.status,
+		}
+
+		// Responses to requests sent before the tracer started are
+		// reported without them.
+		if req, ok := t.popRequest(key); ok {
+			event.Method = req.method
+			event.Path = req.path
+			event.Host = req.host
+			if bpfEvent.Timestamp > req.timestamp {
+				event.Latency = bpfEvent.Timestamp - req.timestamp
+			}
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
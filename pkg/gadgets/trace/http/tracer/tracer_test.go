@@ -0,0 +1,136 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/internal/networktracer"
+)
+
+func TestParseMessages(t *testing.T) {
+	table := []struct {
+		description string
+		input       networktracer.TCPSegment
+		output      []message
+	}{
+		{
+			description: "request",
+			input: networktracer.TCPSegment{Payload: []byte("GET /index.html HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"Accept: */*\r\n\r\n")},
+			output: []message{
+				{request: true, method: "GET", path: "/index.html", host: "example.com"},
+			},
+		},
+		{
+			description: "response",
+			input: networktracer.TCPSegment{Payload: []byte("HTTP/1.1 404 Not Found\r\n" +
+				"Content-Length: 9\r\n\r\n" +
+				"not found")},
+			output: []message{{status: 404}},
+		},
+		{
+			description: "pipelined requests",
+			input: networktracer.TCPSegment{Payload: []byte("POST /a HTTP/1.1\r\nHost: example.com\r\ncontent-length: 4\r\n\r\n" +
+				"body" +
+				"GET /b HTTP/1.1\r\nHost: example.com\r\n\r\n" +
+				"HEAD /c HTTP/1.0\r\n\r\n")},
+			output: []message{
+				{request: true, method: "POST", path: "/a", host: "example.com"},
+				{request: true, method: "GET", path: "/b", host: "example.com"},
+				{request: true, method: "HEAD", path: "/c"},
+			},
+		},
+		{
+			description: "pipelined responses",
+			input: networktracer.TCPSegment{Payload: []byte("HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok" +
+				"HTTP/1.1 304 Not Modified\r\nContent-Length: 100\r\n\r\n" +
+				"HTTP/1.1 500 Internal Server Error\r\nTransfer-Encoding: chunked\r\n\r\n" +
+				"HTTP/1.1 200 OK\r\n\r\n")},
+			output: []message{{status: 200}, {status: 304}, {status: 500}},
+		},
+		{
+			description: "body continuation",
+			input:       networktracer.TCPSegment{Payload: []byte("more body\r\nHTTP/1.1 200 OK\r\n\r\n")},
+			output:      nil,
+		},
+		{
+			description: "truncated request line",
+			input:       networktracer.TCPSegment{Payload: []byte("GET /very/long/pa"), Truncated: true},
+			output: []message{
+				{request: true, method: "GET", path: "/very/long/pa"},
+			},
+		},
+		{
+			description: "incomplete request line",
+			input:       networktracer.TCPSegment{Payload: []byte("GET /very/long/pa")},
+			output:      nil,
+		},
+		{
+			description: "unknown method",
+			input:       networktracer.TCPSegment{Payload: []byte("FOO / HTTP/1.1\r\n\r\n")},
+			output:      nil,
+		},
+		{
+			description: "HTTP/2 connection preface",
+			input:       networktracer.TCPSegment{Payload: []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")},
+			output:      nil,
+		},
+	}
+
+	for _, entry := range table {
+		output := parseMessages(entry.input)
+		if !reflect.DeepEqual(output, entry.output) {
+			t.Fatalf("Failed test %q: got %+v, expected %+v", entry.description, output, entry.output)
+		}
+	}
+}
+
+func TestPendingRequests(t *testing.T) {
+	tracer := &Tracer{
+		conns: make(map[connKey]*conn),
+	}
+
+	key := connKey{netns: 4026531840, clientPort: 40000, serverPort: 80}
+	other := key
+	other.clientPort = 40001
+	// The same addresses used in another network namespace
+	otherNetns := key
+	otherNetns.netns = 4026532000
+
+	tracer.addRequest(key, request{path: "/a", timestamp: 1000})
+	tracer.addRequest(key, request{path: "/b", timestamp: 2000})
+
+	if _, ok := tracer.popRequest(other); ok {
+		t.Fatalf("Got a request for another connection")
+	}
+	if _, ok := tracer.popRequest(otherNetns); ok {
+		t.Fatalf("Got a request for a connection in another network namespace")
+	}
+	for _, path := range []string{"/a", "/b"} {
+		req, ok := tracer.popRequest(key)
+		if !ok || req.path != path {
+			t.Fatalf("Got request %+v, expected %s", req, path)
+		}
+	}
+	if _, ok := tracer.popRequest(key); ok {
+		t.Fatalf("Got a request for a duplicated response")
+	}
+	if n := len(tracer.conns); n != 0 {
+		t.Fatalf("Got %d connections, expected 0", n)
+	}
+}
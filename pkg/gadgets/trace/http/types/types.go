@@ -0,0 +1,63 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"time"
+
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type Event struct {
+	eventtypes.Event
+
+	// Saddr and Sport are the client, Daddr and Dport the server
+	Saddr string `json:"saddr,omitempty" column:"saddr,template:ipaddr,hide"`
+	Sport uint16 `json:"sport,omitempty" column:"sport,template:ipport,hide"`
+	Daddr string `json:"daddr,omitempty" column:"daddr,template:ipaddr"`
+	Dport uint16 `json:"dport,omitempty" column:"dport,template:ipport"`
+
+	Method string `json:"method,omitempty" column:"method,width:7,fixed"`
+	Host   string `json:"host,omitempty" column:"host,width:24"`
+	Path   string `json:"path,omitempty" column:"path,width:32"`
+	Status uint16 `json:"status,omitempty" column:"status,width:6,fixed"`
+
+	// Latency is the time between the request and the response in
+	// nanoseconds.
+	Latency uint64 `json:"latency,omitempty" column:"latency,width:10,align:right"`
+}
+
+func GetColumns() *columns.Columns[Event] {
+	cols := columns.MustCreateColumns[Event]()
+
+	col, _ := cols.GetColumn("container")
+	col.Visible = false
+
+	cols.MustSetExtractor("latency", func(event *Event) string {
+		if event.Latency == 0 {
+			return ""
+		}
+		return time.Duration(event.Latency).String()
+	})
+
+	return cols
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: GPL-2.0
+/* Copyright (c) 2022 The Inspektor Gadget authors */
+#include <vmlinux/vmlinux.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+
+#include "kernelload.h"
+
+#define MAX_ENTRIES	10240
+
+/* Define here, because there are conflicts with include files */
+#define BPF_OBJ_NAME_LEN	16U
+
+const volatile bool filter_by_mnt_ns = false;
+// host_mntns_id is the mount namespace of the host when its processes are
+// traced together with the ones of the mount_ns_filter map, 0 otherwise.
+const volatile u64 host_mntns_id = 0;
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event *unusedevent __attribute__((unused));
+
+static const struct event empty_event = {};
+
+// values keeps the events between the enter and the exit of the syscalls
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, __u32);
+	__type(value, struct event);
+} values SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+	__uint(key_size, sizeof(__u32));
+	__uint(value_size, sizeof(__u32));
+} events SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 1024);
+	__uint(key_size, sizeof(u64));
+	__uint(value_size, sizeof(u32));
+} mount_ns_filter SEC(".maps");
+
+// probe_entry returns the event of the current thread, or NULL if it's
+// filtered out. It's built in place in the values map.
+static __always_inline struct event *probe_entry(enum op op)
+{
+	struct task_struct *task;
+	struct event *event;
+	__u64 pid_tgid;
+	u64 mntns_id;
+	__u32 tid;
+
+	task = (struct task_struct *) bpf_get_current_task();
+	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+	if (filter_by_mnt_ns && mntns_id != host_mntns_id &&
+	    !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+		return NULL;
+
+	pid_tgid = bpf_get_current_pid_tgid();
+	tid = (__u32)pid_tgid;
+	if (bpf_map_update_elem(&values, &tid, &empty_event, BPF_ANY))
+		return NULL;
+
+	event = bpf_map_lookup_elem(&values, &tid);
+	if (!event)
+		return NULL;
+
+	event->pid = pid_tgid >> 32;
+	event->uid = (u32)bpf_get_current_uid_gid();
+	event->mntns_id = mntns_id;
+	event->op = op;
+	bpf_get_current_comm(event->comm, sizeof(event->comm));
+
+	return event;
+}
+
+SEC("tracepoint/syscalls/sys_enter_init_module")
+int ig_init_module_e(struct trace_event_raw_sys_enter *ctx)
+{
+	probe_entry(OP_INIT_MODULE);
+	return 0;
+}
+
+SEC("tracepoint/syscalls/sys_enter_finit_module")
+int ig_finit_module_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct task_struct *task = (struct task_struct *) bpf_get_current_task();
+	int fd = (int)ctx->args[0];
+	struct fdtable *fdt;
+	struct event *event;
+	struct file *file;
+
+	event = probe_entry(OP_FINIT_MODULE);
+	if (!event)
+		return 0;
+
+	// Use the name of the file until the module name is known: the load
+	// can fail before the module is parsed.
+	fdt = BPF_CORE_READ(task, files, fdt);
+	if (fd < 0 || fd >= BPF_CORE_READ(fdt, max_fds))
+		return 0;
+
+	if (bpf_probe_read_kernel(&file, sizeof(file), BPF_CORE_READ(fdt, fd) + fd) || !file)
+		return 0;
+
+	bpf_probe_read_kernel_str(event->name, sizeof(event->name),
+				  BPF_CORE_READ(file, f_path.dentry, d_name.name));
+	return 0;
+}
+
+SEC("tracepoint/module/module_load")
+int ig_module_load(struct trace_event_raw_module_load *ctx)
+{
+	__u32 tid = (__u32)bpf_get_current_pid_tgid();
+	unsigned int name_off;
+	struct event *event;
+
+	event = bpf_map_lookup_elem(&values, &tid);
+	if (!event)
+		return 0;
+
+	// The name is a dynamic array of the tracepoint: the lower 16 bits of
+	// __data_loc_name are its offset in the context.
+	name_off = ctx->__data_loc_name & 0xFFFF;
+	bpf_probe_read_kernel_str(event->name, sizeof(event->name),
+				  (void *)ctx + name_off);
+	return 0;
+}
+
+SEC("tracepoint/syscalls/sys_enter_bpf")
+int ig_bpf_e(struct trace_event_raw_sys_enter *ctx)
+{
+	int cmd = (int)ctx->args[0];
+	void *uattr = (void *)ctx->args[1];
+	__u32 size = (__u32)ctx->args[2];
+	struct event *event;
+
+	switch (cmd) {
+	case BPF_PROG_LOAD:
+		event = probe_entry(OP_PROG_LOAD);
+		if (!event)
+			return 0;
+
+		bpf_probe_read_user(&event->type, sizeof(event->type),
+				    uattr + __builtin_offsetof(union bpf_attr, prog_type));
+		bpf_probe_read_user(&event->attach_type, sizeof(event->attach_type),
+				    uattr + __builtin_offsetof(union bpf_attr, expected_attach_type));
+		// Old loaders pass a shorter attribute without the name
+		if (size >= __builtin_offsetof(union bpf_attr, prog_name) + BPF_OBJ_NAME_LEN)
+			bpf_probe_read_user_str(event->name, BPF_OBJ_NAME_LEN,
+						uattr + __builtin_offsetof(union bpf_attr, prog_name));
+		break;
+	case BPF_MAP_CREATE:
+		event = probe_entry(OP_MAP_CREATE);
+		if (!event)
+			return 0;
+
+		bpf_probe_read_user(&event->type, sizeof(event->type),
+				    uattr + __builtin_offsetof(union bpf_attr, map_type));
+		if (size >= __builtin_offsetof(union bpf_attr, map_name) + BPF_OBJ_NAME_LEN)
+			bpf_probe_read_user_str(event->name, BPF_OBJ_NAME_LEN,
+						uattr + __builtin_offsetof(union bpf_attr, map_name));
+		break;
+	}
+
+	return 0;
+}
+
+// ig_kernelload_x is attached to the exit tracepoints of all the syscalls
+SEC("tracepoint/syscalls/sys_exit")
+int ig_kernelload_x(struct trace_event_raw_sys_exit *ctx)
+{
+	__u32 tid = (__u32)bpf_get_current_pid_tgid();
+	struct event *event;
+
+	event = bpf_map_lookup_elem(&values, &tid);
+	if (!event)
+		return 0;
+
+	// bpf() returns a file descriptor of the program or map, that isn't
+	// meaningful outside of the process: only keep the errors.
+	event->ret = ctx->ret < 0 ? ctx->ret : 0;
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, event, sizeof(*event));
+
+	bpf_map_delete_elem(&values, &tid);
+	return 0;
+}
+
+char LICENSE[] SEC("license") = "GPL";
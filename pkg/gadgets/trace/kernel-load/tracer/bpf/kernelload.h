@@ -0,0 +1,30 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+#ifndef __KERNELLOAD_H
+#define __KERNELLOAD_H
+
+#define TASK_COMM_LEN	16
+#define NAME_LEN	64
+
+enum op {
+	OP_INIT_MODULE = 1,
+	OP_FINIT_MODULE,
+	OP_PROG_LOAD,
+	OP_MAP_CREATE,
+};
+
+struct event {
+	__u64 mntns_id;
+	__u32 pid;
+	__u32 uid;
+	__u32 op;
+	// type is the program or map type, and attach_type the expected
+	// attach type of the program, as in enum bpf_prog_type,
+	// bpf_map_type and bpf_attach_type.
+	__u32 type;
+	__u32 attach_type;
+	int ret;
+	__u8 comm[TASK_COMM_LEN];
+	__u8 name[NAME_LEN];
+};
+
+#endif /* __KERNELLOAD_H */
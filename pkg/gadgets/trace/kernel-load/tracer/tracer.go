@@ -0,0 +1,233 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/kernel-load/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event kernelload ./bpf/kernelload.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+// Keep in sync with enum op in bpf/kernelload.h
+var opNames = map[uint32]string{
+	1: types.OpInitModule,
+	2: types.OpFinitModule,
+	3: types.OpProgLoad,
+	4: types.OpMapCreate,
+}
+
+type Config struct {
+	MountnsMap *ebpf.Map
+
+	// Host traces the processes running on the host, outside of any
+	// container, together with the ones of the containers of MountnsMap.
+	Host bool
+}
+
+type Tracer struct {
+	config        *Config
+	enricher      gadgets.DataEnricher
+	eventCallback func(types.Event)
+
+	objs   kernelloadObjects
+	links  []link.Link
+	reader *perf.Reader
+}
+
+func NewTracer(config *Config, enricher gadgets.DataEnricher,
+	eventCallback func(types.Event),
+) (*Tracer, error) {
+	t := &Tracer{
+		config:        config,
+		enricher:      enricher,
+		eventCallback: eventCallback,
+	}
+
+	if err := t.start(); err != nil {
+		t.Stop()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Tracer) Stop() {
+	for i := range t.links {
+		t.links[i] = gadgets.CloseLink(t.links[i])
+	}
+	t.links = nil
+
+	if t.reader != nil {
+		t.reader.Close()
+	}
+
+	t.objs.Close()
+}
+
+func (t *Tracer) start() error {
+	spec, err := loadKernelload()
+	if err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	mapReplacements := map[string]*ebpf.Map{}
+	filterByMntNs := false
+	hostMntNsID := uint64(0)
+
+	if t.config.MountnsMap != nil {
+		filterByMntNs = true
+		mapReplacements["mount_ns_filter"] = t.config.MountnsMap
+
+		if t.config.Host {
+			// The tracer runs in the host PID namespace: the mount
+			// namespace of the host is the one of its init process.
+			hostMntNsID, err = containerutils.GetMntNs(1)
+			if err != nil {
+				return fmt.Errorf("error getting host mount namespace: %w", err)
+			}
+		}
+	}
+
+	consts := map[string]interface{}{
+		"filter_by_mnt_ns": filterByMntNs,
+		"host_mntns_id":    hostMntNsID,
+	}
+
+	if err := spec.RewriteConstants(consts); err != nil {
+		return fmt.Errorf("error RewriteConstants: %w", err)
+	}
+
+	opts := ebpf.CollectionOptions{
+		MapReplacements: mapReplacements,
+	}
+
+	if err := spec.LoadAndAssign(&t.objs, &opts); err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	syscalls := []struct {
+		name string
+		prog *ebpf.Program
+	}{
+		{"init_module", t.objs.IgInitModuleE},
+		{"finit_module", t.objs.IgFinitModuleE},
+		{"bpf", t.objs.IgBpfE},
+	}
+
+	for _, syscall := range syscalls {
+		enter, err := link.Tracepoint("syscalls", "sys_enter_"+syscall.name, syscall.prog, nil)
+		if err != nil {
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, enter)
+
+		exit, err := link.Tracepoint("syscalls", "sys_exit_"+syscall.name, t.objs.IgKernelloadX, nil)
+		if err != nil {
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, exit)
+	}
+
+	// The module_load tracepoint gives the name of the modules loaded. It
+	// doesn't exist in kernels built without modules support, where
+	// init_module and finit_module always fail.
+	moduleLoad, err := link.Tracepoint("module", "module_load", t.objs.IgModuleLoad, nil)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error opening tracepoint: %w", err)
+	}
+	if err == nil {
+		t.links = append(t.links, moduleLoad)
+	}
+
+	t.reader, err = perf.NewReader(t.objs.kernelloadMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("error creating perf ring buffer: %w", err)
+	}
+
+	go t.run()
+
+	return nil
+}
+
+func (t *Tracer) run() {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				// nothing to do, we're done
+				return
+			}
+
+			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.eventCallback(types.Base(eventtypes.Err(msg)))
+			return
+		}
+
+		if record.LostSamples > 0 {
+			msg := fmt.Sprintf("lost %d samples", record.LostSamples)
+			t.eventCallback(types.Base(eventtypes.Warn(msg)))
+			continue
+		}
+
+		bpfEvent := (*kernelloadEvent)(unsafe.Pointer(&record.RawSample[0]))
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:       bpfEvent.Pid,
+			UID:       bpfEvent.Uid,
+			Comm:      gadgets.FromCString(bpfEvent.Comm[:]),
+			Op:        opNames[bpfEvent.Op],
+			Ret:       int(bpfEvent.Ret),
+			Name:      gadgets.FromCString(bpfEvent.Name[:]),
+			MountNsID: bpfEvent.MntnsId,
+		}
+
+		switch event.Op {
+		case types.OpProgLoad:
+			event.ProgType = ebpf.ProgramType(bpfEvent.Type).String()
+			// The zero value is BPF_CGROUP_INET_INGRESS for the kernel
+			// but it's also used by the programs that don't need an
+			// expected attach type.
+			if bpfEvent.AttachType != 0 {
+				event.AttachType = ebpf.AttachType(bpfEvent.AttachType).String()
+			}
+		case types.OpMapCreate:
+			event.MapType = ebpf.MapType(bpfEvent.Type).String()
+		}
+
+		if t.enricher != nil {
+			t.enricher.Enrich(&event.CommonData, event.MountNsID)
+		}
+
+		t.eventCallback(event)
+	}
+}
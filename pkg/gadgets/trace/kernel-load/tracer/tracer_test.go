@@ -0,0 +1,209 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"golang.org/x/sys/unix"
+
+	utilstest "github.com/lato333/inspektor-gadget/pkg/gadgets/internal/test"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/kernel-load/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/kernel-load/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+func TestKernelLoadTracerCreate(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+	if tracer == nil {
+		t.Fatal("Returned tracer was nil")
+	}
+}
+
+func TestKernelLoadTracerStopIdempotent(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+
+	// Check that a double stop doesn't cause issues
+	tracer.Stop()
+	tracer.Stop()
+}
+
+func TestKernelLoadTracer(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	type testDefinition struct {
+		getTracerConfig func(info *utilstest.RunnerInfo) *tracer.Config
+		generateEvent   func() error
+		validateEvent   func(t *testing.T, info *utilstest.RunnerInfo, _ interface{}, events []types.Event)
+	}
+
+	baseEvent := func(info *utilstest.RunnerInfo) types.Event {
+		return types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:       uint32(info.Pid),
+			UID:       uint32(info.UID),
+			Comm:      info.Comm,
+			MountNsID: info.MountNsID,
+		}
+	}
+
+	createMap := func() error {
+		m, err := ebpf.NewMap(&ebpf.MapSpec{
+			Name:       "test_map",
+			Type:       ebpf.Hash,
+			KeySize:    4,
+			ValueSize:  4,
+			MaxEntries: 1,
+		})
+		if err != nil {
+			return fmt.Errorf("creating map: %w", err)
+		}
+		return m.Close()
+	}
+
+	for name, test := range map[string]testDefinition{
+		"captures_no_events_with_no_matching_filter": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, 0),
+				}
+			},
+			generateEvent: createMap,
+			validateEvent: utilstest.ExpectNoEvent[types.Event, interface{}],
+		},
+		// The library can load other programs and maps to probe the
+		// features of the kernel: look for ours among the events.
+		"captures_map_create": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: createMap,
+			validateEvent: utilstest.ExpectAtLeastOneEvent(func(info *utilstest.RunnerInfo, _ interface{}) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpMapCreate
+				event.MapType = "Hash"
+				event.Name = "test_map"
+				return &event
+			}),
+		},
+		"captures_prog_load": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func() error {
+				prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+					Name: "test_prog",
+					Type: ebpf.SocketFilter,
+					Instructions: asm.Instructions{
+						asm.LoadImm(asm.R0, 0, asm.DWord),
+						asm.Return(),
+					},
+					License: "GPL",
+				})
+				if err != nil {
+					return fmt.Errorf("loading program: %w", err)
+				}
+				return prog.Close()
+			},
+			validateEvent: utilstest.ExpectAtLeastOneEvent(func(info *utilstest.RunnerInfo, _ interface{}) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpProgLoad
+				event.ProgType = "SocketFilter"
+				event.Name = "test_prog"
+				return &event
+			}),
+		},
+		"captures_failed_finit_module": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func() error {
+				err := unix.FinitModule(-1, "", 0)
+				if !errors.Is(err, unix.EBADF) {
+					return fmt.Errorf("finit_module(-1) returned %v, expected EBADF", err)
+				}
+				return nil
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, _ interface{}) *types.Event {
+				event := baseEvent(info)
+				event.Op = types.OpFinitModule
+				event.Ret = -int(unix.EBADF)
+				return &event
+			}),
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events := []types.Event{}
+			eventCallback := func(event types.Event) {
+				events = append(events, event)
+			}
+
+			runner := utilstest.NewRunnerWithTest(t, nil)
+
+			createTracer(t, test.getTracerConfig(runner.Info), eventCallback)
+
+			utilstest.RunWithRunner(t, runner, test.generateEvent)
+
+			// Give some time for the tracer to capture the events
+			time.Sleep(100 * time.Millisecond)
+
+			test.validateEvent(t, runner.Info, nil, events)
+		})
+	}
+}
+
+func createTracer(
+	t *testing.T, config *tracer.Config, callback func(types.Event),
+) *tracer.Tracer {
+	t.Helper()
+
+	tracer, err := tracer.NewTracer(config, nil, callback)
+	if err != nil {
+		t.Fatalf("Error creating tracer: %s", err)
+	}
+	t.Cleanup(tracer.Stop)
+
+	return tracer
+}
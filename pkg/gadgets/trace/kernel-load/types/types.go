@@ -0,0 +1,67 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+const (
+	OpInitModule  = "init_module"
+	OpFinitModule = "finit_module"
+	OpProgLoad    = "prog_load"
+	OpMapCreate   = "map_create"
+)
+
+type Event struct {
+	eventtypes.Event
+
+	Pid  uint32 `json:"pid,omitempty" column:"pid,template:pid"`
+	UID  uint32 `json:"uid,omitempty" column:"uid,minWidth:10,hide"`
+	Comm string `json:"comm,omitempty" column:"comm,template:comm"`
+	Op   string `json:"op,omitempty" column:"op,width:12,fixed"`
+
+	// Ret is 0 when the module, program or map was loaded, or the error
+	// returned by the syscall.
+	Ret int `json:"ret,omitempty" column:"ret,width:4,fixed"`
+
+	// ProgType is the type of BPF program, like "Kprobe", and AttachType
+	// its expected attach type, if any. They're only set for prog_load.
+	// It's not named Type to not shadow eventtypes.Event.Type.
+	ProgType   string `json:"progType,omitempty" column:"progtype,width:16"`
+	AttachType string `json:"attachType,omitempty" column:"attachtype,width:20"`
+
+	// MapType is the type of BPF map, like "Hash", only set for
+	// map_create.
+	MapType string `json:"mapType,omitempty" column:"maptype,width:16"`
+
+	// Name is the name of the kernel module, or of the file passed to
+	// finit_module when the load failed before the module was parsed, or
+	// the name of the BPF program or map.
+	Name string `json:"name,omitempty" column:"name,width:32"`
+
+	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+}
+
+func GetColumns() *columns.Columns[Event] {
+	return columns.MustCreateColumns[Event]()
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -6,6 +6,31 @@
 #include "mountsnoop.h"
 
 #define MAX_ENTRIES 10240
+#define MAX_PENDING_MOUNTS 1024
+
+// include/uapi/linux/mount.h
+#define FSCONFIG_SET_FLAG	0
+#define FSCONFIG_SET_STRING	1
+#define FSCONFIG_SET_PATH	3
+#define FSCONFIG_SET_PATH_EMPTY	4
+#define MOVE_MOUNT_F_EMPTY_PATH	0x00000004
+#define OPEN_TREE_CLONE		1
+
+// include/uapi/linux/fcntl.h
+#define AT_EMPTY_PATH		0x1000
+#define AT_RECURSIVE		0x8000
+
+// include/uapi/linux/mount.h
+#define MS_BIND			4096
+#define MS_MOVE			8192
+#define MS_REC			16384
+
+// The first fields of struct mount_attr, which can be extended.
+struct mount_attr_prefix {
+	__u64 attr_set;
+	__u64 attr_clr;
+	__u64 propagation;
+};
 
 const volatile pid_t target_pid = 0;
 const volatile bool filter_by_mnt_ns = false;
@@ -40,17 +65,30 @@ struct {
 	__uint(value_size, sizeof(u32));
 } mount_ns_filter SEC(".maps");
 
-// TODO: have to use "inline" to avoid this error:
-// bpf/mountsnoop.bpf.c:41:12: error: defined with too many args
-// static int probe_entry(const char *src, const char *dest, const char *fs,
-static inline int probe_entry(const char *src, const char *dest, const char *fs,
-		       __u64 flags, const char *data, enum op op)
+// pending_mounts keeps the mounts being created with the new mount API by
+// the process and file descriptor they're referred to with. The close of the
+// file descriptors isn't traced: the least recently used ones are dropped
+// first.
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__uint(max_entries, MAX_PENDING_MOUNTS);
+	__type(key, struct fd_key);
+	__type(value, struct pending_mount);
+} pending_mounts SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, int);
+	__type(value, struct pending_mount);
+} pending_heap SEC(".maps");
+
+static __always_inline int store_arg(struct arg *arg)
 {
 	__u64 pid_tgid = bpf_get_current_pid_tgid();
 	__u32 pid = pid_tgid >> 32;
 	__u32 tid = (__u32)pid_tgid;
 	struct task_struct *task;
-	struct arg arg = {};
 	u64 mntns_id;
 
 	task = (struct task_struct*) bpf_get_current_task();
@@ -62,18 +100,264 @@ static inline int probe_entry(const char *src, const char *dest, const char *fs,
 	if (target_pid && target_pid != pid)
 		return 0;
 
-	arg.ts = bpf_ktime_get_ns();
+	arg->ts = bpf_ktime_get_ns();
+	bpf_map_update_elem(&args, &tid, arg, BPF_ANY);
+
+	return 0;
+}
+
+// TODO: have to use "inline" to avoid this error:
+// bpf/mountsnoop.bpf.c:41:12: error: defined with too many args
+// static int probe_entry(const char *src, const char *dest, const char *fs,
+static inline int probe_entry(const char *src, const char *dest, const char *fs,
+		       __u64 flags, const char *data, enum op op)
+{
+	struct arg arg = {};
+
 	arg.flags = flags;
 	arg.src = src;
 	arg.dest = dest;
 	arg.fs = fs;
 	arg.data= data;
 	arg.op = op;
-	bpf_map_update_elem(&args, &tid, &arg, BPF_ANY);
 
-	return 0;
+	return store_arg(&arg);
 };
 
+static __always_inline struct pending_mount *
+lookup_pending(__u32 tgid, int fd, __u64 start_time)
+{
+	struct fd_key key = { .tgid = tgid, .fd = fd };
+	struct pending_mount *p;
+
+	p = bpf_map_lookup_elem(&pending_mounts, &key);
+	if (p && p->start_time != start_time) {
+		// Left by a previous process with the same PID
+		bpf_map_delete_elem(&pending_mounts, &key);
+		return NULL;
+	}
+
+	return p;
+}
+
+static __always_inline struct pending_mount *new_pending(__u64 start_time)
+{
+	struct pending_mount *p;
+	int zero = 0;
+
+	p = bpf_map_lookup_elem(&pending_heap, &zero);
+	if (!p)
+		return NULL;
+
+	// The strings are only read up to their end, don't clear them all
+	p->start_time = start_time;
+	p->latency = 0;
+	p->flags = 0;
+	p->attrs = 0;
+	p->propagation = 0;
+	p->options_len = 0;
+	p->calls_len = 0;
+	p->fs[0] = '\0';
+	p->source[0] = '\0';
+	p->options[0] = '\0';
+
+	return p;
+}
+
+static __always_inline void add_call(struct pending_mount *p, enum op op,
+				     __u64 latency)
+{
+	__u8 len = p->calls_len;
+
+	p->latency += latency;
+
+	// Keep the sequence short when a call is repeated, like fsconfig()
+	if (len > 0 && p->calls[(len - 1) & (MAX_CALLS - 1)] == op)
+		return;
+	if (len >= MAX_CALLS)
+		return;
+
+	p->calls[len & (MAX_CALLS - 1)] = op;
+	p->calls_len = len + 1;
+}
+
+// options_off returns the offset in the options for the verifier to see it's
+// below DATA_LEN: the compiler would otherwise drop the mask as the callers
+// already checked it.
+static __always_inline __u32 options_off(__u32 off)
+{
+	asm volatile("" : "+r"(off));
+	return off & (DATA_LEN - 1);
+}
+
+// append_option appends "key", or "key=value" if value isn't NULL or empty,
+// to the comma separated options of the mount.
+static __always_inline void append_option(struct pending_mount *p,
+					  const __u8 *key, const __u8 *value)
+{
+	__u32 off = p->options_len;
+	long n;
+
+	if (off >= DATA_LEN - 1)
+		return;
+	if (off > 0) {
+		p->options[options_off(off)] = ',';
+		off++;
+	}
+
+	n = bpf_probe_read_kernel_str(&p->options[options_off(off)], DATA_LEN, key);
+	if (n > 0)
+		off += n - 1;
+
+	if (value && value[0] != '\0' && off < DATA_LEN - 1) {
+		p->options[options_off(off)] = '=';
+		off++;
+		n = bpf_probe_read_kernel_str(&p->options[options_off(off)], DATA_LEN, value);
+		if (n > 0)
+			off += n - 1;
+	}
+
+	p->options_len = off;
+}
+
+static __always_inline bool is_source_key(const __u8 *key)
+{
+	return key[0] == 's' && key[1] == 'o' && key[2] == 'u' && key[3] == 'r' &&
+	       key[4] == 'c' && key[5] == 'e' && key[6] == '\0';
+}
+
+static __always_inline void add_pending(__u32 tgid, int fd,
+					struct pending_mount *p)
+{
+	struct fd_key key = { .tgid = tgid, .fd = fd };
+
+	bpf_map_update_elem(&pending_mounts, &key, p, BPF_ANY);
+}
+
+// handle_mount_api correlates the calls of the new mount API, made with file
+// descriptors, into the event of move_mount(). It returns true if the event
+// has to be reported: the mount once it's attached, or the calls that failed
+// or don't belong to a known mount.
+static __always_inline bool handle_mount_api(struct event *eventp,
+					     struct arg *argp,
+					     struct task_struct *task,
+					     __u32 pid, int ret)
+{
+	__u64 start_time = BPF_CORE_READ(task, group_leader, start_time);
+	struct fd_key key = { .tgid = pid, .fd = argp->fd };
+	struct pending_mount *p;
+
+	switch (argp->op) {
+	case FSOPEN:
+		if (ret < 0)
+			return true;
+
+		p = new_pending(start_time);
+		if (!p)
+			return false;
+		__builtin_memcpy(p->fs, eventp->fs, sizeof(p->fs));
+		add_call(p, FSOPEN, eventp->delta);
+		add_pending(pid, ret, p);
+		return false;
+	case FSCONFIG:
+		p = lookup_pending(pid, argp->fd, start_time);
+		if (ret < 0 || !p) {
+			if (p)
+				__builtin_memcpy(eventp->fs, p->fs, sizeof(eventp->fs));
+			return true;
+		}
+
+		add_call(p, FSCONFIG, eventp->delta);
+		switch (argp->cmd) {
+		case FSCONFIG_SET_FLAG:
+			append_option(p, eventp->src, NULL);
+			break;
+		case FSCONFIG_SET_STRING:
+		case FSCONFIG_SET_PATH:
+		case FSCONFIG_SET_PATH_EMPTY:
+			if (is_source_key(eventp->src))
+				bpf_probe_read_kernel_str(p->source, sizeof(p->source), eventp->data);
+			else
+				append_option(p, eventp->src, eventp->data);
+			break;
+		}
+		return false;
+	case FSMOUNT:
+		p = lookup_pending(pid, argp->fd, start_time);
+		if (ret < 0 || !p) {
+			if (p) {
+				__builtin_memcpy(eventp->fs, p->fs, sizeof(eventp->fs));
+				bpf_probe_read_kernel_str(eventp->src, sizeof(eventp->src), p->source);
+			}
+			return true;
+		}
+
+		add_call(p, FSMOUNT, eventp->delta);
+		p->attrs = eventp->attr_set;
+		add_pending(pid, ret, p);
+		bpf_map_delete_elem(&pending_mounts, &key);
+		return false;
+	case OPEN_TREE:
+		if (ret < 0)
+			return true;
+
+		p = new_pending(start_time);
+		if (!p)
+			return false;
+		bpf_probe_read_kernel_str(p->source, sizeof(p->source), eventp->src);
+		// Without OPEN_TREE_CLONE, it's an existing mount that can be
+		// moved. Otherwise, it's a bind mount of the path.
+		p->flags = MS_MOVE;
+		if (argp->flags & OPEN_TREE_CLONE) {
+			p->flags = MS_BIND;
+			if (argp->flags & AT_RECURSIVE)
+				p->flags |= MS_REC;
+		}
+		add_call(p, OPEN_TREE, eventp->delta);
+		add_pending(pid, ret, p);
+		return false;
+	case MOUNT_SETATTR:
+		// Attributes set on a detached mount before attaching it, like
+		// the ID mapping of the mounts of a container
+		p = lookup_pending(pid, argp->fd, start_time);
+		if (!p || ret != 0 || eventp->dest[0] != '\0' ||
+		    !(argp->flags & AT_EMPTY_PATH))
+			return true;
+
+		add_call(p, MOUNT_SETATTR, eventp->delta);
+		p->attrs = (p->attrs & ~eventp->attr_clr) | eventp->attr_set;
+		if (eventp->propagation)
+			p->propagation = eventp->propagation;
+		return false;
+	case MOVE_MOUNT:
+		p = NULL;
+		if (argp->flags & MOVE_MOUNT_F_EMPTY_PATH)
+			p = lookup_pending(pid, argp->fd, start_time);
+		if (!p) {
+			// Move of a mount given by its path
+			eventp->flags = MS_MOVE;
+			eventp->calls[0] = MOVE_MOUNT;
+			eventp->calls_len = 1;
+			return true;
+		}
+
+		add_call(p, MOVE_MOUNT, eventp->delta);
+		eventp->delta = p->latency;
+		eventp->flags = p->flags;
+		eventp->attr_set = p->attrs;
+		eventp->propagation = p->propagation;
+		__builtin_memcpy(eventp->calls, p->calls, sizeof(eventp->calls));
+		eventp->calls_len = p->calls_len;
+		__builtin_memcpy(eventp->fs, p->fs, sizeof(eventp->fs));
+		bpf_probe_read_kernel_str(eventp->src, sizeof(eventp->src), p->source);
+		bpf_probe_read_kernel_str(eventp->data, sizeof(eventp->data), p->options);
+		bpf_map_delete_elem(&pending_mounts, &key);
+		return true;
+	}
+
+	return true;
+}
+
 static int probe_exit(void *ctx, int ret)
 {
 	__u64 pid_tgid = bpf_get_current_pid_tgid();
@@ -107,6 +391,23 @@ static int probe_exit(void *ctx, int ret)
 	eventp->mnt_ns = BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
 	eventp->ret = ret;
 	eventp->op = argp->op;
+	eventp->fd = argp->fd;
+	eventp->cmd = argp->cmd;
+	eventp->attr_set = 0;
+	eventp->attr_clr = 0;
+	eventp->propagation = 0;
+	eventp->calls_len = 0;
+	if (argp->op == FSMOUNT) {
+		eventp->attr_set = argp->flags;
+		eventp->flags = 0;
+	} else if (argp->op == MOUNT_SETATTR && argp->attr) {
+		struct mount_attr_prefix attr = {};
+
+		bpf_probe_read_user(&attr, sizeof(attr), argp->attr);
+		eventp->attr_set = attr.attr_set;
+		eventp->attr_clr = attr.attr_clr;
+		eventp->propagation = attr.propagation;
+	}
 	bpf_get_current_comm(&eventp->comm, sizeof(eventp->comm));
 	if (argp->src)
 		bpf_probe_read_user_str(eventp->src, sizeof(eventp->src), argp->src);
@@ -120,12 +421,18 @@ static int probe_exit(void *ctx, int ret)
 		bpf_probe_read_user_str(eventp->fs, sizeof(eventp->fs), argp->fs);
 	else
 		eventp->fs[0] = '\0';
-	if (argp->data)
+	// The value of fsconfig() isn't a string for all the commands
+	if (argp->data && (argp->op != FSCONFIG ||
+			   argp->cmd == FSCONFIG_SET_STRING ||
+			   argp->cmd == FSCONFIG_SET_PATH ||
+			   argp->cmd == FSCONFIG_SET_PATH_EMPTY))
 		bpf_probe_read_user_str(eventp->data, sizeof(eventp->data), argp->data);
 	else
 		eventp->data[0] = '\0';
 
-	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, eventp, sizeof(*eventp));
+	if (argp->op == MOUNT || argp->op == UMOUNT ||
+	    handle_mount_api(eventp, argp, task, pid, ret))
+		bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, eventp, sizeof(*eventp));
 
 	bpf_map_delete_elem(&args, &tid);
 	return 0;
@@ -164,4 +471,120 @@ int ig_umount_x(struct trace_event_raw_sys_exit *ctx)
 	return probe_exit(ctx, (int)ctx->ret);
 }
 
+SEC("tracepoint/syscalls/sys_enter_fsopen")
+int ig_fsopen_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct arg arg = {};
+
+	arg.fs = (const char *)ctx->args[0];
+	arg.flags = (__u64)ctx->args[1];
+	arg.op = FSOPEN;
+
+	return store_arg(&arg);
+}
+
+SEC("tracepoint/syscalls/sys_exit_fsopen")
+int ig_fsopen_x(struct trace_event_raw_sys_exit *ctx)
+{
+	return probe_exit(ctx, (int)ctx->ret);
+}
+
+SEC("tracepoint/syscalls/sys_enter_fsconfig")
+int ig_fsconfig_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct arg arg = {};
+
+	arg.fd = (int)ctx->args[0];
+	arg.cmd = (__u32)ctx->args[1];
+	arg.src = (const char *)ctx->args[2];
+	arg.data = (const char *)ctx->args[3];
+	arg.op = FSCONFIG;
+
+	return store_arg(&arg);
+}
+
+SEC("tracepoint/syscalls/sys_exit_fsconfig")
+int ig_fsconfig_x(struct trace_event_raw_sys_exit *ctx)
+{
+	return probe_exit(ctx, (int)ctx->ret);
+}
+
+SEC("tracepoint/syscalls/sys_enter_fsmount")
+int ig_fsmount_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct arg arg = {};
+
+	arg.fd = (int)ctx->args[0];
+	// attr_flags, the flags of fsmount() are only FSMOUNT_CLOEXEC
+	arg.flags = (__u64)ctx->args[2];
+	arg.op = FSMOUNT;
+
+	return store_arg(&arg);
+}
+
+SEC("tracepoint/syscalls/sys_exit_fsmount")
+int ig_fsmount_x(struct trace_event_raw_sys_exit *ctx)
+{
+	return probe_exit(ctx, (int)ctx->ret);
+}
+
+SEC("tracepoint/syscalls/sys_enter_move_mount")
+int ig_move_mount_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct arg arg = {};
+
+	arg.fd = (int)ctx->args[0];
+	arg.src = (const char *)ctx->args[1];
+	arg.dest = (const char *)ctx->args[3];
+	arg.flags = (__u64)ctx->args[4];
+	arg.op = MOVE_MOUNT;
+
+	return store_arg(&arg);
+}
+
+SEC("tracepoint/syscalls/sys_exit_move_mount")
+int ig_move_mount_x(struct trace_event_raw_sys_exit *ctx)
+{
+	return probe_exit(ctx, (int)ctx->ret);
+}
+
+SEC("tracepoint/syscalls/sys_enter_open_tree")
+int ig_open_tree_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct arg arg = {};
+
+	arg.fd = (int)ctx->args[0];
+	arg.src = (const char *)ctx->args[1];
+	arg.flags = (__u64)ctx->args[2];
+	arg.op = OPEN_TREE;
+
+	return store_arg(&arg);
+}
+
+SEC("tracepoint/syscalls/sys_exit_open_tree")
+int ig_open_tree_x(struct trace_event_raw_sys_exit *ctx)
+{
+	return probe_exit(ctx, (int)ctx->ret);
+}
+
+SEC("tracepoint/syscalls/sys_enter_mount_setattr")
+int ig_mount_setattr_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct arg arg = {};
+
+	arg.fd = (int)ctx->args[0];
+	arg.dest = (const char *)ctx->args[1];
+	arg.flags = (__u64)ctx->args[2];
+	arg.attr = (const void *)ctx->args[3];
+	arg.op = MOUNT_SETATTR;
+
+	return store_arg(&arg);
+}
+
+SEC("tracepoint/syscalls/sys_exit_mount_setattr")
+int ig_mount_setattr_x(struct trace_event_raw_sys_exit *ctx)
+{
+	return probe_exit(ctx, (int)ctx->ret);
+}
+
 char LICENSE[] SEC("license") = "Dual BSD/GPL";
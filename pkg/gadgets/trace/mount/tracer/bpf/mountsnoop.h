@@ -10,8 +10,18 @@
 enum op {
 	MOUNT,
 	UMOUNT,
+	FSOPEN,
+	FSCONFIG,
+	FSMOUNT,
+	MOVE_MOUNT,
+	OPEN_TREE,
+	MOUNT_SETATTR,
 };
 
+// The calls of the new mount API that created a mount, consecutive repeated
+// calls like fsconfig() are only kept once.
+#define MAX_CALLS	8
+
 struct arg {
 	__u64 ts;
 	__u64 flags;
@@ -19,9 +29,23 @@ struct arg {
 	const char *dest;
 	const char *fs;
 	const char *data;
+	const void *attr;
+	int fd;
+	__u32 cmd;
 	enum op op;
 };
 
+// The calls of the new mount API are reported once move_mount() attached the
+// mount: its event has the fs, src, data, flags, attr_set and propagation of
+// the whole mount, the calls made and their total latency in delta.
+// The other calls are only reported when they aren't part of a mount, e.g.
+// when they failed, and use the fields of mount() this way:
+// - fsopen: fs.
+// - fsconfig: fd, cmd, src for the key, data for the value and fs.
+// - fsmount: fd, fs, src and attr_set for the attributes of the mount.
+// - open_tree: fd, src for the path and flags.
+// - mount_setattr: fd, dest for the path, flags and attr_set, attr_clr and
+//   propagation.
 struct event {
 	__u64 delta;
 	__u64 flags;
@@ -30,6 +54,13 @@ struct event {
 	__u64 mount_ns_id;
 	unsigned int mnt_ns;
 	int ret;
+	int fd;
+	__u32 cmd;
+	__u64 attr_set;
+	__u64 attr_clr;
+	__u64 propagation;
+	__u8 calls[MAX_CALLS];
+	__u8 calls_len;
 	__u8 comm[TASK_COMM_LEN];
 	__u8 fs[FS_NAME_LEN];
 	__u8 src[PATH_MAX];
@@ -38,4 +69,31 @@ struct event {
 	enum op op;
 };
 
+struct fd_key {
+	__u32 tgid;
+	int fd;
+};
+
+// pending_mount is a mount being created with the new mount API: a
+// filesystem context created by fsopen() and configured by fsconfig(), then
+// a detached mount created by fsmount() or open_tree(), until move_mount()
+// attaches it.
+struct pending_mount {
+	// The start time of the process, not to use the file descriptors of
+	// a previous process with the same PID
+	__u64 start_time;
+	__u64 latency;
+	__u64 flags;
+	__u64 attrs;
+	__u64 propagation;
+	__u32 options_len;
+	__u8 calls[MAX_CALLS];
+	__u8 calls_len;
+	__u8 fs[FS_NAME_LEN];
+	__u8 source[PATH_MAX];
+	// Options are appended with at most DATA_LEN bytes at an offset below
+	// DATA_LEN, and reported truncated to DATA_LEN.
+	__u8 options[2 * DATA_LEN];
+};
+
 #endif /* __MOUNTSNOOP_H */
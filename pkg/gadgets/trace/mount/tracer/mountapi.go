@@ -0,0 +1,139 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"golang.org/x/sys/unix"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/mount/types"
+)
+
+var mountAPICalls = map[mountsnoopOp]string{
+	mountsnoopOpFSOPEN:        "fsopen",
+	mountsnoopOpFSCONFIG:      "fsconfig",
+	mountsnoopOpFSMOUNT:       "fsmount",
+	mountsnoopOpMOVE_MOUNT:    "move_mount",
+	mountsnoopOpOPEN_TREE:     "open_tree",
+	mountsnoopOpMOUNT_SETATTR: "mount_setattr",
+}
+
+// decodeMountAPI fills the event of a call of the new mount API whose common
+// fields are already set. The calls are correlated in eBPF: move_mount()
+// reports the whole mount, and the other calls are only reported when they
+// aren't part of a mount, e.g. when they failed.
+func decodeMountAPI(event *types.Event, bpfEvent *mountsnoopEvent) {
+	switch bpfEvent.Op {
+	case mountsnoopOpFSOPEN:
+		event.Operation = "fsopen"
+	case mountsnoopOpFSCONFIG:
+		event.Operation = "fsconfig"
+
+		option := event.Source
+		if event.Data != "" {
+			option += "=" + event.Data
+		}
+		event.Source = ""
+		event.Data = option
+	case mountsnoopOpFSMOUNT:
+		event.Operation = "fsmount"
+		event.Flags = attrsToFlags(bpfEvent.AttrSet)
+	case mountsnoopOpOPEN_TREE:
+		event.Operation = "open_tree"
+
+		// Without OPEN_TREE_CLONE, it's an existing mount that can be
+		// moved. Otherwise, it's a bind mount of the path.
+		flags := uint64(unix.MS_MOVE)
+		if bpfEvent.Flags&unix.OPEN_TREE_CLONE != 0 {
+			flags = unix.MS_BIND
+			if bpfEvent.Flags&unix.AT_RECURSIVE != 0 {
+				flags |= unix.MS_REC
+			}
+		}
+		event.Flags = DecodeFlags(flags)
+	case mountsnoopOpMOUNT_SETATTR:
+		event.Operation = "mount_setattr"
+		event.Flags = attrsToFlags(bpfEvent.AttrSet)
+		if bpfEvent.Flags&unix.AT_RECURSIVE != 0 {
+			event.Flags = append([]string{"MS_REC"}, event.Flags...)
+		}
+		event.ClearFlags = attrsToFlags(bpfEvent.AttrClr)
+		event.Propagation = propagationName(bpfEvent.Propagation)
+	case mountsnoopOpMOVE_MOUNT:
+		event.Operation = "mount"
+		event.Flags = append(DecodeFlags(bpfEvent.Flags), attrsToFlags(bpfEvent.AttrSet)...)
+		event.Propagation = propagationName(bpfEvent.Propagation)
+
+		callsLen := int(bpfEvent.CallsLen)
+		if callsLen > len(bpfEvent.Calls) {
+			callsLen = len(bpfEvent.Calls)
+		}
+		for _, call := range bpfEvent.Calls[:callsLen] {
+			event.Calls = append(event.Calls, mountAPICalls[mountsnoopOp(call)])
+		}
+	}
+}
+
+// attrsToFlags returns the names of the mount() flags equivalent to the
+// MOUNT_ATTR_* attributes. The ones without an equivalent keep their name.
+func attrsToFlags(attrs uint64) []string {
+	var flags uint64
+
+	for attr, flag := range map[uint64]uint64{
+		unix.MOUNT_ATTR_RDONLY:      unix.MS_RDONLY,
+		unix.MOUNT_ATTR_NOSUID:      unix.MS_NOSUID,
+		unix.MOUNT_ATTR_NODEV:       unix.MS_NODEV,
+		unix.MOUNT_ATTR_NOEXEC:      unix.MS_NOEXEC,
+		unix.MOUNT_ATTR_NODIRATIME:  unix.MS_NODIRATIME,
+		unix.MOUNT_ATTR_NOSYMFOLLOW: unix.MS_NOSYMFOLLOW,
+	} {
+		if attrs&attr != 0 {
+			flags |= flag
+		}
+	}
+
+	switch attrs & unix.MOUNT_ATTR__ATIME {
+	case unix.MOUNT_ATTR_NOATIME:
+		flags |= unix.MS_NOATIME
+	case unix.MOUNT_ATTR_STRICTATIME:
+		flags |= unix.MS_STRICTATIME
+	}
+
+	names := DecodeFlags(flags)
+	if attrs&unix.MOUNT_ATTR_IDMAP != 0 {
+		names = append(names, "MOUNT_ATTR_IDMAP")
+	}
+
+	return names
+}
+
+// propagationName returns the propagation type set by the given mount()
+// flags, if any.
+func propagationName(flags uint64) string {
+	switch {
+	case flags&unix.MS_SHARED != 0:
+		return types.PropagationShared
+	case flags&unix.MS_SLAVE != 0:
+		return types.PropagationSlave
+	case flags&unix.MS_PRIVATE != 0:
+		return types.PropagationPrivate
+	case flags&unix.MS_UNBINDABLE != 0:
+		return types.PropagationUnbindable
+	}
+
+	return ""
+}
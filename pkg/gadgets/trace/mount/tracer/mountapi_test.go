@@ -0,0 +1,144 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/mount/types"
+)
+
+func TestDecodeMountAPI(t *testing.T) {
+	type testDefinition struct {
+		event    types.Event
+		bpfEvent mountsnoopEvent
+		expected types.Event
+	}
+
+	for name, test := range map[string]testDefinition{
+		"new_filesystem": {
+			event: types.Event{
+				Fs:     "ext4",
+				Source: "/dev/sda1",
+				Target: "/mnt",
+				Data:   "ro,errors=remount-ro",
+			},
+			bpfEvent: mountsnoopEvent{
+				Op:          mountsnoopOpMOVE_MOUNT,
+				AttrSet:     unix.MOUNT_ATTR_NOSUID | unix.MOUNT_ATTR_NODEV | unix.MOUNT_ATTR_IDMAP,
+				Propagation: unix.MS_PRIVATE,
+				Calls: [8]uint8{
+					uint8(mountsnoopOpFSOPEN), uint8(mountsnoopOpFSCONFIG), uint8(mountsnoopOpFSMOUNT),
+					uint8(mountsnoopOpMOUNT_SETATTR), uint8(mountsnoopOpMOVE_MOUNT),
+				},
+				CallsLen: 5,
+			},
+			expected: types.Event{
+				Operation:   "mount",
+				Fs:          "ext4",
+				Source:      "/dev/sda1",
+				Target:      "/mnt",
+				Data:        "ro,errors=remount-ro",
+				Flags:       []string{"MS_NOSUID", "MS_NODEV", "MOUNT_ATTR_IDMAP"},
+				Propagation: types.PropagationPrivate,
+				Calls:       []string{"fsopen", "fsconfig", "fsmount", "mount_setattr", "move_mount"},
+			},
+		},
+		"bind": {
+			event: types.Event{Source: "/src", Target: "/dst"},
+			bpfEvent: mountsnoopEvent{
+				Op:       mountsnoopOpMOVE_MOUNT,
+				Flags:    unix.MS_BIND | unix.MS_REC,
+				Calls:    [8]uint8{uint8(mountsnoopOpOPEN_TREE), uint8(mountsnoopOpMOVE_MOUNT)},
+				CallsLen: 2,
+			},
+			expected: types.Event{
+				Operation: "mount",
+				Source:    "/src",
+				Target:    "/dst",
+				Flags:     []string{"MS_BIND", "MS_REC"},
+				Calls:     []string{"open_tree", "move_mount"},
+			},
+		},
+		"move": {
+			event: types.Event{Source: "/src", Target: "/dst"},
+			bpfEvent: mountsnoopEvent{
+				Op:       mountsnoopOpMOVE_MOUNT,
+				Flags:    unix.MS_MOVE,
+				Calls:    [8]uint8{uint8(mountsnoopOpMOVE_MOUNT)},
+				CallsLen: 1,
+			},
+			expected: types.Event{
+				Operation: "mount",
+				Source:    "/src",
+				Target:    "/dst",
+				Flags:     []string{"MS_MOVE"},
+				Calls:     []string{"move_mount"},
+			},
+		},
+		"failed_fsconfig": {
+			event:    types.Event{Fs: "ext4", Source: "foo", Data: "bar"},
+			bpfEvent: mountsnoopEvent{Op: mountsnoopOpFSCONFIG, Fd: 3},
+			expected: types.Event{
+				Operation: "fsconfig",
+				Fs:        "ext4",
+				Data:      "foo=bar",
+			},
+		},
+		"failed_open_tree": {
+			event: types.Event{Source: "/src"},
+			bpfEvent: mountsnoopEvent{
+				Op: mountsnoopOpOPEN_TREE, Fd: unix.AT_FDCWD,
+				Flags: unix.OPEN_TREE_CLONE | unix.AT_RECURSIVE,
+			},
+			expected: types.Event{
+				Operation: "open_tree",
+				Source:    "/src",
+				Flags:     []string{"MS_BIND", "MS_REC"},
+			},
+		},
+		"mount_setattr": {
+			event: types.Event{Target: "/mnt"},
+			bpfEvent: mountsnoopEvent{
+				Op: mountsnoopOpMOUNT_SETATTR, Fd: unix.AT_FDCWD, Flags: unix.AT_RECURSIVE,
+				AttrSet: unix.MOUNT_ATTR_RDONLY, AttrClr: unix.MOUNT_ATTR__ATIME | unix.MOUNT_ATTR_NOEXEC,
+				Propagation: unix.MS_SHARED,
+			},
+			expected: types.Event{
+				Operation:   "mount_setattr",
+				Target:      "/mnt",
+				Flags:       []string{"MS_REC", "MS_RDONLY"},
+				ClearFlags:  []string{"MS_NOEXEC"},
+				Propagation: types.PropagationShared,
+			},
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			event := test.event
+			decodeMountAPI(&event, &test.bpfEvent)
+			if !reflect.DeepEqual(event, test.expected) {
+				t.Fatalf("expected %+v, got %+v", test.expected, event)
+			}
+		})
+	}
+}
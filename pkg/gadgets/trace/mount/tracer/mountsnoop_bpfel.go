@@ -14,27 +14,40 @@ import (
 )
 
 type mountsnoopEvent struct {
-	Delta     uint64
-	Flags     uint64
-	Pid       uint32
-	Tid       uint32
-	MountNsId uint64
-	MntNs     uint32
-	Ret       int32
-	Comm      [16]uint8
-	Fs        [8]uint8
-	Src       [4096]uint8
-	Dest      [4096]uint8
-	Data      [512]uint8
-	Op        mountsnoopOp
-	_         [4]byte
+	Delta       uint64
+	Flags       uint64
+	Pid         uint32
+	Tid         uint32
+	MountNsId   uint64
+	MntNs       uint32
+	Ret         int32
+	Fd          int32
+	Cmd         uint32
+	AttrSet     uint64
+	AttrClr     uint64
+	Propagation uint64
+	Calls       [8]uint8
+	CallsLen    uint8
+	Comm        [16]uint8
+	Fs          [8]uint8
+	Src         [4096]uint8
+	Dest        [4096]uint8
+	Data        [512]uint8
+	_           [3]byte
+	Op          mountsnoopOp
 }
 
 type mountsnoopOp uint32
 
 const (
-	mountsnoopOpMOUNT  mountsnoopOp = 0
-	mountsnoopOpUMOUNT mountsnoopOp = 1
+	mountsnoopOpMOUNT         mountsnoopOp = 0
+	mountsnoopOpUMOUNT        mountsnoopOp = 1
+	mountsnoopOpFSOPEN        mountsnoopOp = 2
+	mountsnoopOpFSCONFIG      mountsnoopOp = 3
+	mountsnoopOpFSMOUNT       mountsnoopOp = 4
+	mountsnoopOpMOVE_MOUNT    mountsnoopOp = 5
+	mountsnoopOpOPEN_TREE     mountsnoopOp = 6
+	mountsnoopOpMOUNT_SETATTR mountsnoopOp = 7
 )
 
 // loadMountsnoop returns the embedded CollectionSpec for mountsnoop.
@@ -78,10 +91,22 @@ type mountsnoopSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type mountsnoopProgramSpecs struct {
-	IgMountE  *ebpf.ProgramSpec `ebpf:"ig_mount_e"`
-	IgMountX  *ebpf.ProgramSpec `ebpf:"ig_mount_x"`
-	IgUmountE *ebpf.ProgramSpec `ebpf:"ig_umount_e"`
-	IgUmountX *ebpf.ProgramSpec `ebpf:"ig_umount_x"`
+	IgFsconfigE     *ebpf.ProgramSpec `ebpf:"ig_fsconfig_e"`
+	IgFsconfigX     *ebpf.ProgramSpec `ebpf:"ig_fsconfig_x"`
+	IgFsmountE      *ebpf.ProgramSpec `ebpf:"ig_fsmount_e"`
+	IgFsmountX      *ebpf.ProgramSpec `ebpf:"ig_fsmount_x"`
+	IgFsopenE       *ebpf.ProgramSpec `ebpf:"ig_fsopen_e"`
+	IgFsopenX       *ebpf.ProgramSpec `ebpf:"ig_fsopen_x"`
+	IgMountE        *ebpf.ProgramSpec `ebpf:"ig_mount_e"`
+	IgMountSetattrE *ebpf.ProgramSpec `ebpf:"ig_mount_setattr_e"`
+	IgMountSetattrX *ebpf.ProgramSpec `ebpf:"ig_mount_setattr_x"`
+	IgMountX        *ebpf.ProgramSpec `ebpf:"ig_mount_x"`
+	IgMoveMountE    *ebpf.ProgramSpec `ebpf:"ig_move_mount_e"`
+	IgMoveMountX    *ebpf.ProgramSpec `ebpf:"ig_move_mount_x"`
+	IgOpenTreeE     *ebpf.ProgramSpec `ebpf:"ig_open_tree_e"`
+	IgOpenTreeX     *ebpf.ProgramSpec `ebpf:"ig_open_tree_x"`
+	IgUmountE       *ebpf.ProgramSpec `ebpf:"ig_umount_e"`
+	IgUmountX       *ebpf.ProgramSpec `ebpf:"ig_umount_x"`
 }
 
 // mountsnoopMapSpecs contains maps before they are loaded into the kernel.
@@ -92,6 +117,8 @@ type mountsnoopMapSpecs struct {
 	Events        *ebpf.MapSpec `ebpf:"events"`
 	Heap          *ebpf.MapSpec `ebpf:"heap"`
 	MountNsFilter *ebpf.MapSpec `ebpf:"mount_ns_filter"`
+	PendingHeap   *ebpf.MapSpec `ebpf:"pending_heap"`
+	PendingMounts *ebpf.MapSpec `ebpf:"pending_mounts"`
 }
 
 // mountsnoopObjects contains all objects after they have been loaded into the kernel.
@@ -117,6 +144,8 @@ type mountsnoopMaps struct {
 	Events        *ebpf.Map `ebpf:"events"`
 	Heap          *ebpf.Map `ebpf:"heap"`
 	MountNsFilter *ebpf.Map `ebpf:"mount_ns_filter"`
+	PendingHeap   *ebpf.Map `ebpf:"pending_heap"`
+	PendingMounts *ebpf.Map `ebpf:"pending_mounts"`
 }
 
 func (m *mountsnoopMaps) Close() error {
@@ -125,6 +154,8 @@ func (m *mountsnoopMaps) Close() error {
 		m.Events,
 		m.Heap,
 		m.MountNsFilter,
+		m.PendingHeap,
+		m.PendingMounts,
 	)
 }
 
@@ -132,16 +163,40 @@ func (m *mountsnoopMaps) Close() error {
 //
 // It can be passed to loadMountsnoopObjects or ebpf.CollectionSpec.LoadAndAssign.
 type mountsnoopPrograms struct {
-	IgMountE  *ebpf.Program `ebpf:"ig_mount_e"`
-	IgMountX  *ebpf.Program `ebpf:"ig_mount_x"`
-	IgUmountE *ebpf.Program `ebpf:"ig_umount_e"`
-	IgUmountX *ebpf.Program `ebpf:"ig_umount_x"`
+	IgFsconfigE     *ebpf.Program `ebpf:"ig_fsconfig_e"`
+	IgFsconfigX     *ebpf.Program `ebpf:"ig_fsconfig_x"`
+	IgFsmountE      *ebpf.Program `ebpf:"ig_fsmount_e"`
+	IgFsmountX      *ebpf.Program `ebpf:"ig_fsmount_x"`
+	IgFsopenE       *ebpf.Program `ebpf:"ig_fsopen_e"`
+	IgFsopenX       *ebpf.Program `ebpf:"ig_fsopen_x"`
+	IgMountE        *ebpf.Program `ebpf:"ig_mount_e"`
+	IgMountSetattrE *ebpf.Program `ebpf:"ig_mount_setattr_e"`
+	IgMountSetattrX *ebpf.Program `ebpf:"ig_mount_setattr_x"`
+	IgMountX        *ebpf.Program `ebpf:"ig_mount_x"`
+	IgMoveMountE    *ebpf.Program `ebpf:"ig_move_mount_e"`
+	IgMoveMountX    *ebpf.Program `ebpf:"ig_move_mount_x"`
+	IgOpenTreeE     *ebpf.Program `ebpf:"ig_open_tree_e"`
+	IgOpenTreeX     *ebpf.Program `ebpf:"ig_open_tree_x"`
+	IgUmountE       *ebpf.Program `ebpf:"ig_umount_e"`
+	IgUmountX       *ebpf.Program `ebpf:"ig_umount_x"`
 }
 
 func (p *mountsnoopPrograms) Close() error {
 	return _MountsnoopClose(
+		p.IgFsconfigE,
+		p.IgFsconfigX,
+		p.IgFsmountE,
+		p.IgFsmountX,
+		p.IgFsopenE,
+		p.IgFsopenX,
 		p.IgMountE,
+		p.IgMountSetattrE,
+		p.IgMountSetattrX,
 		p.IgMountX,
+		p.IgMoveMountE,
+		p.IgMoveMountX,
+		p.IgOpenTreeE,
+		p.IgOpenTreeX,
 		p.IgUmountE,
 		p.IgUmountX,
 	)
@@ -157,5 +212,6 @@ func _MountsnoopClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed mountsnoop_bpfel.o
 var _MountsnoopBytes []byte
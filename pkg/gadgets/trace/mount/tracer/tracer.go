@@ -47,7 +47,9 @@ type Tracer struct {
 	umountEnterLink link.Link
 	mountExitLink   link.Link
 	umountExitLink  link.Link
-	reader          *perf.Reader
+	// Links of the new mount API, which isn't available on all kernels
+	mountAPILinks []link.Link
+	reader        *perf.Reader
 }
 
 func NewTracer(config *Config, enricher gadgets.DataEnricher,
@@ -72,6 +74,10 @@ func (t *Tracer) Stop() {
 	t.umountEnterLink = gadgets.CloseLink(t.umountEnterLink)
 	t.mountExitLink = gadgets.CloseLink(t.mountExitLink)
 	t.umountExitLink = gadgets.CloseLink(t.umountExitLink)
+	for _, l := range t.mountAPILinks {
+		gadgets.CloseLink(l)
+	}
+	t.mountAPILinks = nil
 
 	if t.reader != nil {
 		t.reader.Close()
@@ -131,6 +137,35 @@ func (t *Tracer) start() error {
 		return fmt.Errorf("error opening tracepoint: %w", err)
 	}
 
+	// fsopen(), fsconfig(), fsmount(), move_mount() and open_tree() were
+	// added in Linux 5.2 and mount_setattr() in 5.12.
+	for _, tp := range []struct {
+		name string
+		prog *ebpf.Program
+	}{
+		{"sys_enter_fsopen", t.objs.IgFsopenE},
+		{"sys_exit_fsopen", t.objs.IgFsopenX},
+		{"sys_enter_fsconfig", t.objs.IgFsconfigE},
+		{"sys_exit_fsconfig", t.objs.IgFsconfigX},
+		{"sys_enter_fsmount", t.objs.IgFsmountE},
+		{"sys_exit_fsmount", t.objs.IgFsmountX},
+		{"sys_enter_move_mount", t.objs.IgMoveMountE},
+		{"sys_exit_move_mount", t.objs.IgMoveMountX},
+		{"sys_enter_open_tree", t.objs.IgOpenTreeE},
+		{"sys_exit_open_tree", t.objs.IgOpenTreeX},
+		{"sys_enter_mount_setattr", t.objs.IgMountSetattrE},
+		{"sys_exit_mount_setattr", t.objs.IgMountSetattrX},
+	} {
+		l, err := link.Tracepoint("syscalls", tp.name, tp.prog, nil)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.mountAPILinks = append(t.mountAPILinks, l)
+	}
+
 	t.reader, err = perf.NewReader(t.objs.mountsnoopMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
 	if err != nil {
 		return fmt.Errorf("error creating perf ring buffer: %w", err)
@@ -182,14 +217,20 @@ func (t *Tracer) run() {
 		switch bpfEvent.Op {
 		case mountsnoopOpMOUNT:
 			event.Operation = "mount"
+			event.Flags = DecodeFlags(bpfEvent.Flags)
+			event.FlagsRaw = bpfEvent.Flags
+			event.Propagation = propagationName(bpfEvent.Flags)
 		case mountsnoopOpUMOUNT:
 			event.Operation = "umount"
+			event.Flags = DecodeFlags(bpfEvent.Flags)
+			event.FlagsRaw = bpfEvent.Flags
+		case mountsnoopOpFSOPEN, mountsnoopOpFSCONFIG, mountsnoopOpFSMOUNT,
+			mountsnoopOpMOVE_MOUNT, mountsnoopOpOPEN_TREE, mountsnoopOpMOUNT_SETATTR:
+			decodeMountAPI(&event, bpfEvent)
 		default:
 			event.Operation = "unknown"
 		}
 
-		event.Flags = DecodeFlags(bpfEvent.Flags)
-
 		if t.enricher != nil {
 			t.enricher.Enrich(&event.CommonData, event.MountNsID)
 		}
@@ -24,12 +24,12 @@ var flagNames = []string{
 	"MS_MANDLOCK",
 	"MS_DIRSYNC",
 	"MS_NOSYMFOLLOW",
+	"", // unused
 	"MS_NOATIME",
 	"MS_NODIRATIME",
 	"MS_BIND",
 	"MS_MOVE",
 	"MS_REC",
-	"MS_VERBOSE",
 	"MS_SILENT",
 	"MS_POSIXACL",
 	"MS_UNBINDABLE",
@@ -53,7 +53,7 @@ func DecodeFlags(flags uint64) []string {
 	flagsStr := []string{}
 
 	for i, val := range flagNames {
-		if (1<<i)&flags == 0 {
+		if val == "" || (1<<i)&flags == 0 {
 			continue
 		}
 		flagsStr = append(flagsStr, val)
@@ -0,0 +1,36 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeFlags(t *testing.T) {
+	// Values from include/uapi/linux/mount.h
+	for flags, expected := range map[uint64][]string{
+		0:             {},
+		1 << 10:       {"MS_NOATIME"},
+		1<<12 | 1<<14: {"MS_BIND", "MS_REC"},
+		1 << 15:       {"MS_SILENT"},
+		1<<0 | 1<<31:  {"MS_RDONLY", "MS_NOUSER"},
+		1<<9 | 1<<11:  {"MS_NODIRATIME"},
+	} {
+		if actual := DecodeFlags(flags); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("DecodeFlags(%#x): expected %v, got %v", flags, expected, actual)
+		}
+	}
+}
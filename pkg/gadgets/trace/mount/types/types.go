@@ -22,6 +22,13 @@ import (
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
 
+const (
+	PropagationShared     = "shared"
+	PropagationSlave      = "slave"
+	PropagationPrivate    = "private"
+	PropagationUnbindable = "unbindable"
+)
+
 type Event struct {
 	eventtypes.Event
 
@@ -29,7 +36,7 @@ type Event struct {
 	Pid       uint32   `json:"pid,omitempty" column:"pid,template:pid"`
 	Tid       uint32   `json:"tid,omitempty" column:"tid,template:pid"`
 	MountNsID uint64   `json:"mntnsid,omitempty" column:"mntns,template:ns"`
-	Operation string   `json:"operation,omitempty" column:"op,minWidth:5,maxWidth:7,hide"`
+	Operation string   `json:"operation,omitempty" column:"op,minWidth:5,maxWidth:13,hide"`
 	Retval    int      `json:"ret,omitempty" column:"ret,width:3,fixed,hide"`
 	Latency   uint64   `json:"latency,omitempty" column:"latency,minWidth:3,hide"`
 	Fs        string   `json:"fs,omitempty" column:"fs,minWidth:3,maxWidth:8,hide"`
@@ -38,6 +45,13 @@ type Event struct {
 	Data      string   `json:"data,omitempty" column:"data,width:16,hide"`
 	Flags     []string `json:"flags,omitempty" column:"flags,width:24,hide"`
 	FlagsRaw  uint64   `json:"flagsRaw,omitempty"`
+
+	// ClearFlags are the flags cleared by mount_setattr().
+	ClearFlags  []string `json:"clearFlags,omitempty" column:"clearflags,width:24,hide"`
+	Propagation string   `json:"propagation,omitempty" column:"propagation,width:10,hide"`
+	// Calls are the calls of the new mount API that created the mount, e.g.
+	// fsopen, fsconfig, fsmount and move_mount.
+	Calls []string `json:"calls,omitempty" column:"calls,width:32,hide"`
 }
 
 func GetColumns() *columns.Columns[Event] {
@@ -57,6 +71,22 @@ func GetColumns() *columns.Columns[Event] {
 			case "umount":
 				format := `umount("%s", %s) = %d`
 				return fmt.Sprintf(format, e.Target, strings.Join(e.Flags, " | "), e.Retval)
+			case "mount_setattr":
+				format := `mount_setattr("%s", %s, %s, "%s") = %d`
+				return fmt.Sprintf(format, e.Target, strings.Join(e.Flags, " | "),
+					strings.Join(e.ClearFlags, " | "), e.Propagation, e.Retval)
+			case "fsopen":
+				format := `fsopen("%s") = %d`
+				return fmt.Sprintf(format, e.Fs, e.Retval)
+			case "fsconfig":
+				format := `fsconfig("%s", "%s") = %d`
+				return fmt.Sprintf(format, e.Fs, e.Data, e.Retval)
+			case "fsmount":
+				format := `fsmount("%s", "%s", %s) = %d`
+				return fmt.Sprintf(format, e.Fs, e.Source, strings.Join(e.Flags, " | "), e.Retval)
+			case "open_tree":
+				format := `open_tree("%s", %s) = %d`
+				return fmt.Sprintf(format, e.Source, strings.Join(e.Flags, " | "), e.Retval)
 			}
 
 			return ""
@@ -67,6 +97,14 @@ func GetColumns() *columns.Columns[Event] {
 		return strings.Join(event.Flags, " | ")
 	})
 
+	cols.MustSetExtractor("clearflags", func(event *Event) string {
+		return strings.Join(event.ClearFlags, " | ")
+	})
+
+	cols.MustSetExtractor("calls", func(event *Event) string {
+		return strings.Join(event.Calls, ", ")
+	})
+
 	return cols
 }
 
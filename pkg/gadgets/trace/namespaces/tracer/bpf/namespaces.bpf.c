@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: GPL-2.0
+/* Copyright (c) 2022 The Inspektor Gadget authors */
+#include <vmlinux/vmlinux.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+
+#include "namespaces.h"
+
+#define MAX_ENTRIES	10240
+
+/* Define here, because there are conflicts with include files */
+#define CSIGNAL		0x000000ff
+#define CLONE_NEWTIME	0x00000080
+#define CLONE_NEWNS	0x00020000
+#define CLONE_NEWCGROUP	0x02000000
+#define CLONE_NEWUTS	0x04000000
+#define CLONE_NEWIPC	0x08000000
+#define CLONE_NEWUSER	0x10000000
+#define CLONE_NEWPID	0x20000000
+#define CLONE_NEWNET	0x40000000
+
+#define NS_FLAGS	(CLONE_NEWTIME | CLONE_NEWNS | CLONE_NEWCGROUP | \
+			 CLONE_NEWUTS | CLONE_NEWIPC | CLONE_NEWUSER | \
+			 CLONE_NEWPID | CLONE_NEWNET)
+
+#define NSFS_MAGIC	0x6e736673
+
+const volatile bool filter_by_mnt_ns = false;
+
+// Linux 6.18 moved the type of the namespaces from their operations to the
+// ns_type field, which isn't present in the vmlinux.h we use.
+struct ns_common___type {
+	u32 ns_type;
+} __attribute__((preserve_access_index));
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event *unusedevent __attribute__((unused));
+
+// values keeps the events between the enter and the exit of the syscalls
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, __u32);
+	__type(value, struct event);
+} values SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+	__uint(key_size, sizeof(__u32));
+	__uint(value_size, sizeof(__u32));
+} events SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 1024);
+	__uint(key_size, sizeof(u64));
+	__uint(value_size, sizeof(u32));
+} mount_ns_filter SEC(".maps");
+
+static __always_inline int probe_entry(enum syscall syscall, __u32 types, __u64 inode)
+{
+	struct task_struct *task = (struct task_struct *) bpf_get_current_task();
+	struct event event = {};
+	__u64 pid_tgid;
+	u64 mntns_id;
+	__u32 tid;
+
+	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+		return 0;
+
+	pid_tgid = bpf_get_current_pid_tgid();
+	tid = (__u32)pid_tgid;
+
+	event.mntns_id = mntns_id;
+	event.pid = pid_tgid >> 32;
+	event.syscall = syscall;
+	event.types = types;
+	event.inode = inode;
+	bpf_get_current_comm(&event.comm, sizeof(event.comm));
+
+	bpf_map_update_elem(&values, &tid, &event, BPF_ANY);
+	return 0;
+}
+
+SEC("tracepoint/syscalls/sys_enter_setns")
+int ig_setns_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct task_struct *task = (struct task_struct *) bpf_get_current_task();
+	int fd = (int)ctx->args[0];
+	__u32 types = (__u32)ctx->args[1];
+	struct ns_common *ns;
+	struct fdtable *fdt;
+	struct inode *inode;
+	struct file *file;
+	__u64 inum = 0;
+
+	fdt = BPF_CORE_READ(task, files, fdt);
+	if (fd < 0 || fd >= BPF_CORE_READ(fdt, max_fds))
+		goto out;
+
+	if (bpf_probe_read_kernel(&file, sizeof(file), BPF_CORE_READ(fdt, fd) + fd) || !file)
+		goto out;
+
+	// The file descriptor is either a namespace file, whose type is given
+	// by the namespace itself when the nstype argument is 0, or a pidfd,
+	// and nstype is the mask of the namespaces of the process to join.
+	inode = BPF_CORE_READ(file, f_inode);
+	if (BPF_CORE_READ(inode, i_sb, s_magic) != NSFS_MAGIC)
+		goto out;
+
+	ns = (struct ns_common *) BPF_CORE_READ(inode, i_private);
+	inum = BPF_CORE_READ(ns, inum);
+	if (bpf_core_field_exists(((struct ns_common___type *)ns)->ns_type))
+		types = BPF_CORE_READ((struct ns_common___type *)ns, ns_type);
+	else
+		types = BPF_CORE_READ(ns, ops, type);
+
+out:
+	return probe_entry(SYSCALL_SETNS, types & NS_FLAGS, inum);
+}
+
+SEC("tracepoint/syscalls/sys_enter_unshare")
+int ig_unshare_e(struct trace_event_raw_sys_enter *ctx)
+{
+	__u32 types = (__u32)ctx->args[0] & NS_FLAGS;
+
+	if (!types)
+		return 0;
+
+	return probe_entry(SYSCALL_UNSHARE, types, 0);
+}
+
+SEC("tracepoint/syscalls/sys_enter_clone")
+int ig_clone_e(struct trace_event_raw_sys_enter *ctx)
+{
+	// The lowest byte of the flags of clone() is the exit signal of the
+	// child: CLONE_NEWTIME is only supported by clone3().
+	__u32 types = (__u32)ctx->args[0] & NS_FLAGS & ~CSIGNAL;
+
+	if (!types)
+		return 0;
+
+	return probe_entry(SYSCALL_CLONE, types, 0);
+}
+
+SEC("tracepoint/syscalls/sys_enter_clone3")
+int ig_clone3_e(struct trace_event_raw_sys_enter *ctx)
+{
+	struct clone_args *uargs = (struct clone_args *)ctx->args[0];
+	__u64 flags;
+	__u32 types;
+
+	if (bpf_probe_read_user(&flags, sizeof(flags), &uargs->flags))
+		return 0;
+
+	types = flags & NS_FLAGS;
+	if (!types)
+		return 0;
+
+	return probe_entry(SYSCALL_CLONE3, types, 0);
+}
+
+// ig_fork gets the namespaces of the children created by clone() and
+// clone3(). It runs in the context of the parent, before the syscall
+// returns.
+SEC("raw_tracepoint/sched_process_fork")
+int ig_fork(struct bpf_raw_tracepoint_args *ctx)
+{
+	struct task_struct *child = (struct task_struct *)ctx->args[1];
+	__u32 tid = (__u32)bpf_get_current_pid_tgid();
+	struct event *event;
+
+	event = bpf_map_lookup_elem(&values, &tid);
+	if (!event)
+		return 0;
+
+	if (event->types & CLONE_NEWNS)
+		event->new_mntns = BPF_CORE_READ(child, nsproxy, mnt_ns, ns.inum);
+	if (event->types & CLONE_NEWNET)
+		event->new_netns = BPF_CORE_READ(child, nsproxy, net_ns, ns.inum);
+
+	return 0;
+}
+
+// ig_namespaces_x is attached to the exit tracepoints of all the syscalls
+SEC("tracepoint/syscalls/sys_exit")
+int ig_namespaces_x(struct trace_event_raw_sys_exit *ctx)
+{
+	struct task_struct *task = (struct task_struct *) bpf_get_current_task();
+	__u32 tid = (__u32)bpf_get_current_pid_tgid();
+	struct event *event;
+
+	event = bpf_map_lookup_elem(&values, &tid);
+	if (!event)
+		return 0;
+
+	event->ret = ctx->ret;
+
+	// setns() and unshare() move the calling thread itself to the
+	// namespaces joined or created.
+	if ((event->syscall == SYSCALL_SETNS || event->syscall == SYSCALL_UNSHARE) &&
+	    event->ret == 0) {
+		if (event->types & CLONE_NEWNS)
+			event->new_mntns = BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+		if (event->types & CLONE_NEWNET)
+			event->new_netns = BPF_CORE_READ(task, nsproxy, net_ns, ns.inum);
+	}
+
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, event, sizeof(*event));
+
+	bpf_map_delete_elem(&values, &tid);
+	return 0;
+}
+
+char LICENSE[] SEC("license") = "GPL";
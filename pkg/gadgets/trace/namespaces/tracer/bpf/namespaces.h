@@ -0,0 +1,31 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+#ifndef __NAMESPACES_H
+#define __NAMESPACES_H
+
+#define TASK_COMM_LEN	16
+
+enum syscall {
+	SYSCALL_SETNS = 1,
+	SYSCALL_UNSHARE,
+	SYSCALL_CLONE,
+	SYSCALL_CLONE3,
+};
+
+struct event {
+	__u64 mntns_id;
+	// inode is the namespace of the file descriptor passed to setns(), if
+	// it's a namespace file descriptor and not a pidfd.
+	__u64 inode;
+	// new_mntns and new_netns are the mount and network namespaces joined
+	// or created, if they are part of the types.
+	__u64 new_mntns;
+	__u64 new_netns;
+	__u32 pid;
+	__u32 syscall;
+	// types is a mask of the CLONE_NEW* flags of the namespaces involved
+	__u32 types;
+	int ret;
+	__u8 comm[TASK_COMM_LEN];
+};
+
+#endif /* __NAMESPACES_H */
@@ -0,0 +1,291 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/namespaces/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event namespaces ./bpf/namespaces.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+// Keep in sync with enum syscall in bpf/namespaces.h
+var syscallNames = map[uint32]string{
+	1: "setns",
+	2: "unshare",
+	3: "clone",
+	4: "clone3",
+}
+
+var nsTypes = []struct {
+	flag uint32
+	name string
+}{
+	{unix.CLONE_NEWNS, "mnt"},
+	{unix.CLONE_NEWNET, "net"},
+	{unix.CLONE_NEWPID, "pid"},
+	{unix.CLONE_NEWUSER, "user"},
+	{unix.CLONE_NEWUTS, "uts"},
+	{unix.CLONE_NEWIPC, "ipc"},
+	{unix.CLONE_NEWCGROUP, "cgroup"},
+	{unix.CLONE_NEWTIME, "time"},
+}
+
+type Config struct {
+	MountnsMap *ebpf.Map
+}
+
+type Tracer struct {
+	config        *Config
+	enricher      gadgets.DataEnricher
+	eventCallback func(types.Event)
+
+	// The namespaces of the host, to recognize them as targets
+	hostMntns uint64
+	hostNetns uint64
+
+	objs   namespacesObjects
+	links  []link.Link
+	reader *perf.Reader
+}
+
+// NewTracer creates a tracer. The targets are resolved with the mount
+// namespaces of the containers known by enricher, or with their network
+// namespaces if it's a gadgets.DataEnricherByNetNs as well.
+func NewTracer(config *Config, enricher gadgets.DataEnricher,
+	eventCallback func(types.Event),
+) (*Tracer, error) {
+	t := &Tracer{
+		config:        config,
+		enricher:      enricher,
+		eventCallback: eventCallback,
+	}
+
+	if err := t.start(); err != nil {
+		t.Stop()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Tracer) Stop() {
+	for i := range t.links {
+		t.links[i] = gadgets.CloseLink(t.links[i])
+	}
+	t.links = nil
+
+	if t.reader != nil {
+		t.reader.Close()
+	}
+
+	t.objs.Close()
+}
+
+func (t *Tracer) start() error {
+	var err error
+
+	// The tracer runs in the host PID namespace: the namespaces of the
+	// host are the ones of its init process.
+	t.hostMntns, err = containerutils.GetMntNs(1)
+	if err != nil {
+		return fmt.Errorf("error getting host mount namespace: %w", err)
+	}
+	t.hostNetns, err = containerutils.GetNetNs(1)
+	if err != nil {
+		return fmt.Errorf("error getting host network namespace: %w", err)
+	}
+
+	spec, err := loadNamespaces()
+	if err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	mapReplacements := map[string]*ebpf.Map{}
+	filterByMntNs := false
+
+	if t.config.MountnsMap != nil {
+		filterByMntNs = true
+		mapReplacements["mount_ns_filter"] = t.config.MountnsMap
+	}
+
+	consts := map[string]interface{}{
+		"filter_by_mnt_ns": filterByMntNs,
+	}
+
+	if err := spec.RewriteConstants(consts); err != nil {
+		return fmt.Errorf("error RewriteConstants: %w", err)
+	}
+
+	opts := ebpf.CollectionOptions{
+		MapReplacements: mapReplacements,
+	}
+
+	if err := spec.LoadAndAssign(&t.objs, &opts); err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	// clone3 was added in Linux 5.3
+	syscalls := []struct {
+		name     string
+		prog     *ebpf.Program
+		optional bool
+	}{
+		{"setns", t.objs.IgSetnsE, false},
+		{"unshare", t.objs.IgUnshareE, false},
+		{"clone", t.objs.IgCloneE, false},
+		{"clone3", t.objs.IgClone3E, true},
+	}
+
+	for _, syscall := range syscalls {
+		enter, err := link.Tracepoint("syscalls", "sys_enter_"+syscall.name, syscall.prog, nil)
+		if err != nil {
+			if syscall.optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, enter)
+
+		exit, err := link.Tracepoint("syscalls", "sys_exit_"+syscall.name, t.objs.IgNamespacesX, nil)
+		if err != nil {
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+		t.links = append(t.links, exit)
+	}
+
+	fork, err := link.AttachRawTracepoint(link.RawTracepointOptions{
+		Name:    "sched_process_fork",
+		Program: t.objs.IgFork,
+	})
+	if err != nil {
+		return fmt.Errorf("error opening raw tracepoint: %w", err)
+	}
+	t.links = append(t.links, fork)
+
+	t.reader, err = perf.NewReader(t.objs.namespacesMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("error creating perf ring buffer: %w", err)
+	}
+
+	go t.run()
+
+	return nil
+}
+
+// formatTypes returns the names of the namespace types of the mask of
+// CLONE_NEW* flags, like "mnt,net".
+func formatTypes(mask uint32) string {
+	names := []string{}
+	for _, nsType := range nsTypes {
+		if mask&nsType.flag != 0 {
+			names = append(names, nsType.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// resolveTarget returns the owner of the namespaces joined or created by the
+// event: the host, a known container or nothing.
+func (t *Tracer) resolveTarget(event *types.Event) string {
+	var data eventtypes.CommonData
+
+	switch {
+	case event.TargetMntns != 0:
+		if event.TargetMntns == t.hostMntns {
+			return types.TargetHost
+		}
+		if t.enricher != nil {
+			t.enricher.Enrich(&data, event.TargetMntns)
+		}
+	case event.TargetNetns != 0:
+		if event.TargetNetns == t.hostNetns {
+			return types.TargetHost
+		}
+		if enricher, ok := t.enricher.(gadgets.DataEnricherByNetNs); ok {
+			enricher.EnrichByNetNs(&data, event.TargetNetns)
+		}
+	}
+
+	parts := []string{}
+	for _, part := range []string{data.Namespace, data.Pod, data.Container} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (t *Tracer) run() {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				// nothing to do, we're done
+				return
+			}
+
+			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.eventCallback(types.Base(eventtypes.Err(msg)))
+			return
+		}
+
+		if record.LostSamples > 0 {
+			msg := fmt.Sprintf("lost %d samples", record.LostSamples)
+			t.eventCallback(types.Base(eventtypes.Warn(msg)))
+			continue
+		}
+
+		bpfEvent := (*namespacesEvent)(unsafe.Pointer(&record.RawSample[0]))
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:         bpfEvent.Pid,
+			Comm:        gadgets.FromCString(bpfEvent.Comm[:]),
+			Syscall:     syscallNames[bpfEvent.Syscall],
+			Ret:         int(bpfEvent.Ret),
+			Types:       formatTypes(bpfEvent.Types),
+			Inode:       bpfEvent.Inode,
+			TargetMntns: bpfEvent.NewMntns,
+			TargetNetns: bpfEvent.NewNetns,
+			MountNsID:   bpfEvent.MntnsId,
+		}
+		event.Target = t.resolveTarget(&event)
+
+		if t.enricher != nil {
+			t.enricher.Enrich(&event.CommonData, event.MountNsID)
+		}
+
+		t.eventCallback(event)
+	}
+}
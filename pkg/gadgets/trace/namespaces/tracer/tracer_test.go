@@ -0,0 +1,216 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
+	utilstest "github.com/lato333/inspektor-gadget/pkg/gadgets/internal/test"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/namespaces/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/namespaces/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+func TestNamespacesTracerCreate(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+	if tracer == nil {
+		t.Fatal("Returned tracer was nil")
+	}
+}
+
+func TestNamespacesTracerStopIdempotent(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+
+	// Check that a double stop doesn't cause issues
+	tracer.Stop()
+	tracer.Stop()
+}
+
+func TestNamespacesTracer(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	hostNetns, err := containerutils.GetNetNs(1)
+	if err != nil {
+		t.Fatalf("Error getting host network namespace: %s", err)
+	}
+
+	type testDefinition struct {
+		getTracerConfig func(info *utilstest.RunnerInfo) *tracer.Config
+		// generateEvent returns the network namespace of the runner
+		// thread after the event
+		generateEvent func() (uint64, error)
+		validateEvent func(t *testing.T, info *utilstest.RunnerInfo, netns uint64, events []types.Event)
+	}
+
+	baseEvent := func(info *utilstest.RunnerInfo) types.Event {
+		return types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:       uint32(info.Pid),
+			Comm:      info.Comm,
+			MountNsID: info.MountNsID,
+		}
+	}
+
+	// The runner thread is never reused by other goroutines: it can be
+	// moved to other namespaces.
+	threadNetns := func() (uint64, error) {
+		return containerutils.GetNetNs(unix.Gettid())
+	}
+
+	unshare := func(flags int) func() (uint64, error) {
+		return func() (uint64, error) {
+			if err := unix.Unshare(flags); err != nil {
+				return 0, fmt.Errorf("unsharing namespaces: %w", err)
+			}
+			return threadNetns()
+		}
+	}
+
+	for name, test := range map[string]testDefinition{
+		"captures_no_events_with_no_matching_filter": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, 0),
+				}
+			},
+			generateEvent: unshare(unix.CLONE_NEWUTS),
+			validateEvent: utilstest.ExpectNoEvent[types.Event, uint64],
+		},
+		"captures_unshare": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: unshare(unix.CLONE_NEWUTS | unix.CLONE_NEWNET),
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, netns uint64) *types.Event {
+				event := baseEvent(info)
+				event.Syscall = "unshare"
+				event.Types = "net,uts"
+				event.TargetNetns = netns
+				return &event
+			}),
+		},
+		"captures_setns_host": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func() (uint64, error) {
+				fd, err := unix.Open("/proc/1/ns/net", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+				if err != nil {
+					return 0, fmt.Errorf("opening host network namespace: %w", err)
+				}
+				defer unix.Close(fd)
+
+				if err := unix.Setns(fd, 0); err != nil {
+					return 0, fmt.Errorf("joining host network namespace: %w", err)
+				}
+				return threadNetns()
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, netns uint64) *types.Event {
+				event := baseEvent(info)
+				event.Syscall = "setns"
+				event.Types = "net"
+				event.Inode = hostNetns
+				event.TargetNetns = hostNetns
+				event.Target = types.TargetHost
+				return &event
+			}),
+		},
+		"captures_failed_setns": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: func() (uint64, error) {
+				if err := unix.Setns(-1, unix.CLONE_NEWNET); err != unix.EBADF {
+					return 0, fmt.Errorf("setns(-1) returned %v, expected EBADF", err)
+				}
+				return threadNetns()
+			},
+			validateEvent: utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, netns uint64) *types.Event {
+				event := baseEvent(info)
+				event.Syscall = "setns"
+				event.Types = "net"
+				event.Ret = -int(unix.EBADF)
+				return &event
+			}),
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events := []types.Event{}
+			eventCallback := func(event types.Event) {
+				events = append(events, event)
+			}
+
+			runner := utilstest.NewRunnerWithTest(t, nil)
+
+			createTracer(t, test.getTracerConfig(runner.Info), eventCallback)
+
+			var netns uint64
+			utilstest.RunWithRunner(t, runner, func() error {
+				var err error
+				netns, err = test.generateEvent()
+				return err
+			})
+
+			// Give some time for the tracer to capture the events
+			time.Sleep(100 * time.Millisecond)
+
+			test.validateEvent(t, runner.Info, netns, events)
+		})
+	}
+}
+
+func createTracer(
+	t *testing.T, config *tracer.Config, callback func(types.Event),
+) *tracer.Tracer {
+	t.Helper()
+
+	tracer, err := tracer.NewTracer(config, nil, callback)
+	if err != nil {
+		t.Fatalf("Error creating tracer: %s", err)
+	}
+	t.Cleanup(tracer.Stop)
+
+	return tracer
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+// TargetHost is the target of the events joining or creating namespaces of
+// the host.
+const TargetHost = "host"
+
+type Event struct {
+	eventtypes.Event
+
+	Pid     uint32 `json:"pid,omitempty" column:"pid,template:pid"`
+	Comm    string `json:"comm,omitempty" column:"comm,template:comm"`
+	Syscall string `json:"syscall,omitempty" column:"syscall,width:7,fixed"`
+	Ret     int    `json:"ret,omitempty" column:"ret,width:4,fixed"`
+
+	// Types are the types of the namespaces joined or created, like
+	// "mnt,net".
+	Types string `json:"types,omitempty" column:"types,width:16"`
+
+	// Inode is the namespace joined with setns() and a namespace file
+	// descriptor.
+	Inode uint64 `json:"inode,omitempty" column:"inode,width:12"`
+
+	// TargetMntns and TargetNetns are the mount and network namespaces
+	// joined or created, if they are part of the types.
+	TargetMntns uint64 `json:"targetMntns,omitempty" column:"targetmntns,width:12"`
+	TargetNetns uint64 `json:"targetNetns,omitempty" column:"targetnetns,width:12"`
+
+	// Target is the owner of TargetMntns, or of TargetNetns if the mount
+	// namespace isn't part of the types: "host", or the container as
+	// "namespace/pod/container", or "namespace/pod" for a network
+	// namespace shared by the containers of a pod. The names missing
+	// outside of Kubernetes are omitted. It's empty for the namespaces
+	// unknown, like the ones being created.
+	Target string `json:"target,omitempty" column:"target,width:32"`
+
+	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+}
+
+func GetColumns() *columns.Columns[Event] {
+	return columns.MustCreateColumns[Event]()
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -29,7 +29,8 @@ const volatile bool filter_by_mnt_ns = false;
 SEC("kprobe/oom_kill_process")
 int BPF_KPROBE(ig_oom_kill, struct oom_control *oc, const char *message)
 {
-	struct data_t data;
+	struct data_t data = {};
+	struct mem_cgroup *memcg;
 	u64 mntns_id;
 
 	mntns_id = (u64) BPF_CORE_READ(oc, chosen, nsproxy, mnt_ns, ns.inum);
@@ -43,6 +44,16 @@ int BPF_KPROBE(ig_oom_kill, struct oom_control *oc, const char *message)
 	bpf_get_current_comm(&data.fcomm, sizeof(data.fcomm));
 	bpf_probe_read_kernel(&data.tcomm, sizeof(data.tcomm), BPF_CORE_READ(oc, chosen, comm));
 	data.mount_ns_id = mntns_id;
+
+	// memcg is only set when the OOM is caused by a memory cgroup limit, it's
+	// NULL for a global OOM.
+	memcg = BPF_CORE_READ(oc, memcg);
+	if (memcg) {
+		data.memcg_id = BPF_CORE_READ(memcg, css.cgroup, kn, id);
+		data.mem_limit = BPF_CORE_READ(memcg, memory.max);
+		data.mem_usage = BPF_CORE_READ(memcg, memory.usage.counter);
+	}
+
 	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, &data, sizeof(data));
 	return 0;
 }
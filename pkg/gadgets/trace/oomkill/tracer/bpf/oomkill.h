@@ -9,6 +9,11 @@ struct data_t {
 	__u32 tpid;
 	__u64 pages;
 	__u64 mount_ns_id;
+	// memcg_id, mem_limit and mem_usage are only set when a memory cgroup
+	// hit its limit, the latter two in pages.
+	__u64 memcg_id;
+	__u64 mem_limit;
+	__u64 mem_usage;
 	__u8 fcomm[TASK_COMM_LEN];
 	__u8 tcomm[TASK_COMM_LEN];
 };
@@ -18,6 +18,9 @@ type oomkillDataT struct {
 	Tpid      uint32
 	Pages     uint64
 	MountNsId uint64
+	MemcgId   uint64
+	MemLimit  uint64
+	MemUsage  uint64
 	Fcomm     [16]uint8
 	Tcomm     [16]uint8
 }
@@ -127,5 +130,6 @@ func _OomkillClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed oomkill_bpfel_x86.o
 var _OomkillBytes []byte
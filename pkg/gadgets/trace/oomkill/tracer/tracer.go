@@ -26,6 +26,7 @@ import (
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/link"
 	"github.com/cilium/ebpf/perf"
+	"github.com/lato333/inspektor-gadget/pkg/container-utils/cgroups"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/oomkill/types"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
@@ -44,6 +45,10 @@ type Tracer struct {
 	reader        *perf.Reader
 	enricher      gadgets.DataEnricher
 	eventCallback func(types.Event)
+
+	// cgroupV2 is whether the memory cgroup IDs can be matched against the
+	// cgroup v2 IDs of the containers.
+	cgroupV2 bool
 }
 
 func NewTracer(c *Config, enricher gadgets.DataEnricher, eventCallback func(types.Event)) (*Tracer, error) {
@@ -51,6 +56,7 @@ func NewTracer(c *Config, enricher gadgets.DataEnricher, eventCallback func(type
 		config:        c,
 		enricher:      enricher,
 		eventCallback: eventCallback,
+		cgroupV2:      cgroups.IsCgroupV2(),
 	}
 
 	if err := t.start(); err != nil {
@@ -123,6 +129,8 @@ func (t *Tracer) start() error {
 }
 
 func (t *Tracer) run() {
+	pageSize := uint64(os.Getpagesize())
+
 	for {
 		record, err := t.reader.Read()
 		if err != nil {
@@ -147,10 +155,31 @@ func (t *Tracer) run() {
 			KilledComm:    gadgets.FromCString(bpfEvent.Tcomm[:]),
 			Pages:         bpfEvent.Pages,
 			MountNsID:     bpfEvent.MountNsId,
+			Scope:         types.ScopeGlobal,
+		}
+
+		if bpfEvent.MemcgId != 0 {
+			event.Scope = types.ScopeMemcg
+			event.MemcgID = bpfEvent.MemcgId
+			event.MemLimit = bpfEvent.MemLimit * pageSize
+			event.MemUsage = bpfEvent.MemUsage * pageSize
 		}
 
 		if t.enricher != nil {
-			t.enricher.Enrich(&event.CommonData, event.MountNsID)
+			// The container is resolved with the memory cgroup that hit
+			// its limit when it's the one of a container, and with the
+			// mount namespace of the killed process otherwise, e.g. for
+			// global OOMs or when the limit is set on the whole pod. With
+			// cgroup v1, the memory cgroup ID belongs to the v1 memory
+			// hierarchy and can't be matched with the containers' cgroup v2
+			// IDs, so the mount namespace is always used.
+			enriched := false
+			if enricher, ok := t.enricher.(gadgets.DataEnricherByCgroupID); ok && t.cgroupV2 && event.MemcgID != 0 {
+				enriched = enricher.EnrichByCgroupID(&event.CommonData, event.MemcgID)
+			}
+			if !enriched {
+				t.enricher.Enrich(&event.CommonData, event.MountNsID)
+			}
 		}
 
 		t.eventCallback(event)
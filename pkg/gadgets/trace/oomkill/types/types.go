@@ -15,6 +15,10 @@
 package types
 
 import (
+	"fmt"
+
+	"github.com/docker/go-units"
+
 	"github.com/lato333/inspektor-gadget/pkg/columns"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
@@ -28,10 +32,40 @@ type Event struct {
 	TriggeredPid  uint32 `json:"tpid,omitempty" column:"tpid,template:pid"`
 	TriggeredComm string `json:"tcomm,omitempty" column:"tcomm,template:comm"`
 	MountNsID     uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+
+	// Scope is ScopeMemcg when a memory cgroup hit its limit, in which case
+	// the ID of the cgroup, its limit and usage, in bytes, are set, and
+	// ScopeGlobal when the whole node ran out of memory.
+	Scope    Scope  `json:"scope,omitempty" column:"scope,width:6"`
+	MemcgID  uint64 `json:"memcgid,omitempty" column:"memcgid,hide"`
+	MemLimit uint64 `json:"memlimit,omitempty" column:"limit,width:10,align:right"`
+	MemUsage uint64 `json:"memusage,omitempty" column:"usage,width:10,align:right"`
 }
 
+type Scope string
+
+const (
+	ScopeGlobal Scope = "global"
+	ScopeMemcg  Scope = "memcg"
+)
+
 func GetColumns() *columns.Columns[Event] {
-	return columns.MustCreateColumns[Event]()
+	oomkillColumns := columns.MustCreateColumns[Event]()
+
+	oomkillColumns.MustSetExtractor("limit", func(event *Event) string {
+		if event.Scope != ScopeMemcg {
+			return ""
+		}
+		return fmt.Sprint(units.BytesSize(float64(event.MemLimit)))
+	})
+	oomkillColumns.MustSetExtractor("usage", func(event *Event) string {
+		if event.Scope != ScopeMemcg {
+			return ""
+		}
+		return fmt.Sprint(units.BytesSize(float64(event.MemUsage)))
+	})
+
+	return oomkillColumns
 }
 
 func Base(ev eventtypes.Event) Event {
@@ -11,6 +11,10 @@ const volatile pid_t filtered_pid = 0;
 const volatile int target_signal = 0;
 const volatile bool failed_only = false;
 const volatile bool filter_by_mnt_ns = false;
+// cross_container_only traces only the signals sent to processes in another
+// mount namespace. The mount namespace filter then matches the sender or the
+// target of the signals.
+const volatile bool cross_container_only = false;
 
 // we need this to make sure the compiler doesn't remove our struct
 const struct event *unusedevent __attribute__((unused));
@@ -35,6 +39,34 @@ struct {
 	__uint(value_size, sizeof(u32));
 } mount_ns_filter SEC(".maps");
 
+static __always_inline bool filtered_out(u64 mntns_id, u64 target_mntns_id)
+{
+	if (!cross_container_only)
+		return filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id);
+
+	if (target_mntns_id == 0 || target_mntns_id == mntns_id)
+		return true;
+
+	return filter_by_mnt_ns &&
+	       !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id) &&
+	       !bpf_map_lookup_elem(&mount_ns_filter, &target_mntns_id);
+}
+
+// si_from_user is like SI_FROMUSER() in the kernel: whether the signal was
+// sent by a process, e.g. with kill(), rather than generated by the kernel,
+// e.g. SIGCHLD when a child exits or SIGSEGV on a bad memory access.
+static __always_inline bool si_from_user(struct kernel_siginfo *info)
+{
+	// SEND_SIG_NOINFO
+	if ((unsigned long)info == 0)
+		return true;
+	// SEND_SIG_PRIV
+	if ((unsigned long)info == 1)
+		return false;
+
+	return BPF_CORE_READ(info, si_code) <= 0;
+}
+
 static int probe_entry(pid_t tpid, int sig)
 {
 	struct event event = {};
@@ -45,7 +77,9 @@ static int probe_entry(pid_t tpid, int sig)
 
 	task = (struct task_struct *) bpf_get_current_task();
 	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
-	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+	// In cross container mode, the target isn't known yet: the mount
+	// namespace filter is applied when exiting the syscall.
+	if (!cross_container_only && filtered_out(mntns_id, 0))
 		return 0;
 
 	if (target_signal && sig != target_signal)
@@ -79,6 +113,9 @@ static int probe_exit(void *ctx, int ret)
 	if (failed_only && ret >= 0)
 		goto cleanup;
 
+	if (filtered_out(eventp->mntns_id, eventp->target_mntns_id))
+		goto cleanup;
+
 	eventp->ret = ret;
 	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, eventp, sizeof(*eventp));
 
@@ -132,21 +169,48 @@ int ig_sig_tgkill_x(struct trace_event_raw_sys_exit *ctx)
 	return probe_exit(ctx, ctx->ret);
 }
 
-SEC("tracepoint/signal/signal_generate")
-int ig_sig_generate(struct trace_event_raw_signal_generate *ctx)
+// signal_generate is a raw tracepoint to get the task receiving the signal:
+// TP_PROTO(int sig, struct kernel_siginfo *info, struct task_struct *task,
+//          int group, int result)
+SEC("raw_tracepoint/signal_generate")
+int ig_sig_generate(struct bpf_raw_tracepoint_args *ctx)
 {
 	struct event event = {};
-	pid_t tpid = ctx->pid;
-	int ret = ctx->errno;
-	int sig = ctx->sig;
+	int sig = (int)ctx->args[0];
+	struct kernel_siginfo *info = (struct kernel_siginfo *)ctx->args[1];
+	struct task_struct *target = (struct task_struct *)ctx->args[2];
+	pid_t tpid;
+	int ret = 0;
 	__u64 pid_tgid;
-	__u32 pid;
-	u64 mntns_id;
+	__u32 pid, tid;
+	u64 mntns_id, target_mntns_id;
 	struct task_struct *task;
+	struct event *eventp;
+
+	tpid = BPF_CORE_READ(target, pid);
+	target_mntns_id = (u64) BPF_CORE_READ(target, nsproxy, mnt_ns, ns.inum);
+
+	// Complete the event of the syscall sending this signal, if any.
+	pid_tgid = bpf_get_current_pid_tgid();
+	tid = (__u32)pid_tgid;
+	eventp = bpf_map_lookup_elem(&values, &tid);
+	if (eventp)
+		eventp->target_mntns_id = target_mntns_id;
+
+	// Like the tracepoint, SEND_SIG_NOINFO (0) and SEND_SIG_PRIV (1) don't
+	// carry an errno.
+	if ((unsigned long)info > 1)
+		ret = BPF_CORE_READ(info, si_errno);
 
 	task = (struct task_struct *) bpf_get_current_task();
 	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
-	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+	if (filtered_out(mntns_id, target_mntns_id))
+		return 0;
+
+	// The signals generated by the kernel aren't sent by the current
+	// task, e.g. the SIGCHLD of a process exiting in a container is sent
+	// to the container shim on the host.
+	if (cross_container_only && !si_from_user(info))
 		return 0;
 
 	if (failed_only && ret == 0)
@@ -155,7 +219,6 @@ int ig_sig_generate(struct trace_event_raw_signal_generate *ctx)
 	if (target_signal && sig != target_signal)
 		return 0;
 
-	pid_tgid = bpf_get_current_pid_tgid();
 	pid = pid_tgid >> 32;
 	if (filtered_pid && pid != filtered_pid)
 		return 0;
@@ -163,6 +226,7 @@ int ig_sig_generate(struct trace_event_raw_signal_generate *ctx)
 	event.pid = pid;
 	event.tpid = tpid;
 	event.mntns_id = mntns_id;
+	event.target_mntns_id = target_mntns_id;
 	event.sig = sig;
 	event.ret = ret;
 	bpf_get_current_comm(event.comm, sizeof(event.comm));
@@ -8,6 +8,9 @@ struct event {
 	__u32 pid;
 	__u32 tpid;
 	__u64 mntns_id;
+	// target_mntns_id is the mount namespace of the process receiving the
+	// signal, 0 if unknown, e.g. when the target doesn't exist.
+	__u64 target_mntns_id;
 	int sig;
 	int ret;
 	__u8 comm[TASK_COMM_LEN];
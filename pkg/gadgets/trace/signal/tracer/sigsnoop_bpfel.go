@@ -14,12 +14,13 @@ import (
 )
 
 type sigsnoopEvent struct {
-	Pid     uint32
-	Tpid    uint32
-	MntnsId uint64
-	Sig     int32
-	Ret     int32
-	Comm    [16]uint8
+	Pid           uint32
+	Tpid          uint32
+	MntnsId       uint64
+	TargetMntnsId uint64
+	Sig           int32
+	Ret           int32
+	Comm          [16]uint8
 }
 
 // loadSigsnoop returns the embedded CollectionSpec for sigsnoop.
@@ -148,5 +149,6 @@ func _SigsnoopClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed sigsnoop_bpfel.o
 var _SigsnoopBytes []byte
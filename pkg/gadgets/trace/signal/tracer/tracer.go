@@ -18,6 +18,7 @@ import (
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/link"
 	"github.com/cilium/ebpf/perf"
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/signal/types"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
@@ -32,6 +33,10 @@ type Config struct {
 	TargetSignal string
 	TargetPid    int32
 	FailedOnly   bool
+	// CrossContainerOnly traces only the signals sent to processes in
+	// another mount namespace. MountnsMap then matches the sender or the
+	// target of the signals.
+	CrossContainerOnly bool
 }
 
 type Tracer struct {
@@ -49,6 +54,9 @@ type Tracer struct {
 
 	enricher      gadgets.DataEnricher
 	eventCallback func(types.Event)
+
+	// The mount namespace of the host, to recognize it as target
+	hostMntns uint64
 }
 
 func signalStringToInt(signal string) (int32, error) {
@@ -115,6 +123,15 @@ func (t *Tracer) Stop() {
 }
 
 func (t *Tracer) start() error {
+	var err error
+
+	// The tracer runs in the host PID namespace: the mount namespace of the
+	// host is the one of its init process.
+	t.hostMntns, err = containerutils.GetMntNs(1)
+	if err != nil {
+		return fmt.Errorf("error getting host mount namespace: %w", err)
+	}
+
 	spec, err := loadSigsnoop()
 	if err != nil {
 		return fmt.Errorf("failed to load ebpf program: %w", err)
@@ -134,10 +151,11 @@ func (t *Tracer) start() error {
 	}
 
 	consts := map[string]interface{}{
-		"filter_by_mnt_ns": filterByMntNs,
-		"filtered_pid":     t.config.TargetPid,
-		"target_signal":    signal,
-		"failed_only":      t.config.FailedOnly,
+		"filter_by_mnt_ns":     filterByMntNs,
+		"filtered_pid":         t.config.TargetPid,
+		"target_signal":        signal,
+		"failed_only":          t.config.FailedOnly,
+		"cross_container_only": t.config.CrossContainerOnly,
 	}
 
 	if err := spec.RewriteConstants(consts); err != nil {
@@ -182,9 +200,12 @@ func (t *Tracer) start() error {
 		return fmt.Errorf("error opening tracepoint: %w", err)
 	}
 
-	t.signalGenerateLink, err = link.Tracepoint("signal", "signal_generate", t.objs.IgSigGenerate, nil)
+	t.signalGenerateLink, err = link.AttachRawTracepoint(link.RawTracepointOptions{
+		Name:    "signal_generate",
+		Program: t.objs.IgSigGenerate,
+	})
 	if err != nil {
-		return fmt.Errorf("error opening tracepoint: %w", err)
+		return fmt.Errorf("error opening raw tracepoint: %w", err)
 	}
 
 	t.reader, err = perf.NewReader(t.objs.sigsnoopMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
@@ -197,6 +218,26 @@ func (t *Tracer) start() error {
 	return nil
 }
 
+// enrichTarget sets the container of the target of the signal, or TargetHost
+// for the processes of the host. It's left empty when the target is in an
+// unknown container.
+func (t *Tracer) enrichTarget(event *types.Event) {
+	if event.TargetMountNsID == 0 {
+		return
+	}
+
+	var data eventtypes.CommonData
+	t.enricher.Enrich(&data, event.TargetMountNsID)
+	if data.Container == "" && event.TargetMountNsID == t.hostMntns {
+		event.TargetContainer = types.TargetHost
+		return
+	}
+
+	event.TargetNamespace = data.Namespace
+	event.TargetPod = data.Pod
+	event.TargetContainer = data.Container
+}
+
 func (t *Tracer) run() {
 	for {
 		record, err := t.reader.Read()
@@ -223,16 +264,18 @@ func (t *Tracer) run() {
 			Event: eventtypes.Event{
 				Type: eventtypes.NORMAL,
 			},
-			Pid:       bpfEvent.Pid,
-			TargetPid: bpfEvent.Tpid,
-			Signal:    signalIntToString(int(bpfEvent.Sig)),
-			Retval:    int(bpfEvent.Ret),
-			MountNsID: bpfEvent.MntnsId,
-			Comm:      gadgets.FromCString(bpfEvent.Comm[:]),
+			Pid:             bpfEvent.Pid,
+			TargetPid:       bpfEvent.Tpid,
+			Signal:          signalIntToString(int(bpfEvent.Sig)),
+			Retval:          int(bpfEvent.Ret),
+			MountNsID:       bpfEvent.MntnsId,
+			Comm:            gadgets.FromCString(bpfEvent.Comm[:]),
+			TargetMountNsID: bpfEvent.TargetMntnsId,
 		}
 
 		if t.enricher != nil {
 			t.enricher.Enrich(&event.CommonData, event.MountNsID)
+			t.enrichTarget(&event)
 		}
 
 		t.eventCallback(event)
@@ -0,0 +1,76 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/signal/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type fakeEnricher map[uint64]string
+
+func (f fakeEnricher) Enrich(event *eventtypes.CommonData, mountnsid uint64) {
+	if container, ok := f[mountnsid]; ok {
+		event.Namespace = "default"
+		event.Pod = container
+		event.Container = container
+	}
+}
+
+func TestEnrichTarget(t *testing.T) {
+	const hostMntns = 4026531841
+
+	tracer := &Tracer{
+		enricher: fakeEnricher{
+			4026532001: "known",
+		},
+		hostMntns: hostMntns,
+	}
+
+	for _, entry := range []struct {
+		description string
+		mntns       uint64
+		expected    types.Event
+	}{
+		{
+			description: "known container",
+			mntns:       4026532001,
+			expected: types.Event{
+				TargetNamespace: "default",
+				TargetPod:       "known",
+				TargetContainer: "known",
+				TargetMountNsID: 4026532001,
+			},
+		},
+		{
+			description: "host",
+			mntns:       hostMntns,
+			expected: types.Event{
+				TargetContainer: types.TargetHost,
+				TargetMountNsID: hostMntns,
+			},
+		},
+		{
+			description: "unknown container",
+			mntns:       4026532002,
+			expected: types.Event{
+				TargetMountNsID: 4026532002,
+			},
+		},
+		{
+			description: "unknown mount namespace",
+		},
+	} {
+		event := types.Event{TargetMountNsID: entry.mntns}
+		tracer.enrichTarget(&event)
+		if event != entry.expected {
+			t.Fatalf("Failed test %q: got %+v, expected %+v", entry.description, event, entry.expected)
+		}
+	}
+}
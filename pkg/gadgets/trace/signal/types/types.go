@@ -19,6 +19,10 @@ import (
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
 
+// TargetHost is the container of the targets of the signals that are
+// processes of the host.
+const TargetHost = "host"
+
 type Event struct {
 	eventtypes.Event
 
@@ -30,6 +34,13 @@ type Event struct {
 	TargetPid uint32 `json:"tpid,omitempty" column:"tpid,template:pid"`
 	Retval    int    `json:"ret,omitempty" column:"ret,width:3,fixed"`
 	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+
+	// The container of the target of the signal, TargetHost for the
+	// processes of the host, or empty if it's in an unknown container.
+	TargetNamespace string `json:"tnamespace,omitempty" column:"tnamespace,template:namespace" columnTags:"kubernetes"`
+	TargetPod       string `json:"tpod,omitempty" column:"tpod,template:pod" columnTags:"kubernetes"`
+	TargetContainer string `json:"tcontainer,omitempty" column:"tcontainer,template:container" columnTags:"kubernetes,runtime"`
+	TargetMountNsID uint64 `json:"tmountnsid,omitempty" column:"tmntns,template:ns"`
 }
 
 func GetColumns() *columns.Columns[Event] {
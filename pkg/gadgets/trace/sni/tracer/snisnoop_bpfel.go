@@ -13,7 +13,13 @@ import (
 	"github.com/cilium/ebpf"
 )
 
-type snisnoopEventT struct{ Name [128]uint8 }
+type snisnoopEventT struct {
+	SaddrV6 [16]uint8
+	DaddrV6 [16]uint8
+	Af      uint32
+	Sport   uint16
+	Dport   uint16
+}
 
 // loadSnisnoop returns the embedded CollectionSpec for snisnoop.
 func loadSnisnoop() (*ebpf.CollectionSpec, error) {
@@ -117,5 +123,6 @@ func _SnisnoopClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed snisnoop_bpfel.o
 var _SnisnoopBytes []byte
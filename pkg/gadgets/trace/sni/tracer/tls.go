@@ -0,0 +1,211 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	tlsRecordHeaderLen    = 5
+	tlsHandshakeHeaderLen = 4
+
+	tlsContentTypeHandshake = 0x16
+
+	tlsHandshakeTypeClientHello = 1
+	tlsHandshakeTypeServerHello = 2
+
+	tlsExtensionServerName        = 0
+	tlsExtensionALPN              = 16
+	tlsExtensionSupportedVersions = 43
+
+	tlsServerNameTypeHostName = 0
+)
+
+var tlsVersionNames = map[uint16]string{
+	0x0300: "SSL3.0",
+	0x0301: "TLS1.0",
+	0x0302: "TLS1.1",
+	0x0303: "TLS1.2",
+	0x0304: "TLS1.3",
+}
+
+func tlsVersionName(version uint16) string {
+	if name, ok := tlsVersionNames[version]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
+
+// isGREASE returns true for the values reserved to check that peers ignore
+// unknown values.
+// https://datatracker.ietf.org/doc/html/rfc8701
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// hello contains the fields of a ClientHello or ServerHello message.
+type hello struct {
+	client bool
+
+	// ClientHello fields
+	serverName string
+	alpn       []string
+
+	// versions are the versions offered by the client or the one selected
+	// by the server.
+	versions []uint16
+
+	// ServerHello fields
+	cipher uint16
+}
+
+// cursor reads a message. Reads beyond its end fail, and the following
+// ones too.
+type cursor struct {
+	data []byte
+	ok   bool
+}
+
+func (c *cursor) bytes(n int) []byte {
+	if !c.ok || n > len(c.data) {
+		c.ok = false
+		return nil
+	}
+	b := c.data[:n]
+	c.data = c.data[n:]
+	return b
+}
+
+func (c *cursor) u8() int {
+	b := c.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return int(b[0])
+}
+
+func (c *cursor) u16() int {
+	b := c.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(b))
+}
+
+// vector reads a field prefixed with its length on lenSize bytes.
+func (c *cursor) vector(lenSize int) *cursor {
+	n := 0
+	switch lenSize {
+	case 1:
+		n = c.u8()
+	case 2:
+		n = c.u16()
+	}
+	return &cursor{data: c.bytes(n), ok: c.ok}
+}
+
+// parseHello parses the ClientHello or ServerHello message starting the TLS
+// payload of a segment. Messages truncated in their extensions are returned
+// with the extensions read so far.
+// https://datatracker.ietf.org/doc/html/rfc8446#section-4.1.2
+func parseHello(payload []byte) (hello, bool) {
+	var h hello
+
+	if len(payload) < tlsRecordHeaderLen+tlsHandshakeHeaderLen ||
+		payload[0] != tlsContentTypeHandshake {
+		return hello{}, false
+	}
+
+	c := &cursor{data: payload[tlsRecordHeaderLen+tlsHandshakeHeaderLen:], ok: true}
+	switch payload[tlsRecordHeaderLen] {
+	case tlsHandshakeTypeClientHello:
+		h.client = true
+	case tlsHandshakeTypeServerHello:
+	default:
+		return hello{}, false
+	}
+
+	legacyVersion := uint16(c.u16())
+	c.bytes(32) // random
+	c.vector(1) // session id
+	if h.client {
+		c.vector(2) // cipher suites
+		c.vector(1) // compression methods
+	} else {
+		h.cipher = uint16(c.u16())
+		c.u8() // compression method
+	}
+	if !c.ok {
+		return hello{}, false
+	}
+
+	// Extensions
+	exts := c.vector(2)
+	if !c.ok {
+		// The extensions are truncated or absent, read what was
+		// captured after their length
+		exts = &cursor{data: c.data, ok: true}
+	}
+	for exts.ok && len(exts.data) > 0 {
+		extType := exts.u16()
+		ext := exts.vector(2)
+		if !ext.ok {
+			break
+		}
+
+		switch extType {
+		case tlsExtensionServerName:
+			names := ext.vector(2)
+			for names.ok && len(names.data) > 0 {
+				nameType := names.u8()
+				name := names.vector(2)
+				if name.ok && nameType == tlsServerNameTypeHostName {
+					h.serverName = string(name.data)
+					break
+				}
+			}
+		case tlsExtensionALPN:
+			protocols := ext.vector(2)
+			for protocols.ok && len(protocols.data) > 0 {
+				protocol := protocols.vector(1)
+				if protocol.ok {
+					h.alpn = append(h.alpn, string(protocol.data))
+				}
+			}
+		case tlsExtensionSupportedVersions:
+			if !h.client {
+				h.versions = []uint16{uint16(ext.u16())}
+				break
+			}
+			versions := ext.vector(1)
+			for versions.ok && len(versions.data) >= 2 {
+				v := uint16(versions.u16())
+				if !isGREASE(v) {
+					h.versions = append(h.versions, v)
+				}
+			}
+		}
+	}
+
+	// The supported_versions extension supersedes the legacy version
+	// field since TLS 1.3.
+	if len(h.versions) == 0 {
+		h.versions = []uint16{legacyVersion}
+	}
+
+	return h, true
+}
@@ -15,7 +15,13 @@
 package tracer
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
 
 	"github.com/lato333/inspektor-gadget/pkg/gadgets"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/internal/networktracer"
@@ -26,50 +32,259 @@ import (
 //go:generate bash -c "source ./clangosflags.sh; go run github.com/cilium/ebpf/cmd/bpf2go -target bpfel -cc clang -type event_t snisnoop ./bpf/snisnoop.c -- $CLANG_OS_FLAGS -I./bpf/"
 
 const (
-	BPFProgName         = "ig_trace_sni"
-	BPFPerfMapName      = "events"
-	BPFSocketAttach     = 50
-	TLSMaxServerNameLen = len(snisnoopEventT{}.Name)
+	BPFProgName     = "ig_trace_sni"
+	BPFPerfMapName  = "events"
+	BPFSocketAttach = 50
 )
 
+// At most maxPendingHandshakes ClientHello messages are kept waiting for
+// their ServerHello per network namespace, for up to handshakeTimeout.
+const (
+	maxPendingHandshakes = 4096
+	handshakeTimeout     = 10 * time.Second
+)
+
+type Config struct {
+	// Negotiated reports one event per handshake when the server answers,
+	// with the version and the cipher it selected, instead of one event per
+	// ClientHello. Handshakes the server doesn't answer are reported without
+	// them after handshakeTimeout.
+	Negotiated bool
+
+	// WithoutSNI also reports the ClientHello messages without a server
+	// name.
+	WithoutSNI bool
+}
+
+// connKey identifies a TLS connection.
+type connKey struct {
+	clientAddr [16]byte
+	clientPort uint16
+	serverAddr [16]byte
+	serverPort uint16
+}
+
 type Tracer struct {
 	*networktracer.Tracer[types.Event]
+
+	config *Config
+
+	mu sync.Mutex
+	// handshakes keeps the ClientHello events waiting for their ServerHello
+	// by network namespace. The events of a network namespace are only
+	// returned when parsing its samples, so that they are emitted with the
+	// callback of its containers.
+	handshakes map[uint64]*netnsHandshakes
+}
+
+type netnsHandshakes struct {
+	pending map[connKey]*pendingHandshake
+	// lastExpire is the last time pending was checked for the handshakes
+	// not answered in time
+	lastExpire time.Time
+}
+
+type pendingHandshake struct {
+	event *types.Event
+	start time.Time
 }
 
-func NewTracer() (*Tracer, error) {
+func NewTracer(config *Config) (*Tracer, error) {
 	spec, err := loadSnisnoop()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load asset: %w", err)
 	}
 
-	return &Tracer{
-		Tracer: networktracer.NewTracer(
-			spec,
-			BPFProgName,
-			BPFPerfMapName,
-			BPFSocketAttach,
-			types.Base,
-			parseSNIEvent,
-		),
-	}, nil
+	if err := spec.RewriteConstants(map[string]interface{}{
+		"server_hello": config.Negotiated,
+	}); err != nil {
+		return nil, fmt.Errorf("error RewriteConstants: %w", err)
+	}
+
+	t := &Tracer{
+		config:     config,
+		handshakes: make(map[uint64]*netnsHandshakes),
+	}
+	t.Tracer = networktracer.NewTracer(
+		spec,
+		BPFProgName,
+		BPFPerfMapName,
+		BPFSocketAttach,
+		types.Base,
+		t.parseSNIEvent,
+	)
+
+	return t, nil
 }
 
-func parseSNIEvent(sample []byte) (*types.Event, error) {
-	if len(sample) > TLSMaxServerNameLen {
-		sample = sample[:TLSMaxServerNameLen]
+// addHandshake keeps the ClientHello event until the ServerHello is received.
+// A nil event marks a skipped handshake. If too many handshakes are pending in
+// the network namespace, it returns them: their ServerHello was likely missed.
+func (t *Tracer) addHandshake(netns uint64, key connKey, event *types.Event, now time.Time) []*types.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n, ok := t.handshakes[netns]
+	if !ok {
+		n = &netnsHandshakes{
+			pending:    make(map[connKey]*pendingHandshake),
+			lastExpire: now,
+		}
+		t.handshakes[netns] = n
 	}
 
-	name := gadgets.FromCString(sample)
-	if len(name) == 0 {
+	var events []*types.Event
+	if len(n.pending) >= maxPendingHandshakes {
+		for _, h := range n.pending {
+			if h.event != nil {
+				events = append(events, h.event)
+			}
+		}
+		n.pending = make(map[connKey]*pendingHandshake)
+	}
+	n.pending[key] = &pendingHandshake{event: event, start: now}
+	return events
+}
+
+func (t *Tracer) popHandshake(netns uint64, key connKey) (*types.Event, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n, ok := t.handshakes[netns]
+	if !ok {
+		return nil, false
+	}
+	h, ok := n.pending[key]
+	if !ok {
+		return nil, false
+	}
+	delete(n.pending, key)
+	if len(n.pending) == 0 {
+		delete(t.handshakes, netns)
+	}
+	return h.event, true
+}
+
+// expireHandshakes returns the events of the handshakes of the network
+// namespace not answered within handshakeTimeout. As they are only checked
+// when packets are received in the network namespace, they can be reported
+// later than that.
+func (t *Tracer) expireHandshakes(netns uint64, now time.Time) []*types.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n, ok := t.handshakes[netns]
+	if !ok || now.Sub(n.lastExpire) < time.Second {
+		return nil
+	}
+	n.lastExpire = now
+
+	var events []*types.Event
+	for key, h := range n.pending {
+		if now.Sub(h.start) >= handshakeTimeout {
+			if h.event != nil {
+				events = append(events, h.event)
+			}
+			delete(n.pending, key)
+		}
+	}
+	if len(n.pending) == 0 {
+		delete(t.handshakes, netns)
+	}
+	return events
+}
+
+func (t *Tracer) parseSNIEvent(netns uint64, rawSample []byte) ([]*types.Event, error) {
+	bpfEvent := (*snisnoopEventT)(unsafe.Pointer(&rawSample[0]))
+	if len(rawSample) < int(unsafe.Sizeof(*bpfEvent)) {
+		return nil, errors.New("invalid sample size")
+	}
+
+	seg, ok := networktracer.ParseTCPSegment(rawSample[unsafe.Sizeof(*bpfEvent):])
+	if !ok {
+		return nil, nil
+	}
+	h, ok := parseHello(seg.Payload)
+	if !ok {
 		return nil, nil
 	}
+	// ClientHello messages without a server name are skipped unless
+	// requested. Their ServerHello is then skipped too.
+	skip := h.client && h.serverName == "" && !t.config.WithoutSNI
+	if skip && !t.config.Negotiated {
+		return nil, nil
+	}
+
+	var events []*types.Event
+	now := time.Now()
+	if t.config.Negotiated {
+		events = t.expireHandshakes(netns, now)
+	}
+
+	ipVersion := 0
+	if bpfEvent.Af == syscall.AF_INET {
+		ipVersion = 4
+	} else if bpfEvent.Af == syscall.AF_INET6 {
+		ipVersion = 6
+	}
 
-	event := types.Event{
-		Event: eventtypes.Event{
-			Type: eventtypes.NORMAL,
-		},
-		Name: name,
+	if h.client {
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Name:  h.serverName,
+			Saddr: gadgets.IPStringFromBytes(bpfEvent.SaddrV6, ipVersion),
+			Sport: bpfEvent.Sport,
+			Daddr: gadgets.IPStringFromBytes(bpfEvent.DaddrV6, ipVersion),
+			Dport: bpfEvent.Dport,
+			ALPN:  h.alpn,
+		}
+		for _, v := range h.versions {
+			event.Versions = append(event.Versions, tlsVersionName(v))
+		}
+
+		if !t.config.Negotiated {
+			return []*types.Event{&event}, nil
+		}
+
+		key := connKey{
+			clientAddr: bpfEvent.SaddrV6,
+			clientPort: bpfEvent.Sport,
+			serverAddr: bpfEvent.DaddrV6,
+			serverPort: bpfEvent.Dport,
+		}
+		if skip {
+			return append(events, t.addHandshake(netns, key, nil, now)...), nil
+		}
+		return append(events, t.addHandshake(netns, key, &event, now)...), nil
+	}
+
+	key := connKey{
+		clientAddr: bpfEvent.DaddrV6,
+		clientPort: bpfEvent.Dport,
+		serverAddr: bpfEvent.SaddrV6,
+		serverPort: bpfEvent.Sport,
+	}
+	event, ok := t.popHandshake(netns, key)
+	if ok && event == nil {
+		return events, nil
+	}
+	if !ok {
+		// The ClientHello was sent before the tracer started or wasn't
+		// captured
+		event = &types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Saddr: gadgets.IPStringFromBytes(bpfEvent.DaddrV6, ipVersion),
+			Sport: bpfEvent.Dport,
+			Daddr: gadgets.IPStringFromBytes(bpfEvent.SaddrV6, ipVersion),
+			Dport: bpfEvent.Sport,
+		}
 	}
+	event.Version = tlsVersionName(h.versions[0])
+	event.Cipher = tls.CipherSuiteName(h.cipher)
 
-	return &event, nil
+	return append(events, event), nil
 }
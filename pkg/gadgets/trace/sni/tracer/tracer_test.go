@@ -0,0 +1,290 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"encoding/binary"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/sni/types"
+)
+
+func cat(parts ...[]byte) []byte {
+	var ret []byte
+	for _, p := range parts {
+		ret = append(ret, p...)
+	}
+	return ret
+}
+
+// vec prefixes data with its length on lenSize bytes.
+func vec(lenSize int, data ...[]byte) []byte {
+	body := cat(data...)
+	if lenSize == 1 {
+		return cat([]byte{byte(len(body))}, body)
+	}
+	return cat([]byte{byte(len(body) >> 8), byte(len(body))}, body)
+}
+
+func ext(extType uint16, data []byte) []byte {
+	return cat([]byte{byte(extType >> 8), byte(extType)}, vec(2, data))
+}
+
+func handshake(msgType byte, body []byte) []byte {
+	msg := cat([]byte{msgType, 0, byte(len(body) >> 8), byte(len(body))}, body)
+	return cat([]byte{0x16, 0x03, 0x01, byte(len(msg) >> 8), byte(len(msg))}, msg)
+}
+
+func TestParseHello(t *testing.T) {
+	random := make([]byte, 32)
+
+	clientHello := handshake(tlsHandshakeTypeClientHello, cat(
+		[]byte{0x03, 0x03},
+		random,
+		vec(1, []byte{1, 2, 3}),                // session id
+		vec(2, []byte{0x13, 0x01, 0xc0, 0x2f}), // cipher suites
+		vec(1, []byte{0}),                      // compression methods
+		vec(2,
+			ext(0x0a0a, nil), // GREASE
+			ext(tlsExtensionServerName, vec(2, []byte{0}, vec(2, []byte("example.com")))),
+			ext(tlsExtensionALPN, vec(2, vec(1, []byte("h2")), vec(1, []byte("http/1.1")))),
+			ext(tlsExtensionSupportedVersions, vec(1, []byte{0x7a, 0x7a, 0x03, 0x04, 0x03, 0x03})),
+		),
+	))
+
+	serverHello := handshake(tlsHandshakeTypeServerHello, cat(
+		[]byte{0x03, 0x03},
+		random,
+		vec(1),                // session id
+		[]byte{0x13, 0x01, 0}, // cipher suite and compression method
+		vec(2, ext(tlsExtensionSupportedVersions, []byte{0x03, 0x04})),
+	))
+
+	legacyServerHello := handshake(tlsHandshakeTypeServerHello, cat(
+		[]byte{0x03, 0x01},
+		random,
+		vec(1),
+		[]byte{0xc0, 0x14, 0},
+	))
+
+	table := []struct {
+		description string
+		input       []byte
+		output      hello
+		ok          bool
+	}{
+		{
+			description: "ClientHello",
+			input:       clientHello,
+			output: hello{
+				client:     true,
+				serverName: "example.com",
+				alpn:       []string{"h2", "http/1.1"},
+				versions:   []uint16{0x0304, 0x0303},
+			},
+			ok: true,
+		},
+		{
+			description: "truncated ClientHello",
+			input:       clientHello[:len(clientHello)-11],
+			output: hello{
+				client:     true,
+				serverName: "example.com",
+				alpn:       []string{"h2", "http/1.1"},
+				versions:   []uint16{0x0303},
+			},
+			ok: true,
+		},
+		{
+			description: "ServerHello",
+			input:       serverHello,
+			output:      hello{cipher: 0x1301, versions: []uint16{0x0304}},
+			ok:          true,
+		},
+		{
+			description: "ServerHello without extensions",
+			input:       legacyServerHello,
+			output:      hello{cipher: 0xc014, versions: []uint16{0x0301}},
+			ok:          true,
+		},
+		{
+			description: "application data",
+			input:       []byte{0x17, 0x03, 0x03, 0x00, 0x10, 0, 0, 0, 0},
+			ok:          false,
+		},
+		{
+			description: "truncated before the extensions",
+			input:       clientHello[:20],
+			ok:          false,
+		},
+	}
+
+	for _, entry := range table {
+		output, ok := parseHello(entry.input)
+		if ok != entry.ok || !reflect.DeepEqual(output, entry.output) {
+			t.Fatalf("Failed test %q: got %+v (%t), expected %+v (%t)",
+				entry.description, output, ok, entry.output, entry.ok)
+		}
+	}
+}
+
+func TestTLSNames(t *testing.T) {
+	if name := tlsVersionName(0x0301); name != "TLS1.0" {
+		t.Fatalf("Got %q, expected TLS1.0", name)
+	}
+	if name := tlsVersionName(0x7f1c); name != "0x7f1c" {
+		t.Fatalf("Got %q, expected 0x7f1c", name)
+	}
+	if !isGREASE(0xfafa) || isGREASE(0x0303) {
+		t.Fatalf("Wrong GREASE detection")
+	}
+}
+
+func TestPendingHandshakes(t *testing.T) {
+	tracer := &Tracer{
+		handshakes: make(map[uint64]*netnsHandshakes),
+	}
+
+	const netns1, netns2 = 4026531840, 4026532000
+
+	start := time.Unix(1000, 0)
+	answered := connKey{clientPort: 40000, serverPort: 443}
+	unanswered := connKey{clientPort: 40001, serverPort: 443}
+	skipped := connKey{clientPort: 40002, serverPort: 443}
+
+	tracer.addHandshake(netns1, answered, &types.Event{Name: "answered"}, start)
+	tracer.addHandshake(netns1, unanswered, &types.Event{Name: "unanswered"}, start)
+	tracer.addHandshake(netns1, skipped, nil, start)
+	// The same connection in another network namespace
+	tracer.addHandshake(netns2, unanswered, &types.Event{Name: "other"}, start.Add(5*time.Second))
+
+	if event, ok := tracer.popHandshake(netns1, answered); !ok || event.Name != "answered" {
+		t.Fatalf("Got unexpected handshake %+v", event)
+	}
+	if event, ok := tracer.popHandshake(netns1, skipped); !ok || event != nil {
+		t.Fatalf("Skipped handshake not found")
+	}
+	if _, ok := tracer.popHandshake(netns1, answered); ok {
+		t.Fatalf("Handshake found twice")
+	}
+	if _, ok := tracer.popHandshake(netns2, answered); ok {
+		t.Fatalf("Handshake found in another network namespace")
+	}
+
+	if events := tracer.expireHandshakes(netns1, start.Add(time.Second)); len(events) != 0 {
+		t.Fatalf("Got %d handshakes expired too early", len(events))
+	}
+	// Only the handshakes of the network namespace are expired
+	events := tracer.expireHandshakes(netns2, start.Add(handshakeTimeout))
+	if len(events) != 0 {
+		t.Fatalf("Got unexpected expired handshakes %+v", events)
+	}
+	events = tracer.expireHandshakes(netns1, start.Add(handshakeTimeout))
+	if len(events) != 1 || events[0].Name != "unanswered" {
+		t.Fatalf("Got unexpected expired handshakes %+v", events)
+	}
+	if _, ok := tracer.handshakes[netns1]; ok {
+		t.Fatalf("Expired handshakes still pending")
+	}
+	if event, ok := tracer.popHandshake(netns2, unanswered); !ok || event.Name != "other" {
+		t.Fatalf("Got unexpected handshake %+v", event)
+	}
+	if len(tracer.handshakes) != 0 {
+		t.Fatalf("Answered handshakes still pending")
+	}
+}
+
+// sample builds a sample like the ones sent by the eBPF program, with an IPv4
+// packet carrying the payload.
+func sample(saddr, daddr [4]byte, sport, dport uint16, payload []byte) []byte {
+	bpfEvent := snisnoopEventT{
+		Af:    syscall.AF_INET,
+		Sport: sport,
+		Dport: dport,
+	}
+	copy(bpfEvent.SaddrV6[:], saddr[:])
+	copy(bpfEvent.DaddrV6[:], daddr[:])
+	header := (*[unsafe.Sizeof(bpfEvent)]byte)(unsafe.Pointer(&bpfEvent))[:]
+
+	packet := make([]byte, 14+20+20)
+	packet[14] = 0x45 // IPv4, IHL 5
+	binary.BigEndian.PutUint16(packet[14+2:], uint16(20+20+len(payload)))
+	packet[14+20+12] = 5 << 4 // TCP data offset
+
+	return cat(header, packet, payload)
+}
+
+func TestNegotiatedHandshakesByNetns(t *testing.T) {
+	tracer := &Tracer{
+		config:     &Config{Negotiated: true},
+		handshakes: make(map[uint64]*netnsHandshakes),
+	}
+
+	random := make([]byte, 32)
+	clientHello := func(name string) []byte {
+		return handshake(tlsHandshakeTypeClientHello, cat(
+			[]byte{0x03, 0x03},
+			random,
+			vec(1),
+			vec(2, []byte{0x13, 0x01}),
+			vec(1, []byte{0}),
+			vec(2, ext(tlsExtensionServerName, vec(2, []byte{0}, vec(2, []byte(name))))),
+		))
+	}
+	serverHello := handshake(tlsHandshakeTypeServerHello, cat(
+		[]byte{0x03, 0x03},
+		random,
+		vec(1),
+		[]byte{0x13, 0x01, 0},
+	))
+
+	// Two network namespaces with the same addresses and ports
+	const netns1, netns2 = 4026531840, 4026532000
+	client := [4]byte{10, 0, 0, 2}
+	server := [4]byte{10, 0, 0, 1}
+
+	for _, entry := range []struct {
+		netns uint64
+		name  string
+	}{
+		{netns1, "one.example.com"},
+		{netns2, "two.example.com"},
+	} {
+		events, err := tracer.parseSNIEvent(entry.netns, sample(client, server, 40000, 443, clientHello(entry.name)))
+		if err != nil || len(events) != 0 {
+			t.Fatalf("Got %+v (%v) for the ClientHello of %s", events, err, entry.name)
+		}
+	}
+
+	for _, entry := range []struct {
+		netns uint64
+		name  string
+	}{
+		{netns2, "two.example.com"},
+		{netns1, "one.example.com"},
+	} {
+		events, err := tracer.parseSNIEvent(entry.netns, sample(server, client, 443, 40000, serverHello))
+		if err != nil {
+			t.Fatalf("Failed to parse the ServerHello: %s", err)
+		}
+		if len(events) != 1 || events[0].Name != entry.name || events[0].Cipher != "TLS_AES_128_GCM_SHA256" {
+			t.Fatalf("Got %+v, expected the handshake of %s", events, entry.name)
+		}
+	}
+}
@@ -15,6 +15,8 @@
 package types
 
 import (
+	"strings"
+
 	"github.com/lato333/inspektor-gadget/pkg/columns"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
@@ -23,6 +25,22 @@ type Event struct {
 	eventtypes.Event
 
 	Name string `json:"name,omitempty" column:"name,width:30"`
+
+	// Saddr and Sport are the client, Daddr and Dport the server
+	Saddr string `json:"saddr,omitempty" column:"saddr,template:ipaddr,hide"`
+	Sport uint16 `json:"sport,omitempty" column:"sport,template:ipport,hide"`
+	Daddr string `json:"daddr,omitempty" column:"daddr,template:ipaddr"`
+	Dport uint16 `json:"dport,omitempty" column:"dport,template:ipport"`
+
+	// ALPN and Versions are the application protocols and the TLS versions
+	// offered by the client.
+	ALPN     []string `json:"alpn,omitempty" column:"alpn,width:12"`
+	Versions []string `json:"versions,omitempty" column:"versions,width:16"`
+
+	// Version and Cipher are the ones selected by the server. They are
+	// only set when tracing negotiated handshakes.
+	Version string `json:"version,omitempty" column:"version,width:7,hide"`
+	Cipher  string `json:"cipher,omitempty" column:"cipher,width:32,hide"`
 }
 
 func GetColumns() *columns.Columns[Event] {
@@ -31,6 +49,13 @@ func GetColumns() *columns.Columns[Event] {
 	col, _ := cols.GetColumn("container")
 	col.Visible = false
 
+	cols.MustSetExtractor("alpn", func(event *Event) string {
+		return strings.Join(event.ALPN, ",")
+	})
+	cols.MustSetExtractor("versions", func(event *Event) string {
+		return strings.Join(event.Versions, ",")
+	})
+
 	return cols
 }
 
@@ -14,6 +14,10 @@
 const volatile uid_t filter_uid = -1;
 const volatile pid_t filter_pid = 0;
 const volatile bool filter_by_mnt_ns = false;
+// lifetime reports one event per connection when it's closed, with its
+// duration and the bytes transferred, instead of the connect, accept and
+// close events.
+const volatile bool lifetime = false;
 
 /* Define here, because there are conflicts with include files */
 #define AF_INET		2
@@ -63,6 +67,25 @@ struct {
 	__type(value, struct sock *);
 } sockets SEC(".maps");
 
+// birth keeps the time the connections traced in lifetime mode started:
+// when connecting for the outgoing ones and when accepted for the incoming
+// ones, as the process accepting them isn't known before.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, struct sock *);
+	__type(value, u64);
+} birth SEC(".maps");
+
+// idents keeps the processes that connected or accepted the connections
+// traced in lifetime mode
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, struct sock *);
+	__type(value, struct pid_comm_t);
+} idents SEC(".maps");
+
 struct {
 	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
 	__uint(key_size, sizeof(u32));
@@ -219,7 +242,10 @@ exit_tcp_connect(struct pt_regs *ctx, int ret, __u16 family)
 	pid_comm.mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
 	bpf_get_current_comm(&pid_comm.comm, sizeof(pid_comm.comm));
 
-	bpf_map_update_elem(&tuplepid, &tuple, &pid_comm, 0);
+	if (lifetime)
+		bpf_map_update_elem(&idents, &sk, &pid_comm, 0);
+	else
+		bpf_map_update_elem(&tuplepid, &tuple, &pid_comm, 0);
 
 end:
 	bpf_map_delete_elem(&sockets, &tid);
@@ -358,6 +384,20 @@ int BPF_KRETPROBE(ig_tcp_accept, struct sock *sk)
 	if (t.saddr_v6 == 0 || t.daddr_v6 == 0 || t.dport == 0 || t.sport == 0)
 		return 0;
 
+	if (lifetime) {
+		struct pid_comm_t pid_comm = {};
+		__u64 ts = bpf_ktime_get_ns();
+
+		pid_comm.pid = pid;
+		pid_comm.uid = uid;
+		pid_comm.mntns_id = mntns_id;
+		bpf_get_current_comm(&pid_comm.comm, sizeof(pid_comm.comm));
+
+		bpf_map_update_elem(&birth, &sk, &ts, BPF_NOEXIST);
+		bpf_map_update_elem(&idents, &sk, &pid_comm, 0);
+		return 0;
+	}
+
 	fill_event(&t, &event, pid, uid, family, TCP_EVENT_TYPE_ACCEPT, mntns_id);
 
 	bpf_get_current_comm(&event.task, sizeof(event.task));
@@ -368,5 +408,66 @@ int BPF_KRETPROBE(ig_tcp_accept, struct sock *sk)
 	return 0;
 }
 
+SEC("tracepoint/sock/inet_sock_set_state")
+int ig_tcp_life(struct trace_event_raw_inet_sock_set_state *ctx)
+{
+	struct sock *sk = (struct sock *)ctx->skaddr;
+	struct tcp_sock *tp = (struct tcp_sock *)sk;
+	struct tuple_key_t tuple = {};
+	struct event event = {};
+	struct pid_comm_t *p;
+	struct task_struct *task;
+	__u64 ts, *start;
+	u64 mntns_id;
+
+	if (ctx->protocol != IPPROTO_TCP)
+		return 0;
+
+	// The outgoing connections start with the SYN_SENT state, that is set
+	// in the context of the process connecting. The incoming ones are
+	// added to birth when accepted.
+	if (ctx->newstate == TCP_SYN_SENT) {
+		task = (struct task_struct*)bpf_get_current_task();
+		mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
+		if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
+			return 0;
+
+		ts = bpf_ktime_get_ns();
+		bpf_map_update_elem(&birth, &sk, &ts, BPF_NOEXIST);
+		return 0;
+	}
+
+	if (ctx->newstate != TCP_CLOSE)
+		return 0;
+
+	// Don't report the connections that were never established
+	if (ctx->oldstate == TCP_SYN_SENT || ctx->oldstate == TCP_SYN_RECV)
+		goto cleanup;
+
+	// Skip the connections started before the gadget, and the ones of the
+	// processes not traced.
+	start = bpf_map_lookup_elem(&birth, &sk);
+	p = bpf_map_lookup_elem(&idents, &sk);
+	if (!start || !p)
+		goto cleanup;
+
+	if (!fill_tuple(&tuple, sk, ctx->family))
+		goto cleanup;
+
+	fill_event(&tuple, &event, p->pid, p->uid, ctx->family, TCP_EVENT_TYPE_CLOSE, p->mntns_id);
+	__builtin_memcpy(&event.task, p->comm, sizeof(event.task));
+	event.duration = bpf_ktime_get_ns() - *start;
+	event.sent_bytes = BPF_CORE_READ(tp, bytes_acked);
+	event.received_bytes = BPF_CORE_READ(tp, bytes_received);
+
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU,
+			      &event, sizeof(event));
+
+cleanup:
+	bpf_map_delete_elem(&birth, &sk);
+	bpf_map_delete_elem(&idents, &sk);
+
+	return 0;
+}
 
 char LICENSE[] SEC("license") = "GPL";
@@ -39,6 +39,11 @@ struct event {
 	__u16 dport;
 	__u16 sport;
 	enum event_type type;
+	// duration, in nanoseconds, and bytes sent and received over the
+	// connection, only set in lifetime mode
+	__u64 duration;
+	__u64 sent_bytes;
+	__u64 received_bytes;
 };
 
 
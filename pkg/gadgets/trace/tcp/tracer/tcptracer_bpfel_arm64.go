@@ -14,19 +14,22 @@ import (
 )
 
 type tcptracerEvent struct {
-	Saddr   [16]uint8
-	Daddr   [16]uint8
-	Task    [16]uint8
-	MntnsId uint64
-	TsUs    uint64
-	Af      uint32
-	Pid     uint32
-	Uid     uint32
-	Netns   uint32
-	Dport   uint16
-	Sport   uint16
-	Type    tcptracerEventType
-	_       [11]byte
+	Saddr         [16]uint8
+	Daddr         [16]uint8
+	Task          [16]uint8
+	MntnsId       uint64
+	TsUs          uint64
+	Af            uint32
+	Pid           uint32
+	Uid           uint32
+	Netns         uint32
+	Dport         uint16
+	Sport         uint16
+	Type          tcptracerEventType
+	_             [3]byte
+	Duration      uint64
+	SentBytes     uint64
+	ReceivedBytes uint64
 }
 
 type tcptracerEventType uint8
@@ -80,6 +83,7 @@ type tcptracerSpecs struct {
 type tcptracerProgramSpecs struct {
 	IgTcpAccept *ebpf.ProgramSpec `ebpf:"ig_tcp_accept"`
 	IgTcpClose  *ebpf.ProgramSpec `ebpf:"ig_tcp_close"`
+	IgTcpLife   *ebpf.ProgramSpec `ebpf:"ig_tcp_life"`
 	IgTcpState  *ebpf.ProgramSpec `ebpf:"ig_tcp_state"`
 	IgTcpV4CoE  *ebpf.ProgramSpec `ebpf:"ig_tcp_v4_co_e"`
 	IgTcpV4CoX  *ebpf.ProgramSpec `ebpf:"ig_tcp_v4_co_x"`
@@ -91,7 +95,9 @@ type tcptracerProgramSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type tcptracerMapSpecs struct {
+	Birth         *ebpf.MapSpec `ebpf:"birth"`
 	Events        *ebpf.MapSpec `ebpf:"events"`
+	Idents        *ebpf.MapSpec `ebpf:"idents"`
 	MountNsFilter *ebpf.MapSpec `ebpf:"mount_ns_filter"`
 	Sockets       *ebpf.MapSpec `ebpf:"sockets"`
 	Tuplepid      *ebpf.MapSpec `ebpf:"tuplepid"`
@@ -116,7 +122,9 @@ func (o *tcptracerObjects) Close() error {
 //
 // It can be passed to loadTcptracerObjects or ebpf.CollectionSpec.LoadAndAssign.
 type tcptracerMaps struct {
+	Birth         *ebpf.Map `ebpf:"birth"`
 	Events        *ebpf.Map `ebpf:"events"`
+	Idents        *ebpf.Map `ebpf:"idents"`
 	MountNsFilter *ebpf.Map `ebpf:"mount_ns_filter"`
 	Sockets       *ebpf.Map `ebpf:"sockets"`
 	Tuplepid      *ebpf.Map `ebpf:"tuplepid"`
@@ -124,7 +132,9 @@ type tcptracerMaps struct {
 
 func (m *tcptracerMaps) Close() error {
 	return _TcptracerClose(
+		m.Birth,
 		m.Events,
+		m.Idents,
 		m.MountNsFilter,
 		m.Sockets,
 		m.Tuplepid,
@@ -137,6 +147,7 @@ func (m *tcptracerMaps) Close() error {
 type tcptracerPrograms struct {
 	IgTcpAccept *ebpf.Program `ebpf:"ig_tcp_accept"`
 	IgTcpClose  *ebpf.Program `ebpf:"ig_tcp_close"`
+	IgTcpLife   *ebpf.Program `ebpf:"ig_tcp_life"`
 	IgTcpState  *ebpf.Program `ebpf:"ig_tcp_state"`
 	IgTcpV4CoE  *ebpf.Program `ebpf:"ig_tcp_v4_co_e"`
 	IgTcpV4CoX  *ebpf.Program `ebpf:"ig_tcp_v4_co_x"`
@@ -148,6 +159,7 @@ func (p *tcptracerPrograms) Close() error {
 	return _TcptracerClose(
 		p.IgTcpAccept,
 		p.IgTcpClose,
+		p.IgTcpLife,
 		p.IgTcpState,
 		p.IgTcpV4CoE,
 		p.IgTcpV4CoX,
@@ -166,5 +178,6 @@ func _TcptracerClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed tcptracer_bpfel_arm64.o
 var _TcptracerBytes []byte
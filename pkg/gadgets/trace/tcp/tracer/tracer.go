@@ -37,6 +37,10 @@ import (
 
 type Config struct {
 	MountnsMap *ebpf.Map
+	// Lifetime reports one event per connection closed, with its duration
+	// and the bytes transferred, instead of the connect, accept and close
+	// events.
+	Lifetime bool
 }
 
 type Tracer struct {
@@ -53,6 +57,7 @@ type Tracer struct {
 	tcpCloseEnterLink     link.Link
 	tcpSetStateEnterLink  link.Link
 	inetCskAcceptExitLink link.Link
+	tcpLifeLink           link.Link
 
 	reader *perf.Reader
 }
@@ -82,6 +87,7 @@ func (t *Tracer) Stop() {
 	t.tcpCloseEnterLink = gadgets.CloseLink(t.tcpCloseEnterLink)
 	t.tcpSetStateEnterLink = gadgets.CloseLink(t.tcpSetStateEnterLink)
 	t.inetCskAcceptExitLink = gadgets.CloseLink(t.inetCskAcceptExitLink)
+	t.tcpLifeLink = gadgets.CloseLink(t.tcpLifeLink)
 
 	if t.reader != nil {
 		t.reader.Close()
@@ -106,6 +112,7 @@ func (t *Tracer) start() error {
 
 	consts := map[string]interface{}{
 		"filter_by_mnt_ns": filterByMntNs,
+		"lifetime":         t.config.Lifetime,
 	}
 
 	if err := spec.RewriteConstants(consts); err != nil {
@@ -140,15 +147,22 @@ func (t *Tracer) start() error {
 		return fmt.Errorf("error opening kprobe: %w", err)
 	}
 
-	// TODO: rename function in ebpf program
-	t.tcpCloseEnterLink, err = link.Kprobe("tcp_close", t.objs.IgTcpClose, nil)
-	if err != nil {
-		return fmt.Errorf("error opening kprobe: %w", err)
-	}
+	if t.config.Lifetime {
+		t.tcpLifeLink, err = link.Tracepoint("sock", "inet_sock_set_state", t.objs.IgTcpLife, nil)
+		if err != nil {
+			return fmt.Errorf("error opening tracepoint: %w", err)
+		}
+	} else {
+		// TODO: rename function in ebpf program
+		t.tcpCloseEnterLink, err = link.Kprobe("tcp_close", t.objs.IgTcpClose, nil)
+		if err != nil {
+			return fmt.Errorf("error opening kprobe: %w", err)
+		}
 
-	t.tcpSetStateEnterLink, err = link.Kprobe("tcp_set_state", t.objs.IgTcpState, nil)
-	if err != nil {
-		return fmt.Errorf("error opening kprobe: %w", err)
+		t.tcpSetStateEnterLink, err = link.Kprobe("tcp_set_state", t.objs.IgTcpState, nil)
+		if err != nil {
+			return fmt.Errorf("error opening kprobe: %w", err)
+		}
 	}
 
 	t.inetCskAcceptExitLink, err = link.Kretprobe("inet_csk_accept", t.objs.IgTcpAccept, nil)
@@ -200,6 +214,12 @@ func (t *Tracer) run() {
 			Sport:     gadgets.Htons(bpfEvent.Sport),
 		}
 
+		if t.config.Lifetime {
+			event.Duration = bpfEvent.Duration
+			event.SentBytes = bpfEvent.SentBytes
+			event.ReceivedBytes = bpfEvent.ReceivedBytes
+		}
+
 		if bpfEvent.Af == unix.AF_INET {
 			event.IPVersion = 4
 		} else if bpfEvent.Af == unix.AF_INET6 {
@@ -0,0 +1,225 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	utilstest "github.com/lato333/inspektor-gadget/pkg/gadgets/internal/test"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcp/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcp/types"
+)
+
+func TestTcpTracerCreate(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+	if tracer == nil {
+		t.Fatal("Returned tracer was nil")
+	}
+}
+
+func TestTcpTracerStopIdempotent(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+
+	// Check that a double stop doesn't cause issues
+	tracer.Stop()
+	tracer.Stop()
+}
+
+// connection describes the connection opened by exchangeData
+type connection struct {
+	serverPort uint16
+	clientPort uint16
+}
+
+func TestTcpTracer(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	type testDefinition struct {
+		getTracerConfig func(info *utilstest.RunnerInfo) *tracer.Config
+		validateEvent   func(t *testing.T, info *utilstest.RunnerInfo, conn connection, events []types.Event)
+	}
+
+	for name, test := range map[string]testDefinition{
+		"captures_no_events_with_no_matching_filter": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, 0),
+				}
+			},
+			validateEvent: utilstest.ExpectNoEvent[types.Event, connection],
+		},
+		"captures_connect_accept_close": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, conn connection, events []types.Event) {
+				operations := map[string]int{}
+				for _, event := range events {
+					utilstest.Equal(t, uint64(0), event.Duration, "Captured event has a duration outside of lifetime mode")
+					operations[event.Operation]++
+				}
+
+				utilstest.Equal(t, 1, operations["connect"], "Wrong number of connect events")
+				utilstest.Equal(t, 1, operations["accept"], "Wrong number of accept events")
+				utilstest.Equal(t, 2, operations["close"], "Wrong number of close events")
+			},
+		},
+		"lifetime": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+					Lifetime:   true,
+				}
+			},
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, conn connection, events []types.Event) {
+				if len(events) != 2 {
+					t.Fatalf("Wrong number of events received %d, expected 2", len(events))
+				}
+
+				for _, event := range events {
+					utilstest.Equal(t, "close", event.Operation, "Captured event has bad Operation")
+					utilstest.Equal(t, uint32(info.Pid), event.Pid, "Captured event has bad Pid")
+					utilstest.Equal(t, "127.0.0.1", event.Daddr, "Captured event has bad Daddr")
+					if event.Duration == 0 {
+						t.Fatalf("Captured event has no duration")
+					}
+
+					switch event.Dport {
+					case conn.serverPort:
+						// client side of the connection
+						utilstest.Equal(t, conn.clientPort, event.Sport, "Captured event has bad Sport")
+						utilstest.Equal(t, uint64(len(request)), event.SentBytes, "Captured event has bad SentBytes")
+						utilstest.Equal(t, uint64(len(response)), event.ReceivedBytes, "Captured event has bad ReceivedBytes")
+					case conn.clientPort:
+						// server side of the connection
+						utilstest.Equal(t, conn.serverPort, event.Sport, "Captured event has bad Sport")
+						utilstest.Equal(t, uint64(len(response)), event.SentBytes, "Captured event has bad SentBytes")
+						utilstest.Equal(t, uint64(len(request)), event.ReceivedBytes, "Captured event has bad ReceivedBytes")
+					default:
+						t.Fatalf("Captured event has bad Dport %d", event.Dport)
+					}
+				}
+			},
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events := []types.Event{}
+			eventCallback := func(event types.Event) {
+				events = append(events, event)
+			}
+
+			runner := utilstest.NewRunnerWithTest(t, nil)
+
+			createTracer(t, test.getTracerConfig(runner.Info), eventCallback)
+
+			var conn connection
+
+			utilstest.RunWithRunner(t, runner, func() error {
+				var err error
+				conn, err = exchangeData()
+				return err
+			})
+
+			// Give some time for the tracer to capture the events
+			time.Sleep(100 * time.Millisecond)
+
+			test.validateEvent(t, runner.Info, conn, events)
+		})
+	}
+}
+
+func createTracer(
+	t *testing.T, config *tracer.Config, callback func(types.Event),
+) *tracer.Tracer {
+	t.Helper()
+
+	tracer, err := tracer.NewTracer(config, nil, callback)
+	if err != nil {
+		t.Fatalf("Error creating tracer: %s", err)
+	}
+	t.Cleanup(tracer.Stop)
+
+	return tracer
+}
+
+var (
+	request  = []byte("ping")
+	response = []byte("pong!")
+)
+
+// exchangeData opens a connection on the loopback interface, sends a request
+// and its response over it and closes it.
+func exchangeData() (connection, error) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		return connection{}, fmt.Errorf("listening: %w", err)
+	}
+	defer l.Close()
+
+	client, err := net.Dial("tcp4", l.Addr().String())
+	if err != nil {
+		return connection{}, fmt.Errorf("connecting: %w", err)
+	}
+	defer client.Close()
+
+	server, err := l.Accept()
+	if err != nil {
+		return connection{}, fmt.Errorf("accepting: %w", err)
+	}
+	defer server.Close()
+
+	buf := make([]byte, 16)
+
+	if _, err := client.Write(request); err != nil {
+		return connection{}, fmt.Errorf("sending request: %w", err)
+	}
+	if _, err := io.ReadFull(server, buf[:len(request)]); err != nil {
+		return connection{}, fmt.Errorf("receiving request: %w", err)
+	}
+	if _, err := server.Write(response); err != nil {
+		return connection{}, fmt.Errorf("sending response: %w", err)
+	}
+	if _, err := io.ReadFull(client, buf[:len(response)]); err != nil {
+		return connection{}, fmt.Errorf("receiving response: %w", err)
+	}
+
+	return connection{
+		serverPort: uint16(l.Addr().(*net.TCPAddr).Port),
+		clientPort: uint16(client.LocalAddr().(*net.TCPAddr).Port),
+	}, nil
+}
@@ -15,7 +15,12 @@
 package types
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/lato333/inspektor-gadget/pkg/columns"
+	"github.com/lato333/inspektor-gadget/pkg/columns/ellipsis"
+	networkTypes "github.com/lato333/inspektor-gadget/pkg/gadgets/trace/network/types"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
 
@@ -31,6 +36,19 @@ type Event struct {
 	Sport     uint16 `json:"sport,omitempty" column:"sport,template:ipport"`
 	Dport     uint16 `json:"dport,omitempty" column:"dport,template:ipport"`
 	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
+
+	// Duration, in nanoseconds, and the bytes sent and received are only
+	// set in lifetime mode, where Saddr and Sport are the local endpoint
+	// and Daddr and Dport the remote one.
+	Duration      uint64 `json:"duration,omitempty" column:"duration,width:10,align:right,hide"`
+	SentBytes     uint64 `json:"sentBytes,omitempty" column:"sent,minWidth:8,align:right,hide"`
+	ReceivedBytes uint64 `json:"receivedBytes,omitempty" column:"received,minWidth:8,align:right,hide"`
+
+	// The remote pod or service, resolved by the gadget the same way as
+	// the network gadget does it, in lifetime mode.
+	RemoteKind      networkTypes.RemoteKind `json:"remoteKind,omitempty" column:"remotekind,maxWidth:5,hide"`
+	RemoteName      string                  `json:"remoteName,omitempty" column:"remotename,hide"`
+	RemoteNamespace string                  `json:"remoteNamespace,omitempty" column:"remotens,hide"`
 }
 
 func GetColumns() *columns.Columns[Event] {
@@ -51,6 +69,53 @@ func GetColumns() *columns.Columns[Event] {
 		return "U"
 	})
 
+	tcpColumns.MustSetExtractor("duration", func(event *Event) string {
+		if event.Duration == 0 {
+			return ""
+		}
+		return time.Duration(event.Duration).Round(time.Microsecond).String()
+	})
+
+	tcpColumns.MustAddColumn(columns.Column[Event]{
+		Name:         "remote",
+		Width:        32,
+		MinWidth:     21,
+		Visible:      false,
+		Order:        1000,
+		EllipsisType: ellipsis.Start,
+		Extractor: func(e *Event) string {
+			switch e.RemoteKind {
+			case networkTypes.RemoteKindPod:
+				return fmt.Sprintf("pod %s/%s", e.RemoteNamespace, e.RemoteName)
+			case networkTypes.RemoteKindService:
+				return fmt.Sprintf("svc %s/%s", e.RemoteNamespace, e.RemoteName)
+			case networkTypes.RemoteKindOther:
+				return fmt.Sprintf("endpoint %s", e.Daddr)
+			default:
+				return e.Daddr
+			}
+		},
+	})
+
+	return tcpColumns
+}
+
+// GetLifetimeColumns returns the columns of the lifetime mode, showing the
+// duration and the bytes transferred instead of the type of the event.
+func GetLifetimeColumns() *columns.Columns[Event] {
+	tcpColumns := GetColumns()
+
+	for name, visible := range map[string]bool{
+		"t":        false,
+		"duration": true,
+		"sent":     true,
+		"received": true,
+		"remote":   true,
+	} {
+		col, _ := tcpColumns.GetColumn(name)
+		col.Visible = visible
+	}
+
 	return tcpColumns
 }
 
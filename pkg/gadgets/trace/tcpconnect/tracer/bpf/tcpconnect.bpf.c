@@ -17,10 +17,12 @@ const volatile uid_t filter_uid = -1;
 const volatile pid_t filter_pid = 0;
 const volatile bool do_count = 0;
 const volatile bool filter_by_mnt_ns = false;
+const volatile __u64 min_latency_ns = 0;
 
 /* Define here, because there are conflicts with include files */
 #define AF_INET		2
 #define AF_INET6	10
+#define ECONNABORTED	103
 
 // we need this to make sure the compiler doesn't remove our struct
 const struct event *unusedevent __attribute__((unused));
@@ -32,6 +34,15 @@ struct {
 	__type(value, struct sock *);
 } sockets SEC(".maps");
 
+// start keeps the connections in SYN_SENT state, until they are established
+// or fail
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_ENTRIES);
+	__type(key, struct sock *);
+	__type(value, struct piddata);
+} start SEC(".maps");
+
 struct {
 	__uint(type, BPF_MAP_TYPE_HASH);
 	__uint(max_entries, MAX_ENTRIES);
@@ -129,40 +140,46 @@ static __always_inline void count_v6(struct sock *sk, __u16 dport)
 }
 
 static __always_inline void
-trace_v4(struct pt_regs *ctx, pid_t pid, struct sock *sk, __u16 dport, __u64 mntns_id)
+trace_v4(void *ctx, struct piddata *piddata, struct sock *sk, __u16 dport,
+	 __u64 latency, __u32 error)
 {
 	struct event event = {};
 
 	event.af = AF_INET;
-	event.pid = pid;
-	event.uid = bpf_get_current_uid_gid();
+	event.pid = piddata->pid;
+	event.uid = piddata->uid;
 	event.ts_us = bpf_ktime_get_ns() / 1000;
 	BPF_CORE_READ_INTO(&event.saddr_v4, sk, __sk_common.skc_rcv_saddr);
 	BPF_CORE_READ_INTO(&event.daddr_v4, sk, __sk_common.skc_daddr);
 	event.dport = dport;
-	event.mntns_id = mntns_id;
-	bpf_get_current_comm(event.task, sizeof(event.task));
+	event.mntns_id = piddata->mntns_id;
+	event.latency = latency;
+	event.error = error;
+	__builtin_memcpy(event.task, piddata->task, sizeof(event.task));
 
 	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU,
 			      &event, sizeof(event));
 }
 
 static __always_inline void
-trace_v6(struct pt_regs *ctx, pid_t pid, struct sock *sk, __u16 dport, __u64 mntns_id)
+trace_v6(void *ctx, struct piddata *piddata, struct sock *sk, __u16 dport,
+	 __u64 latency, __u32 error)
 {
 	struct event event = {};
 
 	event.af = AF_INET6;
-	event.pid = pid;
-	event.uid = bpf_get_current_uid_gid();
+	event.pid = piddata->pid;
+	event.uid = piddata->uid;
 	event.ts_us = bpf_ktime_get_ns() / 1000;
-	event.mntns_id = mntns_id;
+	event.mntns_id = piddata->mntns_id;
 	BPF_CORE_READ_INTO(&event.saddr_v6, sk,
 			   __sk_common.skc_v6_rcv_saddr.in6_u.u6_addr32);
 	BPF_CORE_READ_INTO(&event.daddr_v6, sk,
 			   __sk_common.skc_v6_daddr.in6_u.u6_addr32);
 	event.dport = dport;
-	bpf_get_current_comm(event.task, sizeof(event.task));
+	event.latency = latency;
+	event.error = error;
+	__builtin_memcpy(event.task, piddata->task, sizeof(event.task));
 
 	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU,
 			      &event, sizeof(event));
@@ -172,7 +189,7 @@ static __always_inline int
 exit_tcp_connect(struct pt_regs *ctx, int ret, int ip_ver)
 {
 	__u64 pid_tgid = bpf_get_current_pid_tgid();
-	__u32 pid = pid_tgid >> 32;
+	struct piddata piddata = {};
 	struct task_struct *task;
 	__u32 tid = pid_tgid;
 	struct sock **skpp;
@@ -184,9 +201,6 @@ exit_tcp_connect(struct pt_regs *ctx, int ret, int ip_ver)
 	if (!skpp)
 		return 0;
 
-	if (ret)
-		goto end;
-
 	sk = *skpp;
 
 	BPF_CORE_READ_INTO(&dport, sk, __sk_common.skc_dport);
@@ -197,25 +211,85 @@ exit_tcp_connect(struct pt_regs *ctx, int ret, int ip_ver)
 	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
 
 	if (filter_by_mnt_ns && !bpf_map_lookup_elem(&mount_ns_filter, &mntns_id))
-		return 0;
+		goto end;
 
 	if (do_count) {
+		if (ret)
+			goto end;
 		if (ip_ver == 4)
 			count_v4(sk, dport);
 		else
 			count_v6(sk, dport);
-	} else {
+		goto end;
+	}
+
+	piddata.pid = pid_tgid >> 32;
+	piddata.uid = bpf_get_current_uid_gid();
+	piddata.mntns_id = mntns_id;
+	piddata.ts = bpf_ktime_get_ns();
+	bpf_get_current_comm(piddata.task, sizeof(piddata.task));
+
+	// The connection failed before sending the SYN, e.g. without a route
+	// to the destination: there is no handshake to wait for.
+	if (ret) {
 		if (ip_ver == 4)
-			trace_v4(ctx, pid, sk, dport, mntns_id);
+			trace_v4(ctx, &piddata, sk, dport, 0, -ret);
 		else
-			trace_v6(ctx, pid, sk, dport, mntns_id);
+			trace_v6(ctx, &piddata, sk, dport, 0, -ret);
+		goto end;
 	}
 
+	bpf_map_update_elem(&start, &sk, &piddata, 0);
+
 end:
 	bpf_map_delete_elem(&sockets, &tid);
 	return 0;
 }
 
+SEC("tracepoint/sock/inet_sock_set_state")
+int ig_tcpc_state(struct trace_event_raw_inet_sock_set_state *ctx)
+{
+	struct sock *sk = (struct sock *)ctx->skaddr;
+	struct piddata *piddata;
+	__u32 error = 0;
+	__u64 latency;
+	__u16 dport;
+
+	if (ctx->protocol != IPPROTO_TCP || ctx->oldstate != TCP_SYN_SENT)
+		return 0;
+
+	piddata = bpf_map_lookup_elem(&start, &sk);
+	if (!piddata)
+		return 0;
+
+	latency = bpf_ktime_get_ns() - piddata->ts;
+
+	if (ctx->newstate == TCP_ESTABLISHED) {
+		if (latency < min_latency_ns)
+			goto cleanup;
+	} else {
+		// The reason of the failure, like ECONNREFUSED for a reset or
+		// ETIMEDOUT when the SYN retries are exhausted, is kept in
+		// sk_err. It's not set when the process gave up and closed the
+		// socket before the end of the handshake, e.g. after its own
+		// timeout because the SYN was dropped by a network policy.
+		error = BPF_CORE_READ(sk, sk_err);
+		if (!error)
+			error = ECONNABORTED;
+	}
+
+	BPF_CORE_READ_INTO(&dport, sk, __sk_common.skc_dport);
+
+	if (ctx->family == AF_INET)
+		trace_v4(ctx, piddata, sk, dport, latency, error);
+	else
+		trace_v6(ctx, piddata, sk, dport, latency, error);
+
+cleanup:
+	bpf_map_delete_elem(&start, &sk);
+	return 0;
+}
+
 SEC("kprobe/tcp_v4_connect")
 int BPF_KPROBE(ig_tcpc_v4_co_e, struct sock *sk)
 {
@@ -23,6 +23,15 @@ struct ipv6_flow_key {
 	__u16 dport;
 };
 
+// piddata keeps the process calling connect() until the end of the handshake
+struct piddata {
+	__u8 task[TASK_COMM_LEN];
+	__u64 ts;
+	__u32 pid;
+	__u32 uid;
+	__u64 mntns_id;
+};
+
 struct event {
 	union {
 		__u8 saddr_v6[16];
@@ -39,6 +48,11 @@ struct event {
 	__u32 uid;
 	__u16 dport;
 	__u64 mntns_id;
+	// latency is the time between connect() and the end of the handshake,
+	// in nanoseconds.
+	__u64 latency;
+	// error is the reason the connection failed, 0 if it was established.
+	__u32 error;
 };
 
 #endif /* __TCPCONNECT_H */
@@ -24,6 +24,9 @@ type tcpconnectEvent struct {
 	Dport   uint16
 	_       [2]byte
 	MntnsId uint64
+	Latency uint64
+	Error   uint32
+	_       [4]byte
 }
 
 type tcpconnectIpv4FlowKey struct {
@@ -39,6 +42,14 @@ type tcpconnectIpv6FlowKey struct {
 	Dport uint16
 }
 
+type tcpconnectPiddata struct {
+	Task    [16]uint8
+	Ts      uint64
+	Pid     uint32
+	Uid     uint32
+	MntnsId uint64
+}
+
 // loadTcpconnect returns the embedded CollectionSpec for tcpconnect.
 func loadTcpconnect() (*ebpf.CollectionSpec, error) {
 	reader := bytes.NewReader(_TcpconnectBytes)
@@ -80,6 +91,7 @@ type tcpconnectSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type tcpconnectProgramSpecs struct {
+	IgTcpcState *ebpf.ProgramSpec `ebpf:"ig_tcpc_state"`
 	IgTcpcV4CoE *ebpf.ProgramSpec `ebpf:"ig_tcpc_v4_co_e"`
 	IgTcpcV4CoX *ebpf.ProgramSpec `ebpf:"ig_tcpc_v4_co_x"`
 	IgTcpcV6CoE *ebpf.ProgramSpec `ebpf:"ig_tcpc_v6_co_e"`
@@ -95,6 +107,7 @@ type tcpconnectMapSpecs struct {
 	Ipv6Count     *ebpf.MapSpec `ebpf:"ipv6_count"`
 	MountNsFilter *ebpf.MapSpec `ebpf:"mount_ns_filter"`
 	Sockets       *ebpf.MapSpec `ebpf:"sockets"`
+	Start         *ebpf.MapSpec `ebpf:"start"`
 }
 
 // tcpconnectObjects contains all objects after they have been loaded into the kernel.
@@ -121,6 +134,7 @@ type tcpconnectMaps struct {
 	Ipv6Count     *ebpf.Map `ebpf:"ipv6_count"`
 	MountNsFilter *ebpf.Map `ebpf:"mount_ns_filter"`
 	Sockets       *ebpf.Map `ebpf:"sockets"`
+	Start         *ebpf.Map `ebpf:"start"`
 }
 
 func (m *tcpconnectMaps) Close() error {
@@ -130,6 +144,7 @@ func (m *tcpconnectMaps) Close() error {
 		m.Ipv6Count,
 		m.MountNsFilter,
 		m.Sockets,
+		m.Start,
 	)
 }
 
@@ -137,6 +152,7 @@ func (m *tcpconnectMaps) Close() error {
 //
 // It can be passed to loadTcpconnectObjects or ebpf.CollectionSpec.LoadAndAssign.
 type tcpconnectPrograms struct {
+	IgTcpcState *ebpf.Program `ebpf:"ig_tcpc_state"`
 	IgTcpcV4CoE *ebpf.Program `ebpf:"ig_tcpc_v4_co_e"`
 	IgTcpcV4CoX *ebpf.Program `ebpf:"ig_tcpc_v4_co_x"`
 	IgTcpcV6CoE *ebpf.Program `ebpf:"ig_tcpc_v6_co_e"`
@@ -145,6 +161,7 @@ type tcpconnectPrograms struct {
 
 func (p *tcpconnectPrograms) Close() error {
 	return _TcpconnectClose(
+		p.IgTcpcState,
 		p.IgTcpcV4CoE,
 		p.IgTcpcV4CoX,
 		p.IgTcpcV6CoE,
@@ -162,5 +179,6 @@ func _TcpconnectClose(closers ...io.Closer) error {
 }
 
 // Do not access this directly.
+//
 //go:embed tcpconnect_bpfel_arm64.o
 var _TcpconnectBytes []byte
@@ -37,6 +37,9 @@ import (
 
 type Config struct {
 	MountnsMap *ebpf.Map
+	// MinLatency is the minimum latency, in ms, of the connections
+	// established to be reported. Failed connections are always reported.
+	MinLatency uint
 }
 
 type Tracer struct {
@@ -49,6 +52,7 @@ type Tracer struct {
 	v4ExitLink  link.Link
 	v6EnterLink link.Link
 	v6ExitLink  link.Link
+	stateLink   link.Link
 	reader      *perf.Reader
 }
 
@@ -74,6 +78,7 @@ func (t *Tracer) Stop() {
 	t.v4ExitLink = gadgets.CloseLink(t.v4ExitLink)
 	t.v6EnterLink = gadgets.CloseLink(t.v6EnterLink)
 	t.v6ExitLink = gadgets.CloseLink(t.v6ExitLink)
+	t.stateLink = gadgets.CloseLink(t.stateLink)
 
 	t.objs.Close()
 }
@@ -95,6 +100,7 @@ func (t *Tracer) start() error {
 
 	consts := map[string]interface{}{
 		"filter_by_mnt_ns": filterByMntNs,
+		"min_latency_ns":   uint64(t.config.MinLatency * 1000 * 1000),
 	}
 
 	if err := spec.RewriteConstants(consts); err != nil {
@@ -129,6 +135,11 @@ func (t *Tracer) start() error {
 		return fmt.Errorf("error attaching program: %w", err)
 	}
 
+	t.stateLink, err = link.Tracepoint("sock", "inet_sock_set_state", t.objs.IgTcpcState, nil)
+	if err != nil {
+		return fmt.Errorf("error opening tracepoint: %w", err)
+	}
+
 	reader, err := perf.NewReader(t.objs.tcpconnectMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
 	if err != nil {
 		return fmt.Errorf("error creating perf ring buffer: %w", err)
@@ -171,6 +182,11 @@ func (t *Tracer) run() {
 			UID:       bpfEvent.Uid,
 			Comm:      gadgets.FromCString(bpfEvent.Task[:]),
 			Dport:     gadgets.Htons(bpfEvent.Dport),
+			Latency:   bpfEvent.Latency,
+		}
+
+		if bpfEvent.Error != 0 {
+			event.Error = unix.ErrnoName(unix.Errno(bpfEvent.Error))
 		}
 
 		if bpfEvent.Af == unix.AF_INET {
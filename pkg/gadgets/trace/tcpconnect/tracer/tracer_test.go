@@ -0,0 +1,242 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	utilstest "github.com/lato333/inspektor-gadget/pkg/gadgets/internal/test"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcpconnect/tracer"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcpconnect/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+func TestTcpconnectTracerCreate(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+	if tracer == nil {
+		t.Fatal("Returned tracer was nil")
+	}
+}
+
+func TestTcpconnectTracerStopIdempotent(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	tracer := createTracer(t, &tracer.Config{}, func(types.Event) {})
+
+	// Check that a double stop doesn't cause issues
+	tracer.Stop()
+	tracer.Stop()
+}
+
+func TestTcpconnectTracer(t *testing.T) {
+	t.Parallel()
+
+	utilstest.RequireRoot(t)
+
+	type testDefinition struct {
+		getTracerConfig func(info *utilstest.RunnerInfo) *tracer.Config
+		generateEvent   func() (uint16, error)
+		validateEvent   func(t *testing.T, info *utilstest.RunnerInfo, port uint16, events []types.Event)
+	}
+
+	baseEvent := func(info *utilstest.RunnerInfo, port uint16) types.Event {
+		return types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Pid:       uint32(info.Pid),
+			Comm:      info.Comm,
+			IPVersion: 4,
+			Saddr:     "127.0.0.1",
+			Daddr:     "127.0.0.1",
+			Dport:     port,
+			MountNsID: info.MountNsID,
+		}
+	}
+
+	for name, test := range map[string]testDefinition{
+		"captures_no_events_with_no_matching_filter": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, 0),
+				}
+			},
+			generateEvent: connectToListener,
+			validateEvent: utilstest.ExpectNoEvent[types.Event, uint16],
+		},
+		"captures_established_connection": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: connectToListener,
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, port uint16, events []types.Event) {
+				// The latency of the handshake can't be predicted, only
+				// check that it was measured.
+				for i := range events {
+					if events[i].Latency == 0 {
+						t.Fatalf("Captured event has no latency")
+					}
+					events[i].Latency = 0
+				}
+
+				utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, port uint16) *types.Event {
+					event := baseEvent(info, port)
+					return &event
+				})(t, info, port, events)
+			},
+		},
+		"captures_refused_connection": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+				}
+			},
+			generateEvent: connectToClosedPort,
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, port uint16, events []types.Event) {
+				for i := range events {
+					events[i].Latency = 0
+				}
+
+				utilstest.ExpectOneEvent(func(info *utilstest.RunnerInfo, port uint16) *types.Event {
+					event := baseEvent(info, port)
+					event.Error = "ECONNREFUSED"
+					return &event
+				})(t, info, port, events)
+			},
+		},
+		"min_latency_ignores_fast_connections": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+					MinLatency: 1000,
+				}
+			},
+			generateEvent: connectToListener,
+			validateEvent: utilstest.ExpectNoEvent[types.Event, uint16],
+		},
+		"min_latency_keeps_failed_connections": {
+			getTracerConfig: func(info *utilstest.RunnerInfo) *tracer.Config {
+				return &tracer.Config{
+					MountnsMap: utilstest.CreateMntNsFilterMap(t, info.MountNsID),
+					MinLatency: 1000,
+				}
+			},
+			generateEvent: connectToClosedPort,
+			validateEvent: func(t *testing.T, info *utilstest.RunnerInfo, port uint16, events []types.Event) {
+				if len(events) != 1 {
+					t.Fatalf("Wrong number of events received %d, expected 1", len(events))
+				}
+
+				utilstest.Equal(t, "ECONNREFUSED", events[0].Error, "Captured event has bad Error")
+			},
+		},
+	} {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events := []types.Event{}
+			eventCallback := func(event types.Event) {
+				events = append(events, event)
+			}
+
+			runner := utilstest.NewRunnerWithTest(t, nil)
+
+			createTracer(t, test.getTracerConfig(runner.Info), eventCallback)
+
+			var port uint16
+
+			utilstest.RunWithRunner(t, runner, func() error {
+				var err error
+				port, err = test.generateEvent()
+				return err
+			})
+
+			// Give some time for the tracer to capture the events
+			time.Sleep(100 * time.Millisecond)
+
+			test.validateEvent(t, runner.Info, port, events)
+		})
+	}
+}
+
+func createTracer(
+	t *testing.T, config *tracer.Config, callback func(types.Event),
+) *tracer.Tracer {
+	t.Helper()
+
+	tracer, err := tracer.NewTracer(config, nil, callback)
+	if err != nil {
+		t.Fatalf("Error creating tracer: %s", err)
+	}
+	t.Cleanup(tracer.Stop)
+
+	return tracer
+}
+
+// connectToListener connects to a socket listening on the loopback interface
+// and returns its port.
+func connectToListener() (uint16, error) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("listening: %w", err)
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("tcp4", l.Addr().String())
+	if err != nil {
+		return 0, fmt.Errorf("connecting: %w", err)
+	}
+	conn.Close()
+
+	return uint16(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+// connectToClosedPort connects to a port of the loopback interface nobody
+// listens on and returns it.
+func connectToClosedPort() (uint16, error) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("listening: %w", err)
+	}
+	addr := l.Addr().String()
+	port := uint16(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	_, err = net.Dial("tcp4", addr)
+	if !errors.Is(err, unix.ECONNREFUSED) {
+		return 0, fmt.Errorf("connecting to closed port: %w", err)
+	}
+
+	return port, nil
+}
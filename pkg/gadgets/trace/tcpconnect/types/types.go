@@ -15,6 +15,8 @@
 package types
 
 import (
+	"time"
+
 	"github.com/lato333/inspektor-gadget/pkg/columns"
 	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
 )
@@ -29,11 +31,27 @@ type Event struct {
 	Saddr     string `json:"saddr,omitempty" column:"saddr,template:ipaddr"`
 	Daddr     string `json:"daddr,omitempty" column:"daddr,template:ipaddr"`
 	Dport     uint16 `json:"dport,omitempty" column:"dport,template:ipport"`
+
+	// Latency is the time between connect() and the end of the handshake
+	// in nanoseconds, and Error the reason of the failure, like
+	// "ECONNREFUSED", if the connection wasn't established.
+	Latency uint64 `json:"latency,omitempty" column:"latency,width:10,align:right"`
+	Error   string `json:"error,omitempty" column:"error,width:12"`
+
 	MountNsID uint64 `json:"mountnsid,omitempty" column:"mntns,template:ns"`
 }
 
 func GetColumns() *columns.Columns[Event] {
-	return columns.MustCreateColumns[Event]()
+	cols := columns.MustCreateColumns[Event]()
+
+	cols.MustSetExtractor("latency", func(event *Event) string {
+		if event.Latency == 0 {
+			return ""
+		}
+		return time.Duration(event.Latency).String()
+	})
+
+	return cols
 }
 
 func Base(ev eventtypes.Event) Event {
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: GPL-2.0
+#include <vmlinux/vmlinux.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+#include <bpf/bpf_tracing.h>
+
+#include "tcpdrop.h"
+
+/* Define here, because there are conflicts with include files */
+#define AF_INET		2
+#define AF_INET6	10
+
+#define ETH_P_IP	0x0800
+#define ETH_P_IPV6	0x86DD
+
+#define IPPROTO_TCP	6
+#define IPPROTO_UDP	17
+
+const volatile bool filter_by_netns = false;
+const volatile bool kernel_stack = false;
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event *unusedevent __attribute__((unused));
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+	__uint(key_size, sizeof(u32));
+	__uint(value_size, sizeof(u32));
+} events SEC(".maps");
+
+// Packets are usually dropped in softirq context, so the current task has
+// nothing to do with the packet. Events are filtered by the network namespace
+// of the socket or of the device instead of the mount namespace of the current
+// task.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_NETNS);
+	__type(key, u64);
+	__type(value, u32);
+} netns_filter SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_STACK_TRACE);
+	__type(key, u32);
+	__uint(max_entries, 1024);
+	__uint(value_size, MAX_STACK_DEPTH * sizeof(u64));
+} stackmap SEC(".maps");
+
+// The reason field was added in Linux 5.17 and isn't present in the vmlinux.h
+// we use. CO-RE ignores the ___reason suffix when relocating.
+struct trace_event_raw_kfree_skb___reason {
+	unsigned int reason;
+} __attribute__((preserve_access_index));
+
+static __always_inline u64
+get_netns(struct sk_buff *skb)
+{
+	struct net_device *dev;
+	struct sock *sk;
+
+	sk = BPF_CORE_READ(skb, sk);
+	if (sk != NULL)
+		return (u64) BPF_CORE_READ(sk, __sk_common.skc_net.net, ns.inum);
+
+	dev = BPF_CORE_READ(skb, dev);
+	if (dev != NULL)
+		return (u64) BPF_CORE_READ(dev, nd_net.net, ns.inum);
+
+	return 0;
+}
+
+SEC("tracepoint/skb/kfree_skb")
+int ig_tcpdrop(struct trace_event_raw_kfree_skb *ctx)
+{
+	struct sk_buff *skb = (struct sk_buff *)ctx->skbaddr;
+	struct trace_event_raw_kfree_skb___reason *ctx_reason = (void *)ctx;
+	struct event event = {};
+	u16 network_header, transport_header;
+	unsigned char *head;
+	__be16 ports[2];
+	u64 netns;
+
+	netns = get_netns(skb);
+	if (netns == 0)
+		return 0;
+	if (filter_by_netns && !bpf_map_lookup_elem(&netns_filter, &netns))
+		return 0;
+
+	head = BPF_CORE_READ(skb, head);
+	network_header = BPF_CORE_READ(skb, network_header);
+	transport_header = BPF_CORE_READ(skb, transport_header);
+
+	// protocol is the ethertype in host byte order
+	switch (ctx->protocol) {
+	case ETH_P_IP: {
+		struct iphdr iph;
+
+		if (bpf_probe_read_kernel(&iph, sizeof(iph), head + network_header))
+			return 0;
+		event.af = AF_INET;
+		event.proto = iph.protocol;
+		event.saddr_v4 = iph.saddr;
+		event.daddr_v4 = iph.daddr;
+		// The transport header isn't set yet when the packet is
+		// dropped early in the receive path
+		if (transport_header == (u16)~0U || transport_header == network_header)
+			transport_header = network_header + iph.ihl * 4;
+		break;
+	}
+	case ETH_P_IPV6: {
+		struct ipv6hdr ip6h;
+
+		if (bpf_probe_read_kernel(&ip6h, sizeof(ip6h), head + network_header))
+			return 0;
+		event.af = AF_INET6;
+		// Extension headers are not followed
+		event.proto = ip6h.nexthdr;
+		bpf_probe_read_kernel(&event.saddr_v6, sizeof(event.saddr_v6), &ip6h.saddr);
+		bpf_probe_read_kernel(&event.daddr_v6, sizeof(event.daddr_v6), &ip6h.daddr);
+		if (transport_header == (u16)~0U || transport_header == network_header)
+			transport_header = network_header + sizeof(ip6h);
+		break;
+	}
+	default:
+		return 0;
+	}
+
+	if (event.proto != IPPROTO_TCP && event.proto != IPPROTO_UDP)
+		return 0;
+
+	// Both TCP and UDP headers start with the source and destination ports
+	if (bpf_probe_read_kernel(&ports, sizeof(ports), head + transport_header))
+		return 0;
+	event.sport = ports[0];
+	event.dport = ports[1];
+
+	event.netns = netns;
+	event.location = (u64) ctx->location;
+	if (bpf_core_field_exists(ctx_reason->reason))
+		event.reason = BPF_CORE_READ(ctx_reason, reason);
+
+	event.kernel_stack_id = -1;
+	if (kernel_stack)
+		event.kernel_stack_id = bpf_get_stackid(ctx, &stackmap, 0);
+
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU,
+			      &event, sizeof(event));
+	return 0;
+}
+
+char LICENSE[] SEC("license") = "GPL";
@@ -0,0 +1,29 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+#ifndef __TCPDROP_H
+#define __TCPDROP_H
+
+/* The maximum number of network namespaces to trace */
+#define MAX_NETNS 1024
+
+#define MAX_STACK_DEPTH 127
+
+struct event {
+	union {
+		__u8 saddr_v6[16];
+		__u32 saddr_v4;
+	};
+	union {
+		__u8 daddr_v6[16];
+		__u32 daddr_v4;
+	};
+	__u64 netns;
+	__u64 location;
+	__s32 kernel_stack_id;
+	__u32 reason;
+	__u16 af; // AF_INET or AF_INET6
+	__u16 sport;
+	__u16 dport;
+	__u8 proto;
+};
+
+#endif /* __TCPDROP_H */
@@ -0,0 +1,320 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/cilium/ebpf/btf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/internal/kallsyms"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcpdrop/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event tcpdrop ./bpf/tcpdrop.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+const maxStackDepth = 127
+
+var protocols = map[uint8]string{
+	unix.IPPROTO_TCP: "TCP",
+	unix.IPPROTO_UDP: "UDP",
+}
+
+type Config struct {
+	// FilterByNetns restricts the tracing to the network namespaces added
+	// with AddNetns(). Otherwise, drops in all the network namespaces of
+	// the host are traced.
+	FilterByNetns bool
+
+	// KernelStack enables the collection of the kernel stack of each drop.
+	KernelStack bool
+}
+
+// Tracer traces packets dropped by the kernel. Like tcpretrans, it can't
+// filter by mount namespace because drops don't happen in the context of the
+// process owning the socket, so events are filtered and enriched using the
+// network namespace of the socket or the device.
+type Tracer struct {
+	config        *Config
+	objs          tcpdropObjects
+	kfreeSkbLink  link.Link
+	reader        *perf.Reader
+	enricher      gadgets.DataEnricherByNetNs
+	eventCallback func(types.Event)
+
+	// dropReasons is nil if the kernel doesn't report drop reasons, and
+	// empty if their names couldn't be read.
+	dropReasons map[uint32]string
+
+	// kAllSyms is nil if the kernel symbols couldn't be read
+	kAllSyms *kallsyms.KAllSyms
+
+	mu sync.Mutex
+
+	// netnsUsers counts the users of each network namespace added to
+	// the filter
+	netnsUsers map[uint64]int
+}
+
+func NewTracer(config *Config, enricher gadgets.DataEnricherByNetNs,
+	eventCallback func(types.Event),
+) (*Tracer, error) {
+	t := &Tracer{
+		config:        config,
+		enricher:      enricher,
+		eventCallback: eventCallback,
+		netnsUsers:    make(map[uint64]int),
+	}
+
+	if err := t.start(); err != nil {
+		t.Stop()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Tracer) Stop() {
+	t.kfreeSkbLink = gadgets.CloseLink(t.kfreeSkbLink)
+
+	if t.reader != nil {
+		t.reader.Close()
+	}
+
+	t.objs.Close()
+}
+
+func (t *Tracer) start() error {
+	var err error
+
+	// The names of the reasons and of the kernel functions are only
+	// needed to make the events readable, the gadget can do without.
+	t.dropReasons, err = readDropReasons()
+	if err != nil {
+		log.Warnf("tcpdrop: reading drop reasons, reporting their numeric value: %s", err)
+		t.dropReasons = map[uint32]string{}
+	}
+
+	t.kAllSyms, err = kallsyms.NewKAllSyms()
+	if err != nil {
+		log.Warnf("tcpdrop: reading kernel symbols, reporting addresses: %s", err)
+	}
+
+	spec, err := loadTcpdrop()
+	if err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	consts := map[string]interface{}{
+		"filter_by_netns": t.config.FilterByNetns,
+		"kernel_stack":    t.config.KernelStack,
+	}
+
+	if err := spec.RewriteConstants(consts); err != nil {
+		return fmt.Errorf("error RewriteConstants: %w", err)
+	}
+
+	if err := spec.LoadAndAssign(&t.objs, nil); err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	t.kfreeSkbLink, err = link.Tracepoint("skb", "kfree_skb", t.objs.IgTcpdrop, nil)
+	if err != nil {
+		return fmt.Errorf("error opening tracepoint: %w", err)
+	}
+
+	reader, err := perf.NewReader(t.objs.tcpdropMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("error creating perf ring buffer: %w", err)
+	}
+	t.reader = reader
+
+	go t.run()
+
+	return nil
+}
+
+// AddNetns adds a network namespace to the filter. It's only useful when
+// Config.FilterByNetns is set. The network namespace is shared by all the
+// containers of a pod, so AddNetns() can be called several times for the same
+// one.
+func (t *Tracer) AddNetns(netns uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.netnsUsers[netns] == 0 {
+		one := uint32(1)
+		if err := t.objs.NetnsFilter.Put(netns, one); err != nil {
+			return fmt.Errorf("adding network namespace %d to the filter: %w", netns, err)
+		}
+	}
+	t.netnsUsers[netns]++
+
+	return nil
+}
+
+// RemoveNetns undoes a previous call to AddNetns().
+func (t *Tracer) RemoveNetns(netns uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	users, ok := t.netnsUsers[netns]
+	if !ok {
+		return fmt.Errorf("network namespace %d is not in the filter", netns)
+	}
+	if users > 1 {
+		t.netnsUsers[netns]--
+		return nil
+	}
+
+	delete(t.netnsUsers, netns)
+	if err := t.objs.NetnsFilter.Delete(netns); err != nil {
+		return fmt.Errorf("removing network namespace %d from the filter: %w", netns, err)
+	}
+
+	return nil
+}
+
+func (t *Tracer) run() {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				// nothing to do, we're done
+				return
+			}
+
+			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.eventCallback(types.Base(eventtypes.Err(msg)))
+			return
+		}
+
+		if record.LostSamples > 0 {
+			msg := fmt.Sprintf("lost %d samples", record.LostSamples)
+			t.eventCallback(types.Base(eventtypes.Warn(msg)))
+			continue
+		}
+
+		bpfEvent := (*tcpdropEvent)(unsafe.Pointer(&record.RawSample[0]))
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Protocol: protocols[bpfEvent.Proto],
+			Sport:    gadgets.Htons(bpfEvent.Sport),
+			Dport:    gadgets.Htons(bpfEvent.Dport),
+			Location: t.symbol(bpfEvent.Location),
+			NetNsID:  bpfEvent.Netns,
+		}
+
+		// Without the names of the reasons, it's unknown if the kernel
+		// reports them, only report the ones that are set.
+		if t.dropReasons != nil && (len(t.dropReasons) > 0 || bpfEvent.Reason != 0) {
+			reason, ok := t.dropReasons[bpfEvent.Reason]
+			if !ok {
+				reason = strconv.FormatUint(uint64(bpfEvent.Reason), 10)
+			}
+			event.Reason = reason
+		}
+
+		if bpfEvent.Af == unix.AF_INET {
+			event.IPVersion = 4
+		} else if bpfEvent.Af == unix.AF_INET6 {
+			event.IPVersion = 6
+		}
+
+		event.Saddr = gadgets.IPStringFromBytes(bpfEvent.SaddrV6, event.IPVersion)
+		event.Daddr = gadgets.IPStringFromBytes(bpfEvent.DaddrV6, event.IPVersion)
+
+		if bpfEvent.KernelStackId >= 0 {
+			event.KernelStack = t.kernelStack(bpfEvent.KernelStackId)
+		}
+
+		if t.enricher != nil {
+			t.enricher.EnrichByNetNs(&event.CommonData, event.NetNsID)
+		}
+
+		t.eventCallback(event)
+	}
+}
+
+func (t *Tracer) kernelStack(stackID int32) []string {
+	ips := [maxStackDepth]uint64{}
+	if err := t.objs.Stackmap.Lookup(stackID, unsafe.Pointer(&ips)); err != nil {
+		return nil
+	}
+	// Each stack is only reported once, deleting it keeps room for the
+	// other ones. It's done separately as older kernels don't support
+	// LookupAndDelete() on stack trace maps.
+	t.objs.Stackmap.Delete(stackID)
+
+	stack := []string{}
+	for _, ip := range ips {
+		if ip == 0 {
+			break
+		}
+		stack = append(stack, t.symbol(ip))
+	}
+	return stack
+}
+
+// symbol returns the name of the kernel function containing the instruction
+// pointer, or its address if the kernel symbols couldn't be read.
+func (t *Tracer) symbol(ip uint64) string {
+	if t.kAllSyms == nil {
+		return fmt.Sprintf("0x%x", ip)
+	}
+	return t.kAllSyms.LookupByInstructionPointer(ip)
+}
+
+// readDropReasons reads the names of the skb_drop_reason enum from the kernel
+// BTF, as their values change between kernel versions. It returns a nil map on
+// kernels without drop reasons.
+func readDropReasons() (map[uint32]string, error) {
+	spec, err := btf.LoadKernelSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	var enum *btf.Enum
+	if err := spec.TypeByName("skb_drop_reason", &enum); err != nil {
+		if errors.Is(err, btf.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	reasons := make(map[uint32]string, len(enum.Values))
+	for _, v := range enum.Values {
+		reasons[uint32(v.Value)] = strings.TrimPrefix(v.Name, "SKB_DROP_REASON_")
+	}
+	return reasons, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+type Event struct {
+	eventtypes.Event
+
+	// Reason is the name of the skb_drop_reason, without the
+	// SKB_DROP_REASON_ prefix. It's empty on kernels older than 5.17.
+	Reason    string `json:"reason,omitempty" column:"reason,width:20"`
+	Protocol  string `json:"protocol,omitempty" column:"proto,width:5,fixed"`
+	IPVersion int    `json:"ipversion,omitempty" column:"ip,width:2,fixed"`
+	Saddr     string `json:"saddr,omitempty" column:"saddr,template:ipaddr"`
+	Sport     uint16 `json:"sport,omitempty" column:"sport,template:ipport"`
+	Daddr     string `json:"daddr,omitempty" column:"daddr,template:ipaddr"`
+	Dport     uint16 `json:"dport,omitempty" column:"dport,template:ipport"`
+
+	// Location is the kernel function that dropped the packet
+	Location string `json:"location,omitempty" column:"location,width:24,hide"`
+	NetNsID  uint64 `json:"netnsid,omitempty" column:"netns,template:ns"`
+
+	KernelStack []string `json:"kernelStack,omitempty"`
+}
+
+func GetColumns() *columns.Columns[Event] {
+	cols := columns.MustCreateColumns[Event]()
+
+	// Drops are attributed through the network namespace, which is shared
+	// by all the containers of a pod.
+	col, _ := cols.GetColumn("container")
+	col.Visible = false
+
+	return cols
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: GPL-2.0
+//
+// Based on tcpretrans(8) from BCC by Brendan Gregg
+#include <vmlinux/vmlinux.h>
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+#include <bpf/bpf_tracing.h>
+
+#include "tcpretrans.h"
+
+/* Define here, because there are conflicts with include files */
+#define AF_INET		2
+#define AF_INET6	10
+
+// we need this to make sure the compiler doesn't remove our struct
+const struct event *unusedevent __attribute__((unused));
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERF_EVENT_ARRAY);
+	__uint(key_size, sizeof(u32));
+	__uint(value_size, sizeof(u32));
+} events SEC(".maps");
+
+// Retransmissions are usually triggered from a timer or when processing an
+// incoming ACK, so the current task has nothing to do with the socket. Events
+// are therefore filtered by the network namespace of the socket instead of
+// the mount namespace of the current task.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, MAX_NETNS);
+	__type(key, u64);
+	__type(value, u32);
+} netns_filter SEC(".maps");
+
+static __always_inline int
+trace_retrans(void *ctx, struct sock *sk, __u8 type)
+{
+	struct event event = {};
+	__u16 family;
+	__u64 netns;
+
+	if (sk == NULL)
+		return 0;
+
+	netns = (u64) BPF_CORE_READ(sk, __sk_common.skc_net.net, ns.inum);
+	if (!bpf_map_lookup_elem(&netns_filter, &netns))
+		return 0;
+
+	family = BPF_CORE_READ(sk, __sk_common.skc_family);
+	switch (family) {
+	case AF_INET:
+		BPF_CORE_READ_INTO(&event.saddr_v4, sk, __sk_common.skc_rcv_saddr);
+		BPF_CORE_READ_INTO(&event.daddr_v4, sk, __sk_common.skc_daddr);
+		break;
+	case AF_INET6:
+		BPF_CORE_READ_INTO(&event.saddr_v6, sk,
+				   __sk_common.skc_v6_rcv_saddr.in6_u.u6_addr32);
+		BPF_CORE_READ_INTO(&event.daddr_v6, sk,
+				   __sk_common.skc_v6_daddr.in6_u.u6_addr32);
+		break;
+	default:
+		return 0;
+	}
+
+	event.af = family;
+	event.netns = netns;
+	event.type = type;
+	event.state = BPF_CORE_READ(sk, __sk_common.skc_state);
+	// skc_num is in host byte order, skc_dport in network byte order
+	event.sport = BPF_CORE_READ(sk, __sk_common.skc_num);
+	event.dport = BPF_CORE_READ(sk, __sk_common.skc_dport);
+
+	bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU,
+			      &event, sizeof(event));
+	return 0;
+}
+
+SEC("kprobe/tcp_retransmit_skb")
+int BPF_KPROBE(ig_tcpretrans, struct sock *sk)
+{
+	return trace_retrans(ctx, sk, RETRANS);
+}
+
+SEC("kprobe/tcp_send_loss_probe")
+int BPF_KPROBE(ig_tcpretrans_tlp, struct sock *sk)
+{
+	return trace_retrans(ctx, sk, TLP);
+}
+
+char LICENSE[] SEC("license") = "GPL";
@@ -0,0 +1,30 @@
+/* SPDX-License-Identifier: GPL-2.0 */
+#ifndef __TCPRETRANS_H
+#define __TCPRETRANS_H
+
+/* The maximum number of network namespaces to trace */
+#define MAX_NETNS 1024
+
+enum retrans_type {
+	RETRANS = 1,
+	TLP = 2,
+};
+
+struct event {
+	union {
+		__u8 saddr_v6[16];
+		__u32 saddr_v4;
+	};
+	union {
+		__u8 daddr_v6[16];
+		__u32 daddr_v4;
+	};
+	__u64 netns;
+	__u16 af; // AF_INET or AF_INET6
+	__u16 sport;
+	__u16 dport;
+	__u8 state;
+	__u8 type;
+};
+
+#endif /* __TCPRETRANS_H */
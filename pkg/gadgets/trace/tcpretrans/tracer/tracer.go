@@ -0,0 +1,258 @@
+//go:build linux
+// +build linux
+
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/perf"
+	"golang.org/x/sys/unix"
+
+	containerutils "github.com/lato333/inspektor-gadget/pkg/container-utils"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets"
+	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcpretrans/types"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event tcpretrans ./bpf/tcpretrans.bpf.c -- -I./bpf/ -I../../../../${TARGET}
+
+const (
+	retransTypeRetrans = 1
+	retransTypeTLP     = 2
+)
+
+// From include/net/tcp_states.h
+var tcpStates = map[uint8]string{
+	1:  "ESTABLISHED",
+	2:  "SYN_SENT",
+	3:  "SYN_RECV",
+	4:  "FIN_WAIT1",
+	5:  "FIN_WAIT2",
+	6:  "TIME_WAIT",
+	7:  "CLOSE",
+	8:  "CLOSE_WAIT",
+	9:  "LAST_ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+	12: "NEW_SYN_RECV",
+}
+
+type attachment struct {
+	eventCallback func(types.Event)
+
+	// users keeps track of the users' pid that have called Attach(). The
+	// network namespace is shared by all the containers of a pod and by the
+	// pods using hostNetwork=true.
+	users map[uint32]struct{}
+}
+
+// Tracer traces TCP retransmissions. Contrary to most tracers, it doesn't
+// filter events by mount namespace because retransmissions don't happen in
+// the context of the process owning the socket. Instead, it implements the
+// networktracer interface: each container is attached with its network
+// namespace.
+type Tracer struct {
+	objs        tcpretransObjects
+	retransLink link.Link
+	tlpLink     link.Link
+	reader      *perf.Reader
+
+	mu sync.Mutex
+
+	// key: network namespace inode number
+	attachments map[uint64]*attachment
+}
+
+func NewTracer() (*Tracer, error) {
+	t := &Tracer{
+		attachments: make(map[uint64]*attachment),
+	}
+
+	if err := t.start(); err != nil {
+		t.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *Tracer) Close() {
+	t.retransLink = gadgets.CloseLink(t.retransLink)
+	t.tlpLink = gadgets.CloseLink(t.tlpLink)
+
+	if t.reader != nil {
+		t.reader.Close()
+	}
+
+	t.objs.Close()
+}
+
+func (t *Tracer) start() error {
+	var err error
+	spec, err := loadTcpretrans()
+	if err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	if err := spec.LoadAndAssign(&t.objs, nil); err != nil {
+		return fmt.Errorf("failed to load ebpf program: %w", err)
+	}
+
+	t.retransLink, err = link.Kprobe("tcp_retransmit_skb", t.objs.IgTcpretrans, nil)
+	if err != nil {
+		return fmt.Errorf("error attaching program: %w", err)
+	}
+
+	t.tlpLink, err = link.Kprobe("tcp_send_loss_probe", t.objs.IgTcpretransTlp, nil)
+	if err != nil {
+		return fmt.Errorf("error attaching program: %w", err)
+	}
+
+	reader, err := perf.NewReader(t.objs.tcpretransMaps.Events, gadgets.PerfBufferPages*os.Getpagesize())
+	if err != nil {
+		return fmt.Errorf("error creating perf ring buffer: %w", err)
+	}
+	t.reader = reader
+
+	go t.run()
+
+	return nil
+}
+
+func (t *Tracer) Attach(pid uint32, eventCallback func(types.Event)) error {
+	netns, err := containerutils.GetNetNs(int(pid))
+	if err != nil {
+		return fmt.Errorf("getting network namespace of pid %d: %w", pid, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if a, ok := t.attachments[netns]; ok {
+		a.users[pid] = struct{}{}
+		return nil
+	}
+
+	one := uint32(1)
+	if err := t.objs.NetnsFilter.Put(netns, one); err != nil {
+		return fmt.Errorf("adding network namespace %d to the filter: %w", netns, err)
+	}
+
+	t.attachments[netns] = &attachment{
+		eventCallback: eventCallback,
+		users:         map[uint32]struct{}{pid: {}},
+	}
+
+	return nil
+}
+
+func (t *Tracer) Detach(pid uint32) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for netns, a := range t.attachments {
+		if _, ok := a.users[pid]; !ok {
+			continue
+		}
+
+		delete(a.users, pid)
+		if len(a.users) == 0 {
+			if err := t.objs.NetnsFilter.Delete(netns); err != nil {
+				return fmt.Errorf("removing network namespace %d from the filter: %w", netns, err)
+			}
+			delete(t.attachments, netns)
+		}
+		return nil
+	}
+	return fmt.Errorf("pid %d is not attached", pid)
+}
+
+// broadcast sends an event to all the attached containers, it's used for
+// errors and warnings that are not related to a specific container.
+func (t *Tracer) broadcast(event types.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, a := range t.attachments {
+		a.eventCallback(event)
+	}
+}
+
+func (t *Tracer) run() {
+	for {
+		record, err := t.reader.Read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				// nothing to do, we're done
+				return
+			}
+
+			msg := fmt.Sprintf("Error reading perf ring buffer: %s", err)
+			t.broadcast(types.Base(eventtypes.Err(msg)))
+			return
+		}
+
+		if record.LostSamples > 0 {
+			msg := fmt.Sprintf("lost %d samples", record.LostSamples)
+			t.broadcast(types.Base(eventtypes.Warn(msg)))
+			continue
+		}
+
+		bpfEvent := (*tcpretransEvent)(unsafe.Pointer(&record.RawSample[0]))
+
+		event := types.Event{
+			Event: eventtypes.Event{
+				Type: eventtypes.NORMAL,
+			},
+			Sport:   bpfEvent.Sport,
+			Dport:   gadgets.Htons(bpfEvent.Dport),
+			State:   tcpStates[bpfEvent.State],
+			NetNsID: bpfEvent.Netns,
+		}
+
+		switch bpfEvent.Type {
+		case retransTypeRetrans:
+			event.RetransType = types.RetransTypeRetrans
+		case retransTypeTLP:
+			event.RetransType = types.RetransTypeTLP
+		}
+
+		if bpfEvent.Af == unix.AF_INET {
+			event.IPVersion = 4
+		} else if bpfEvent.Af == unix.AF_INET6 {
+			event.IPVersion = 6
+		}
+
+		event.Saddr = gadgets.IPStringFromBytes(bpfEvent.SaddrV6, event.IPVersion)
+		event.Daddr = gadgets.IPStringFromBytes(bpfEvent.DaddrV6, event.IPVersion)
+
+		t.mu.Lock()
+		a, ok := t.attachments[bpfEvent.Netns]
+		t.mu.Unlock()
+		if !ok {
+			// The container was detached in the meantime
+			continue
+		}
+		a.eventCallback(event)
+	}
+}
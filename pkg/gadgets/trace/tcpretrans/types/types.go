@@ -0,0 +1,59 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/lato333/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/lato333/inspektor-gadget/pkg/types"
+)
+
+const (
+	// RetransTypeRetrans is a retransmission triggered by a timeout or by
+	// duplicated ACKs
+	RetransTypeRetrans = "RETRANS"
+
+	// RetransTypeTLP is a tail loss probe
+	RetransTypeTLP = "TLP"
+)
+
+type Event struct {
+	eventtypes.Event
+
+	RetransType string `json:"retranstype,omitempty" column:"type,width:7,fixed"`
+	IPVersion   int    `json:"ipversion,omitempty" column:"ip,width:2,fixed"`
+	Saddr       string `json:"saddr,omitempty" column:"saddr,template:ipaddr"`
+	Sport       uint16 `json:"sport,omitempty" column:"sport,template:ipport"`
+	Daddr       string `json:"daddr,omitempty" column:"daddr,template:ipaddr"`
+	Dport       uint16 `json:"dport,omitempty" column:"dport,template:ipport"`
+	State       string `json:"state,omitempty" column:"state,width:11"`
+	NetNsID     uint64 `json:"netnsid,omitempty" column:"netns,template:ns"`
+}
+
+func GetColumns() *columns.Columns[Event] {
+	cols := columns.MustCreateColumns[Event]()
+
+	// Retransmissions are attributed through the network namespace, which
+	// is shared by all the containers of a pod.
+	col, _ := cols.GetColumn("container")
+	col.Visible = false
+
+	return cols
+}
+
+func Base(ev eventtypes.Event) Event {
+	return Event{
+		Event: ev,
+	}
+}
@@ -211,6 +211,11 @@ func (g *GadgetTracerManager) DumpState(_ context.Context, req *pb.DumpStateRequ
 	out += "List of tracers:\n"
 	out += g.tracerCollection.TracerDump()
 
+	out += "List of container event subscribers:\n"
+	for _, stats := range g.PubSubStats() {
+		out += fmt.Sprintf("%+v\n", stats)
+	}
+
 	out += "List of stacks:\n"
 	buf := make([]byte, 1<<20)
 	stacklen := runtime.Stack(buf, true)
@@ -258,6 +263,7 @@ func NewServer(conf *Conf) (*GadgetTracerManager, error) {
 	if !conf.TestOnly {
 		opts = append(opts, containercollection.WithOCIConfigEnrichment())
 		opts = append(opts, containercollection.WithCgroupEnrichment())
+		opts = append(opts, containercollection.WithCgroupLimitsEnrichment())
 		opts = append(opts, containercollection.WithLinuxNamespaceEnrichment())
 		opts = append(opts, containercollection.WithKubernetesEnrichment(g.nodeName, nil))
 	}
@@ -298,6 +304,11 @@ func NewServer(conf *Conf) (*GadgetTracerManager, error) {
 		opts = append(opts, containercollection.WithFallbackPodInformer(g.nodeName))
 	}
 
+	if conf.StateFile != "" {
+		log.Infof("GadgetTracerManager: persisting containers in %s", conf.StateFile)
+		opts = append(opts, containercollection.WithPersistentState(conf.StateFile))
+	}
+
 	err = g.ContainerCollection.Initialize(opts...)
 	if err != nil {
 		return nil, err
@@ -310,6 +321,7 @@ type Conf struct {
 	NodeName            string
 	HookMode            string
 	FallbackPodInformer bool
+	StateFile           string
 	TestOnly            bool
 }
 
@@ -322,7 +322,13 @@ func (l *LocalGadgetManager) RemoveMountNsMap() error {
 	return l.tracerCollection.RemoveTracer(localGadgetTracerID)
 }
 
-func NewManager(runtimes []*containerutils.RuntimeConfig) (*LocalGadgetManager, error) {
+// NewManager creates a LocalGadgetManager tracking the containers of the given
+// runtimes. Additional options, like WithHostContainer(), can be passed to
+// track more workloads.
+func NewManager(
+	runtimes []*containerutils.RuntimeConfig,
+	extraOpts ...containercollection.ContainerCollectionOption,
+) (*LocalGadgetManager, error) {
 	l := &LocalGadgetManager{
 		traceFactories: gadgetcollection.TraceFactoriesForLocalGadget(),
 		traceResources: make(map[string]*gadgetv1alpha1.Trace),
@@ -346,14 +352,18 @@ func NewManager(runtimes []*containerutils.RuntimeConfig) (*LocalGadgetManager,
 	containerEventFuncs = append(containerEventFuncs, l.containersMap.ContainersMapUpdater())
 	containerEventFuncs = append(containerEventFuncs, l.tracerCollection.TracerMapsUpdater())
 
-	err = l.ContainerCollection.Initialize(
+	opts := []containercollection.ContainerCollectionOption{
 		containercollection.WithPubSub(containerEventFuncs...),
 		containercollection.WithOCIConfigEnrichment(),
 		containercollection.WithCgroupEnrichment(),
+		containercollection.WithCgroupLimitsEnrichment(),
 		containercollection.WithLinuxNamespaceEnrichment(),
 		containercollection.WithMultipleContainerRuntimesEnrichment(runtimes),
 		containercollection.WithRuncFanotify(),
-	)
+	}
+	opts = append(opts, extraOpts...)
+
+	err = l.ContainerCollection.Initialize(opts...)
 	if err != nil {
 		return nil, err
 	}
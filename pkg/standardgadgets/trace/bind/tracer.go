@@ -16,6 +16,7 @@ package standard
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/bind/tracer"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/bind/types"
@@ -24,6 +25,10 @@ import (
 )
 
 func NewTracer(config *tracer.Config, eventCallback func(types.Event)) (*trace.StandardTracer[types.Event], error) {
+	if config.Listen {
+		return nil, errors.New("tracing listen isn't supported by the standard tracer")
+	}
+
 	prepareLine := func(line string) string {
 		event := map[string]interface{}{}
 
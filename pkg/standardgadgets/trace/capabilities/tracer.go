@@ -15,12 +15,18 @@
 package standard
 
 import (
+	"errors"
+
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/capabilities/tracer"
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/capabilities/types"
 	"github.com/lato333/inspektor-gadget/pkg/standardgadgets/trace"
 )
 
 func NewTracer(config *tracer.Config, eventCallback func(types.Event)) (*trace.StandardTracer[types.Event], error) {
+	if config.PrintStack {
+		return nil, errors.New("printing the stack isn't supported by the standard tracer")
+	}
+
 	standardConfig := &trace.StandardTracerConfig[types.Event]{
 		ScriptName:    "capable",
 		EventCallback: eventCallback,
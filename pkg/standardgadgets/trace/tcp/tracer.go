@@ -15,6 +15,7 @@
 package standard
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcp/tracer"
@@ -23,6 +24,10 @@ import (
 )
 
 func NewTracer(config *tracer.Config, eventCallback func(types.Event)) (*trace.StandardTracer[types.Event], error) {
+	if config.Lifetime {
+		return nil, errors.New("lifetime mode isn't supported by the standard tracer")
+	}
+
 	prepareLine := func(line string) string {
 		// "Hack" to avoid changing the BCC tool implementation
 		line = strings.ReplaceAll(line, `"ip"`, `"ipversion"`)
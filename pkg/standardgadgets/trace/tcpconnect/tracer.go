@@ -15,6 +15,7 @@
 package standard
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/lato333/inspektor-gadget/pkg/gadgets/trace/tcpconnect/tracer"
@@ -23,6 +24,12 @@ import (
 )
 
 func NewTracer(config *tracer.Config, eventCallback func(types.Event)) (*trace.StandardTracer[types.Event], error) {
+	// The BCC tool reports the connect() calls only, not the end of the
+	// handshake.
+	if config.MinLatency != 0 {
+		return nil, errors.New("minimum latency isn't supported by the standard tracer")
+	}
+
 	prepareLine := func(line string) string {
 		// "Hack" to avoid changing the BCC tool implementation
 		line = strings.ReplaceAll(line, `"ip"`, `"ipversion"`)
@@ -0,0 +1,134 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracercollection
+
+import (
+	"sort"
+	"strings"
+
+	containercollection "github.com/lato333/inspektor-gadget/pkg/container-collection"
+)
+
+// tracerSet is a set of tracer IDs.
+type tracerSet map[string]struct{}
+
+func indexAdd(index map[string]tracerSet, key, id string) {
+	set, ok := index[key]
+	if !ok {
+		set = make(tracerSet)
+		index[key] = set
+	}
+	set[id] = struct{}{}
+}
+
+func indexRemove(index map[string]tracerSet, key, id string) {
+	set, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(set, id)
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+// tracerIndex keeps the tracers indexed by the most selective criteria of
+// their container selector, so that the tracers that can match a container
+// are found without evaluating the selector of every tracer. Each tracer is
+// indexed under a single criteria, in the same order of preference as the
+// container index of the container collection: pod name, container name,
+// namespaces, one of the label keys, or none.
+type tracerIndex struct {
+	all         tracerSet
+	byPodname   map[string]tracerSet
+	byName      map[string]tracerSet
+	byNamespace map[string]tracerSet
+	byLabelKey  map[string]tracerSet
+}
+
+func newTracerIndex() *tracerIndex {
+	return &tracerIndex{
+		all:         make(tracerSet),
+		byPodname:   make(map[string]tracerSet),
+		byName:      make(map[string]tracerSet),
+		byNamespace: make(map[string]tracerSet),
+		byLabelKey:  make(map[string]tracerSet),
+	}
+}
+
+// update adds (add == true) or removes the tracer id with the given selector.
+func (idx *tracerIndex) update(id string, s *containercollection.ContainerSelector, add bool) {
+	indexUpdate := indexRemove
+	if add {
+		indexUpdate = indexAdd
+	}
+
+	switch {
+	case s.Podname != "":
+		indexUpdate(idx.byPodname, s.Podname, id)
+	case s.Name != "":
+		indexUpdate(idx.byName, s.Name, id)
+	case s.Namespace != "":
+		for _, ns := range strings.Split(s.Namespace, ",") {
+			indexUpdate(idx.byNamespace, ns, id)
+		}
+	case len(s.Labels) > 0:
+		// Any key does, take the first one to be deterministic
+		keys := make([]string, 0, len(s.Labels))
+		for k := range s.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		indexUpdate(idx.byLabelKey, keys[0], id)
+	default:
+		if add {
+			idx.all[id] = struct{}{}
+		} else {
+			delete(idx.all, id)
+		}
+	}
+}
+
+func (idx *tracerIndex) add(id string, s *containercollection.ContainerSelector) {
+	idx.update(id, s, true)
+}
+
+func (idx *tracerIndex) remove(id string, s *containercollection.ContainerSelector) {
+	idx.update(id, s, false)
+}
+
+// candidates returns the IDs of the tracers whose selector can match the
+// container. Callers still need to check the selector of each candidate. As
+// each tracer is indexed under a single criteria, an ID is returned at most
+// once.
+func (idx *tracerIndex) candidates(c *containercollection.Container) []string {
+	sets := []tracerSet{
+		idx.all,
+		idx.byPodname[c.Podname],
+		idx.byName[c.Name],
+		idx.byNamespace[c.Namespace],
+	}
+	for k := range c.Labels {
+		sets = append(sets, idx.byLabelKey[k])
+	}
+
+	ret := []string{}
+	for _, set := range sets {
+		for id := range set {
+			ret = append(ret, id)
+		}
+	}
+	return ret
+}
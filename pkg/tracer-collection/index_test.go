@@ -0,0 +1,106 @@
+// Copyright 2022 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracercollection
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	containercollection "github.com/lato333/inspektor-gadget/pkg/container-collection"
+)
+
+var testSelectors = []containercollection.ContainerSelector{
+	{},
+	{Namespace: "namespace1"},
+	{Namespace: "namespace1,namespace2"},
+	{Namespace: "namespace1", Podname: "pod1"},
+	{Podname: "pod1", Name: "name1"},
+	{Name: "name0"},
+	{Labels: map[string]string{"app": "app1"}},
+	{Labels: map[string]string{"app": "app1", "tier": "web"}},
+	{Namespace: "namespace3", Labels: map[string]string{"app": "app3"}},
+}
+
+func newTestContainer(i int) *containercollection.Container {
+	return &containercollection.Container{
+		ID:        fmt.Sprintf("container%d", i),
+		Namespace: fmt.Sprintf("namespace%d", i%4),
+		Podname:   fmt.Sprintf("pod%d", i%3),
+		Name:      fmt.Sprintf("name%d", i%2),
+		Labels: map[string]string{
+			"app":  fmt.Sprintf("app%d", i%5),
+			"tier": "web",
+		},
+	}
+}
+
+func newTestTracerCollection(t testing.TB, selectors []containercollection.ContainerSelector) *TracerCollection {
+	tc, err := NewTracerCollectionTest(nil)
+	if err != nil {
+		t.Fatalf("Failed to create tracer collection: %s", err)
+	}
+	for i, s := range selectors {
+		if err := tc.AddTracer(fmt.Sprintf("tracer%d", i), s); err != nil {
+			t.Fatalf("Failed to add tracer: %s", err)
+		}
+	}
+	return tc
+}
+
+func TestMatchingTracers(t *testing.T) {
+	tc := newTestTracerCollection(t, testSelectors)
+	if err := tc.RemoveTracer("tracer5"); err != nil {
+		t.Fatalf("Failed to remove tracer: %s", err)
+	}
+
+	for i := 0; i < 60; i++ {
+		c := newTestContainer(i)
+
+		expected := []string{}
+		for id, tracer := range tc.tracers {
+			if containercollection.ContainerSelectorMatches(&tracer.containerSelector, c) {
+				expected = append(expected, id)
+			}
+		}
+		actual := []string{}
+		for _, tracer := range tc.matchingTracers(c) {
+			actual = append(actual, tracer.tracerID)
+		}
+
+		sort.Strings(expected)
+		sort.Strings(actual)
+		if fmt.Sprint(actual) != fmt.Sprint(expected) {
+			t.Fatalf("Container %s: expected tracers %v, got %v", c.ID, expected, actual)
+		}
+	}
+}
+
+func BenchmarkMatchingTracers(b *testing.B) {
+	selectors := []containercollection.ContainerSelector{}
+	for i := 0; i < 100; i++ {
+		selectors = append(selectors, containercollection.ContainerSelector{
+			Namespace: fmt.Sprintf("namespace%d", i),
+			Podname:   fmt.Sprintf("pod%d", i),
+		})
+	}
+	tc := newTestTracerCollection(b, selectors)
+	c := newTestContainer(42)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tc.matchingTracers(c)
+	}
+}
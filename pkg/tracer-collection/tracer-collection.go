@@ -33,7 +33,13 @@ const (
 )
 
 type TracerCollection struct {
-	tracers             map[string]tracer
+	tracers map[string]tracer
+
+	// index allows to find the tracers matching a container without
+	// evaluating the selector of all of them. It's updated together with
+	// tracers.
+	index *tracerIndex
+
 	containerCollection *containercollection.ContainerCollection
 
 	// We keep an open file descriptor of the containers mount
@@ -61,6 +67,7 @@ type tracer struct {
 func NewTracerCollection(cc *containercollection.ContainerCollection) (*TracerCollection, error) {
 	return &TracerCollection{
 		tracers:             make(map[string]tracer),
+		index:               newTracerIndex(),
 		containerCollection: cc,
 		containerMntNsFds:   make(map[string]int),
 	}, nil
@@ -69,6 +76,7 @@ func NewTracerCollection(cc *containercollection.ContainerCollection) (*TracerCo
 func NewTracerCollectionTest(cc *containercollection.ContainerCollection) (*TracerCollection, error) {
 	return &TracerCollection{
 		tracers:             make(map[string]tracer),
+		index:               newTracerIndex(),
 		containerCollection: cc,
 		testOnly:            true,
 	}, nil
@@ -97,24 +105,20 @@ func (tc *TracerCollection) TracerMapsUpdater() containercollection.FuncNotify {
 			tc.containerMntNsFds[event.Container.ID] = fd
 			tc.mu.Unlock()
 
-			for _, t := range tc.tracers {
-				if containercollection.ContainerSelectorMatches(&t.containerSelector, event.Container) {
-					mntnsC := uint64(event.Container.Mntns)
-					one := uint32(1)
-					if mntnsC != 0 {
-						t.mntnsSetMap.Put(mntnsC, one)
-					} else {
-						log.Errorf("new container with mntns=0")
-					}
+			for _, t := range tc.matchingTracers(event.Container) {
+				mntnsC := uint64(event.Container.Mntns)
+				one := uint32(1)
+				if mntnsC != 0 {
+					t.mntnsSetMap.Put(mntnsC, one)
+				} else {
+					log.Errorf("new container with mntns=0")
 				}
 			}
 
 		case containercollection.EventTypeRemoveContainer:
-			for _, t := range tc.tracers {
-				if containercollection.ContainerSelectorMatches(&t.containerSelector, event.Container) {
-					mntnsC := uint64(event.Container.Mntns)
-					t.mntnsSetMap.Delete(mntnsC)
-				}
+			for _, t := range tc.matchingTracers(event.Container) {
+				mntnsC := uint64(event.Container.Mntns)
+				t.mntnsSetMap.Delete(mntnsC)
 			}
 
 			tc.mu.Lock()
@@ -160,6 +164,7 @@ func (tc *TracerCollection) AddTracer(id string, containerSelector containercoll
 		mntnsSetMap:       mntnsSetMap,
 		gadgetStream:      stream.NewGadgetStream(),
 	}
+	tc.index.add(id, &containerSelector)
 	return nil
 }
 
@@ -179,10 +184,23 @@ func (tc *TracerCollection) RemoveTracer(id string) error {
 
 	t.gadgetStream.Close()
 
+	tc.index.remove(id, &t.containerSelector)
 	delete(tc.tracers, id)
 	return nil
 }
 
+// matchingTracers returns the tracers whose selector matches the container.
+func (tc *TracerCollection) matchingTracers(c *containercollection.Container) []tracer {
+	ret := []tracer{}
+	for _, id := range tc.index.candidates(c) {
+		t, ok := tc.tracers[id]
+		if ok && containercollection.ContainerSelectorMatches(&t.containerSelector, c) {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
 func (tc *TracerCollection) Stream(id string) (*stream.GadgetStream, error) {
 	t, ok := tc.tracers[id]
 	if !ok {